@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// -animate modes
+const (
+	animateTypewriter = "typewriter"
+	animateLines      = "lines"
+)
+
+const defaultAnimateSpeed = 40
+
+func checkAnimation(mode string, speed int) error {
+	switch mode {
+	case "", animateTypewriter, animateLines:
+	default:
+		return fmt.Errorf("unknown animation %q (use typewriter or lines)", mode)
+	}
+	if speed <= 0 {
+		return fmt.Errorf("-speed must be positive, got %d", speed)
+	}
+	return nil
+}
+
+// animate prints art a piece at a time: speed characters a second for
+// typewriter, skipping the pauses on blanks, or speed lines a second for
+// lines. Color escape sequences are written whole, so they never show.
+// When frames caps the frame rate below speed, each frame prints several
+// pieces so the overall speed holds. The art comes from layout, which is
+// asked again if the terminal is resized: the screen is then cleared and
+// what was shown so far is redrawn in the new layout before going on.
+func animate(w io.Writer, layout func() string, mode string, speed int, frames *frameLimiter) {
+	period, perFrame := framePacing(speed, frames)
+	var resized <-chan struct{}
+	if isTerminal(w) {
+		var stop func()
+		resized, stop = watchResize()
+		defer stop()
+	}
+
+	rest, shown := layout(), 0
+	for rest != "" {
+		frames.begin()
+		var piece string
+		piece, rest = splitPieces(rest, mode, perFrame)
+		io.WriteString(w, piece)
+		shown += perFrame
+		select {
+		case <-time.After(frames.next(period)):
+		case <-resized:
+			var head string
+			head, rest = splitPieces(layout(), mode, shown)
+			io.WriteString(w, clearScreen+head)
+		}
+	}
+	if mode != animateLines {
+		fmt.Fprintln(w)
+	}
+}
+
+// framePacing returns how long each frame of an animation at speed
+// stays up and how many pieces it adds
+func framePacing(speed int, frames *frameLimiter) (time.Duration, int) {
+	period, perFrame := time.Second/time.Duration(speed), 1
+	if frames != nil && frames.interval > period {
+		perFrame = int(frames.interval / period)
+		period *= time.Duration(perFrame)
+	}
+	return period, perFrame
+}
+
+// animationFrames counts the frames animate takes to show art
+func animationFrames(art, mode string, speed int, frames *frameLimiter) int {
+	_, perFrame := framePacing(speed, frames)
+	pieces := 0
+	if mode == animateLines {
+		pieces = len(strings.SplitAfter(art, "\n"))
+	} else {
+		for i := 0; i < len(art); {
+			if size := escapeLength(art[i:]); size > 0 {
+				i += size
+				continue
+			}
+			r, size := utf8.DecodeRuneInString(art[i:])
+			i += size
+			if !unicode.IsSpace(r) {
+				pieces++
+			}
+		}
+	}
+	return (pieces + perFrame - 1) / perFrame
+}
+
+// splitPieces splits art after its first n pieces: lines, each ending in
+// a newline, for lines mode, or else characters other than blanks
+func splitPieces(art, mode string, n int) (head, tail string) {
+	if mode == animateLines {
+		lines := strings.SplitAfter(art, "\n")
+		n = min(n, len(lines))
+		head, tail = strings.Join(lines[:n], ""), strings.Join(lines[n:], "")
+		if !strings.HasSuffix(head, "\n") {
+			head += "\n"
+		}
+		return head, tail
+	}
+
+	i := 0
+	for shown := 0; i < len(art) && shown < n; {
+		if size := escapeLength(art[i:]); size > 0 {
+			i += size
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(art[i:])
+		i += size
+		if !unicode.IsSpace(r) {
+			shown++
+		}
+	}
+	return art[:i], art[i:]
+}
+
+// escapeLength returns the length of the CSI escape sequence at the
+// start of s, or 0 if there is none
+func escapeLength(s string) int {
+	if !strings.HasPrefix(s, "\x1b[") {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// frameRecorder feeds writes to a vterm and keeps what the screen shows
+// after each one, since every write of an animation is a frame
+type frameRecorder struct {
+	vt     *vterm
+	frames []string
+}
+
+func (r *frameRecorder) Write(p []byte) (int, error) {
+	n, err := r.vt.Write(p)
+	r.frames = append(r.frames, r.vt.text())
+	return n, err
+}
+
+func recordAnimation(art, mode string, frames *frameLimiter) *frameRecorder {
+	r := &frameRecorder{vt: newVTerm(40, 10)}
+	layout := func() string { return art }
+	animate(r, layout, mode, 100000, frames)
+	return r
+}
+
+func TestAnimateTypewriter(t *testing.T) {
+	r := recordAnimation("ab c\n\x1b[31md\x1b[0m e", animateTypewriter, &frameLimiter{})
+	want := []string{
+		"a",
+		"ab",
+		"ab c",
+		"ab c\nd",
+		"ab c\nd e",
+		"ab c\nd e",
+	}
+	if strings.Join(r.frames, "|") != strings.Join(want, "|") {
+		t.Errorf("frames %q, want %q", r.frames, want)
+	}
+	if pen := r.vt.cell(1, 0).pen; pen.fg != "31" {
+		t.Errorf("colored character drawn with %+v", pen)
+	}
+	if len(r.vt.unknown) > 0 {
+		t.Errorf("unknown sequences written: %v", r.vt.unknown)
+	}
+}
+
+func TestAnimateLines(t *testing.T) {
+	art := "one\ntwo\nthree"
+	r := recordAnimation(art, animateLines, &frameLimiter{})
+	want := []string{"one", "one\ntwo", "one\ntwo\nthree"}
+	if strings.Join(r.frames, "|") != strings.Join(want, "|") {
+		t.Errorf("frames %q, want %q", r.frames, want)
+	}
+}
+
+func TestAnimateFrameCap(t *testing.T) {
+	// At 100000 characters a second, a 100 fps cap draws 1000 per frame
+	art := strings.TrimRight(renderer.Figure("Hi!", "small"), "\n")
+	r := recordAnimation(art, animateTypewriter, &frameLimiter{interval: 10 * time.Millisecond})
+	if len(r.frames) != 2 {
+		t.Fatalf("%d frames, want the art and then the final newline", len(r.frames))
+	}
+	checkSnapshot(t, "animate-capped.txt", r.frames[0]+"\n")
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ascii-art/asciiart"
+)
+
+// renderANSArt shows a classic ANSI art file (.ans, code page 437), or an
+// XBIN or binary text one, in its own colors, with the credits from its SAUCE record, or saves it in
+// options.format to the -output file. A -category and -style reframe it
+// in the style's border, and a color scheme, -fg or -gradient recolor
+// its characters instead. Saved as ans, it keeps its SAUCE record.
+func renderANSArt(path string, config *AppConfig, options RenderOptions) {
+	canvas, record, err := parseArtFile(path)
+	if err != nil {
+		fmt.Printf("Error reading ANSI art: %v\n", err)
+		os.Exit(1)
+	}
+
+	if options.category > 0 || options.borderChar != "" || options.borderChars != nil {
+		d := asciiart.Decorator{}
+		if options.category > 0 {
+			_, style := config.getStyleSelection(options.category, max(options.style, 1), options.menuChoices)
+			d = style.Decorator
+		}
+		d = options.overrideDecorator(d)
+		if d.Border != nil || d.Top != "" || d.Bottom != "" || d.Left != "" || d.Right != "" {
+			canvas = canvas.Framed(d)
+		}
+	}
+
+	var colorizer asciiart.Colorizer
+	switch {
+	case !options.showColors:
+	case options.gradient != nil:
+		colorizer = options.gradient
+	case options.fg != nil:
+		colorizer = options.fg
+	case options.colorScheme > 0:
+		colorizer = config.getColorSelection(options.colorScheme, true, options.menuChoices)
+	}
+
+	art, ans := ansArtVariants(canvas, colorizer)
+	shown := art.Plain
+	switch {
+	case colorizer != nil:
+		shown = asciiart.Colorize(art.Plain, colorizer)
+	case options.showColors && detectColorDepth() != colorDepthNone:
+		shown = canvas.ANSI(detectColorDepth())
+	}
+	if options.format != "" {
+		shown = map[string]string{"text": art.Plain, "ansi": art.ANSI, "ans": ans, "html": art.HTML, "svg": art.SVG}[options.format]
+	}
+	if record != nil && options.format == "ans" {
+		options.sauce = mergeSAUCE(options.sauce, *record)
+	}
+	if options.sauce != nil {
+		shown = withSAUCE(shown, options.format, *options.sauce)
+	}
+
+	if options.outputFile == "" {
+		if options.sauce != nil {
+			fmt.Print(shown)
+			return
+		}
+		fmt.Println(shown)
+		if record != nil && options.format == "" {
+			printSAUCE(record)
+		}
+		return
+	}
+	if err := saveToFile(options.outputFile, shown); err != nil {
+		fmt.Printf("Error saving to file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("ASCII art saved to: %s\n", options.outputFile)
+}
+
+// parseArtFile reads the ANSI art file at path: XBIN if it starts like
+// one, binary text if named .bin or its SAUCE record says so, and ANSI
+// otherwise
+func parseArtFile(path string) (*asciiart.Canvas, *asciiart.SAUCE, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bytes.HasPrefix(data, []byte(asciiart.XBinID)) {
+		return asciiart.ParseXBin(data)
+	}
+	_, record := asciiart.ParseSAUCE(data)
+	if strings.EqualFold(filepath.Ext(path), ".bin") || record != nil && record.DataType == asciiart.SAUCEBinaryText {
+		canvas, record := asciiart.ParseBinaryText(data)
+		return canvas, record, nil
+	}
+	canvas, record := asciiart.ParseANSIArt(data)
+	return canvas, record, nil
+}
+
+// ansArtVariants renders canvas for every output format, in its own
+// colors or recolored by colorizer, if not nil. The second result is the
+// canvas as an ANSI art file.
+func ansArtVariants(canvas *asciiart.Canvas, colorizer asciiart.Colorizer) (RenderedArt, string) {
+	plain := canvas.Text()
+	if colorizer == nil {
+		return RenderedArt{
+			Text:  plain,
+			Plain: plain,
+			ANSI:  canvas.ANSI(asciiart.DepthTrue),
+			HTML:  asciiart.HTML(plain, canvas),
+			SVG:   asciiart.SVG(plain, canvas),
+		}, string(canvas.EncodeANSIArt())
+	}
+
+	ansi := asciiart.Colorize(plain, forcedColorizer(colorizer))
+	if gradient, ok := colorizer.(*asciiart.Gradient); ok {
+		colorizer = gradient.WithDepth(asciiart.DepthTrue)
+	}
+	return RenderedArt{
+		Text:  plain,
+		Plain: plain,
+		ANSI:  ansi,
+		HTML:  asciiart.HTML(plain, colorizer),
+		SVG:   asciiart.SVG(plain, colorizer),
+	}, ansArt(ansi)
+}
+
+// forcedColorizer returns c with its colors written even when stdout is
+// not a terminal
+func forcedColorizer(c asciiart.Colorizer) asciiart.Colorizer {
+	switch c := c.(type) {
+	case *asciiart.Gradient:
+		return c.Forced()
+	case *asciiart.ColorScheme:
+		return c.Forced()
+	}
+	return c
+}
+
+// ansArt converts art with ANSI color sequences, such as the ANSI variant
+// of a render, to a classic ANSI art file for -format ans
+func ansArt(ansi string) string {
+	return string(asciiart.ParseANSI(ansi, 0).EncodeANSIArt())
+}
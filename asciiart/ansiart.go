@@ -0,0 +1,536 @@
+package asciiart
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// ANSIArtWidth is the screen width classic ANSI art is drawn for
+const ANSIArtWidth = 80
+
+// Colors of a canvas cell, as indexes into the 16 colors of ANSI art. The
+// first eight are the dim ones, in ANSI order: black, red, green, yellow
+// (brown), blue, magenta, cyan and white (light gray); adding 8 gives the
+// bright ones. Indexes from 16 on are 24-bit colors in the canvas palette.
+const (
+	DefaultFG = 7
+	DefaultBG = 0
+)
+
+// VGAPalette holds the RGB values of the 16 ANSI art colors as the VGA
+// text mode shows them, which is what art of that era was drawn against
+var VGAPalette = [16]RGB{
+	{0, 0, 0}, {170, 0, 0}, {0, 170, 0}, {170, 85, 0},
+	{0, 0, 170}, {170, 0, 170}, {0, 170, 170}, {170, 170, 170},
+	{85, 85, 85}, {255, 85, 85}, {85, 255, 85}, {255, 255, 85},
+	{85, 85, 255}, {255, 85, 255}, {85, 255, 255}, {255, 255, 255},
+}
+
+// CanvasCell is one character cell of a Canvas. The cell after a wide
+// character has Rune 0.
+type CanvasCell struct {
+	Rune   rune
+	FG, BG int // Indexes into the canvas palette
+}
+
+var blankCell = CanvasCell{' ', DefaultFG, DefaultBG}
+
+// Canvas is a grid of colored character cells, the form ANSI art takes
+// once its cursor movements and color changes have been played out
+type Canvas struct {
+	Width int // 0 grows lines as needed instead of wrapping
+	Rows  [][]CanvasCell
+	// Palette holds the RGB values of the colors, VGAPalette if nil. XBIN
+	// files bring their own 16, and 24-bit colors are added after those.
+	Palette []RGB
+
+	colors []*color.Color // For ColorAt, one per color so runs share them
+}
+
+// RGB returns the RGB value of color index
+func (c *Canvas) RGB(index int) RGB {
+	if index < len(c.Palette) {
+		return c.Palette[index]
+	}
+	return VGAPalette[index]
+}
+
+// addColor returns the index of rgb in the palette, adding it if needed
+func (c *Canvas) addColor(rgb RGB) int {
+	if c.Palette == nil {
+		c.Palette = append([]RGB(nil), VGAPalette[:]...)
+	}
+	for i, v := range c.Palette {
+		if v == rgb {
+			return i
+		}
+	}
+	c.Palette = append(c.Palette, rgb)
+	return len(c.Palette) - 1
+}
+
+// basicIndex returns color index as one of the 16, the nearest one for a
+// 24-bit color
+func (c *Canvas) basicIndex(index int) int {
+	if index < 16 {
+		return index
+	}
+	return nearestVGA(c.RGB(index))
+}
+
+// ansiPen is the drawing state while parsing ANSI art
+type ansiPen struct {
+	fg, bg           int
+	bold, blink      bool
+	inverse          bool
+	fgExact, bgExact bool // Set to a bright or dim color outright, not one bold or blink brightens
+}
+
+func (p ansiPen) cell(r rune) CanvasCell {
+	fg, bg := p.fg, p.bg
+	if p.bold && !p.fgExact && fg < 8 {
+		fg += 8
+	}
+	// iCE colors: blink brightens the background instead of blinking
+	if p.blink && !p.bgExact && bg < 8 {
+		bg += 8
+	}
+	if p.inverse {
+		fg, bg = bg, fg
+	}
+	return CanvasCell{r, fg, bg}
+}
+
+// ParseANSIArt reads a classic ANSI art file: code page 437 text with
+// ANSI.SYS escape sequences, drawn on an 80 column screen unless its
+// SAUCE record, returned if there is one, gives another width
+func ParseANSIArt(data []byte) (*Canvas, *SAUCE) {
+	data, record := ParseSAUCE(data)
+	if i := bytes.IndexByte(data, '\x1a'); i >= 0 {
+		data = data[:i]
+	}
+	width := ANSIArtWidth
+	if record != nil && record.DataType == SAUCECharacter && record.Width > 0 {
+		width = record.Width
+	}
+	return ParseANSI(DecodeCP437(data), width), record
+}
+
+// ParseANSI plays out text with ANSI escape sequences onto a canvas width
+// cells wide, wrapping like a terminal; a width of 0 never wraps. Cursor
+// movement, erasure and SGR colors are understood, including 256 and 24-bit
+// colors and PabloDraw's 24-bit color sequence, ESC[0;R;G;Bt for the
+// background and ESC[1;R;G;Bt for the foreground. Other sequences are
+// skipped.
+func ParseANSI(text string, width int) *Canvas {
+	c := &Canvas{Width: width}
+	pen := ansiPen{fg: DefaultFG, bg: DefaultBG}
+	row, col := 0, 0
+	savedRow, savedCol := 0, 0
+
+	for text != "" {
+		if text[0] == '\x1b' {
+			n := escapeLength(text)
+			if n == 0 {
+				// A lone escape or one not followed by [
+				n = min(2, len(text))
+			} else if final := text[n-1]; n >= 3 {
+				args := ansiArgs(text[2 : n-1])
+				arg := func(i, fallback int) int {
+					if i < len(args) && args[i] > 0 {
+						return args[i]
+					}
+					return fallback
+				}
+				switch final {
+				case 'm':
+					pen = pen.apply(args, c)
+				case 't':
+					if len(args) == 4 {
+						index := c.addColor(RGB{args[1], args[2], args[3]})
+						if args[0] == 1 {
+							pen.fg, pen.fgExact = index, true
+						} else {
+							pen.bg, pen.bgExact = index, true
+						}
+					}
+				case 'H', 'f':
+					row, col = arg(0, 1)-1, arg(1, 1)-1
+				case 'A':
+					row = max(row-arg(0, 1), 0)
+				case 'B':
+					row += arg(0, 1)
+				case 'C':
+					col += arg(0, 1)
+				case 'D':
+					col = max(col-arg(0, 1), 0)
+				case 'G':
+					col = arg(0, 1) - 1
+				case 's':
+					savedRow, savedCol = row, col
+				case 'u':
+					row, col = savedRow, savedCol
+				case 'J':
+					if arg(0, 0) == 2 {
+						c.Rows, row, col = nil, 0, 0
+					}
+				case 'K':
+					if row < len(c.Rows) && col < len(c.Rows[row]) {
+						c.Rows[row] = c.Rows[row][:col]
+					}
+				}
+				if c.Width > 0 {
+					col = min(col, c.Width-1)
+				}
+			}
+			text = text[n:]
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+		switch r {
+		case '\r':
+			col = 0
+			continue
+		case '\n':
+			row, col = row+1, 0
+			continue
+		case '\t':
+			col = (col/8 + 1) * 8
+			continue
+		}
+		if unicode.IsControl(r) {
+			continue
+		}
+		w := runeWidth(r)
+		if w == 0 {
+			continue
+		}
+		if c.Width > 0 && col+w > c.Width {
+			row, col = row+1, 0
+		}
+		c.set(row, col, pen.cell(r))
+		if w == 2 {
+			c.set(row, col+1, CanvasCell{0, pen.cell(r).FG, pen.cell(r).BG})
+		}
+		col += w
+	}
+	return c
+}
+
+// escapeLength returns the length of the CSI escape sequence at the start
+// of s, or 0 if there is none
+func escapeLength(s string) int {
+	if !strings.HasPrefix(s, "\x1b[") {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// ansiArgs splits CSI parameters; missing ones are 0
+func ansiArgs(params string) []int {
+	params = strings.TrimLeft(params, "?=")
+	var args []int
+	for _, p := range strings.Split(params, ";") {
+		n, _ := strconv.Atoi(p)
+		args = append(args, n)
+	}
+	return args
+}
+
+// apply returns the pen after an SGR sequence with args, adding colors
+// beyond the 16 to c's palette
+func (p ansiPen) apply(args []int, c *Canvas) ansiPen {
+	for i := 0; i < len(args); i++ {
+		switch n := args[i]; {
+		case n == 0:
+			p = ansiPen{fg: DefaultFG, bg: DefaultBG}
+		case n == 1:
+			p.bold = true
+		case n == 5 || n == 6:
+			p.blink = true
+		case n == 7:
+			p.inverse = true
+		case n == 22:
+			p.bold = false
+		case n == 25:
+			p.blink = false
+		case n == 27:
+			p.inverse = false
+		case n >= 30 && n <= 37:
+			p.fg, p.fgExact = n-30, false
+		case n == 39:
+			p.fg, p.fgExact = DefaultFG, false
+		case n >= 40 && n <= 47:
+			p.bg, p.bgExact = n-40, false
+		case n == 49:
+			p.bg, p.bgExact = DefaultBG, false
+		case n >= 90 && n <= 97:
+			p.fg, p.fgExact = n-90+8, true
+		case n >= 100 && n <= 107:
+			p.bg, p.bgExact = n-100+8, true
+		case (n == 38 || n == 48) && i+2 < len(args) && args[i+1] == 5:
+			index := args[i+2]
+			if index >= 16 {
+				index = c.addColor(palette256(index))
+			}
+			if n == 38 {
+				p.fg, p.fgExact = index, true
+			} else {
+				p.bg, p.bgExact = index, true
+			}
+			i += 2
+		case (n == 38 || n == 48) && i+4 < len(args) && args[i+1] == 2:
+			index := c.addColor(RGB{args[i+2], args[i+3], args[i+4]})
+			if n == 38 {
+				p.fg, p.fgExact = index, true
+			} else {
+				p.bg, p.bgExact = index, true
+			}
+			i += 4
+		}
+	}
+	return p
+}
+
+// nearestVGA returns the index of the ANSI art color closest to c
+func nearestVGA(c RGB) int {
+	best := 0
+	for i, v := range VGAPalette {
+		if c.distance(v) < c.distance(VGAPalette[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// set puts cell at row and col, growing the canvas to reach them
+func (c *Canvas) set(row, col int, cell CanvasCell) {
+	for len(c.Rows) <= row {
+		c.Rows = append(c.Rows, nil)
+	}
+	for len(c.Rows[row]) <= col {
+		c.Rows[row] = append(c.Rows[row], blankCell)
+	}
+	c.Rows[row][col] = cell
+}
+
+// trimmed returns the rows without trailing blank cells or blank rows at
+// the bottom. A cell is blank when it is a space on the default
+// background.
+func (c *Canvas) trimmed() [][]CanvasCell {
+	rows := make([][]CanvasCell, len(c.Rows))
+	for i, row := range c.Rows {
+		end := len(row)
+		for end > 0 && row[end-1].Rune == ' ' && row[end-1].BG == DefaultBG {
+			end--
+		}
+		rows[i] = row[:end]
+	}
+	for len(rows) > 0 && len(rows[len(rows)-1]) == 0 {
+		rows = rows[:len(rows)-1]
+	}
+	return rows
+}
+
+// Text returns the characters of the canvas without colors
+func (c *Canvas) Text() string {
+	var lines []string
+	for _, row := range c.trimmed() {
+		var b strings.Builder
+		for _, cell := range row {
+			if cell.Rune != 0 {
+				b.WriteRune(cell.Rune)
+			}
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ANSI returns the canvas as UTF-8 text with escape sequences for a
+// terminal showing depth colors. Each line ends with a reset, so what
+// follows is drawn in the terminal's own colors.
+func (c *Canvas) ANSI(depth int) string {
+	// The colors left to the terminal's defaults, unless a palette of the
+	// art's own changes them
+	plain := CanvasCell{FG: DefaultFG, BG: DefaultBG}
+	if depth >= Depth256 && c.RGB(DefaultFG) != VGAPalette[DefaultFG] {
+		plain.FG = -1
+	}
+	if depth >= Depth256 && c.RGB(DefaultBG) != VGAPalette[DefaultBG] {
+		plain.BG = -1
+	}
+	var lines []string
+	for _, row := range c.trimmed() {
+		var b strings.Builder
+		current := plain
+		for _, cell := range row {
+			if cell.Rune == 0 {
+				continue
+			}
+			if cell.FG != current.FG || cell.BG != current.BG {
+				b.WriteString("\x1b[0")
+				if cell.FG != plain.FG {
+					b.WriteString(";" + c.sgr(cell.FG, depth, false))
+				}
+				if cell.BG != plain.BG {
+					b.WriteString(";" + c.sgr(cell.BG, depth, true))
+				}
+				b.WriteString("m")
+				current = cell
+			}
+			b.WriteRune(cell.Rune)
+		}
+		if current.FG != plain.FG || current.BG != plain.BG {
+			b.WriteString("\x1b[0m")
+		}
+		lines = append(lines, b.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sgr returns the SGR parameters selecting color index as the foreground
+// or background. Terminals of 256 colors or more get the palette's RGB
+// values, since their own 16 colors are often themed.
+func (c *Canvas) sgr(index, depth int, background bool) string {
+	if depth >= Depth256 {
+		rgb := c.RGB(index)
+		sgr := colorSGR(&rgb, depth, background)
+		return strings.TrimSuffix(strings.TrimPrefix(sgr, "\x1b["), "m")
+	}
+	index = c.basicIndex(index)
+	base := 30
+	if background {
+		base = 40
+	}
+	if index >= 8 {
+		base += 60
+	}
+	return strconv.Itoa(base + index%8)
+}
+
+// EncodeANSIArt writes the canvas as a classic ANSI art file: code page
+// 437 with ANSI.SYS color sequences, where bold gives the bright
+// foregrounds and blink the bright backgrounds (iCE colors), and CRLF
+// line breaks. 24-bit colors become the nearest of the 16, and characters
+// code page 437 lacks become '?'.
+func (c *Canvas) EncodeANSIArt() []byte {
+	var b strings.Builder
+	current := CanvasCell{FG: DefaultFG, BG: DefaultBG}
+	for i, row := range c.trimmed() {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		for _, cell := range row {
+			if cell.Rune == 0 {
+				continue
+			}
+			cell.FG, cell.BG = c.basicIndex(cell.FG), c.basicIndex(cell.BG)
+			if cell.FG != current.FG || cell.BG != current.BG {
+				fmt.Fprintf(&b, "\x1b[0;%d;%d", 30+cell.FG%8, 40+cell.BG%8)
+				if cell.FG >= 8 {
+					b.WriteString(";1")
+				}
+				if cell.BG >= 8 {
+					b.WriteString(";5")
+				}
+				b.WriteString("m")
+				current = cell
+			}
+			b.WriteRune(cell.Rune)
+		}
+	}
+	b.WriteString("\x1b[0m\r\n")
+	data, _ := EncodeCP437(b.String())
+	return data
+}
+
+// ColorAt returns the foreground color of the canvas cell under cell, so
+// a canvas can color its own text for HTML and SVG
+func (c *Canvas) ColorAt(cell Cell) *color.Color {
+	if cell.Row >= len(c.Rows) || cell.Col >= len(c.Rows[cell.Row]) {
+		return nil
+	}
+	index := c.Rows[cell.Row][cell.Col].FG
+	for len(c.colors) <= index {
+		c.colors = append(c.colors, nil)
+	}
+	if c.colors[index] == nil {
+		c.colors[index] = c.RGB(index).Color(DepthTrue)
+	}
+	return c.colors[index]
+}
+
+// Framed returns the canvas drawn inside d's border, in the default
+// colors, with its own cells and colors kept
+func (c *Canvas) Framed(d Decorator) *Canvas {
+	rows := c.trimmed()
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	// Frame a block of placeholders to find where the art lands
+	const placeholder = ''
+	block := make([]string, len(rows))
+	for i := range block {
+		block[i] = strings.Repeat(string(placeholder), width)
+	}
+	framed := ParseANSI(ApplyDecorator(strings.Join(block, "\n"), d), 0)
+	top, left := -1, 0
+	for y, row := range framed.Rows {
+		for x, cell := range row {
+			if cell.Rune == placeholder {
+				top, left = y, x
+				break
+			}
+		}
+		if top >= 0 {
+			break
+		}
+	}
+	if top < 0 {
+		return framed
+	}
+	for y, row := range framed.Rows {
+		for x, cell := range row {
+			if cell.Rune == placeholder {
+				framed.Rows[y][x] = blankCell
+			}
+		}
+	}
+	if c.Palette != nil {
+		// Carry over the art's colors, making room for any the border uses
+		palette := framed.Palette
+		framed.Palette = append([]RGB(nil), c.Palette...)
+		remap := func(index int) int {
+			if index >= 16 && index < len(palette) {
+				return framed.addColor(palette[index])
+			}
+			return index
+		}
+		for _, row := range framed.Rows {
+			for x, cell := range row {
+				row[x].FG, row[x].BG = remap(cell.FG), remap(cell.BG)
+			}
+		}
+	}
+	for y, row := range rows {
+		for x, cell := range row {
+			framed.set(top+y, left+x, cell)
+		}
+	}
+	return framed
+}
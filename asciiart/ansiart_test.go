@@ -0,0 +1,114 @@
+package asciiart
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCP437RoundTrip(t *testing.T) {
+	var all []byte
+	for c := range 256 {
+		if !bytes.ContainsRune([]byte(cp437Controls), rune(c)) && c != 0 {
+			all = append(all, byte(c))
+		}
+	}
+	text := DecodeCP437(all)
+	back, lossless := EncodeCP437(text)
+	if !lossless || !bytes.Equal(back, all) {
+		t.Errorf("code page 437 did not survive a round trip through UTF-8")
+	}
+	if _, lossless := EncodeCP437("日本"); lossless {
+		t.Error("characters missing from code page 437 reported as encoded")
+	}
+}
+
+func TestParseANSIArt(t *testing.T) {
+	data := []byte("\x1b[1;33m\xdc\xdc\x1b[0m \x1b[5;44mA\x1b[0m\r\n\x1b[2C\xc9\xcd\xbb\x1aSAUCE00junk")
+	c, _ := ParseANSIArt(data)
+	if got, want := c.Text(), "▄▄ A\n  ╔═╗"; got != want {
+		t.Errorf("text %q, want %q", got, want)
+	}
+	checks := []struct {
+		row, col int
+		cell     CanvasCell
+	}{
+		{0, 0, CanvasCell{'▄', 11, DefaultBG}}, // Bold brightens yellow
+		{0, 3, CanvasCell{'A', DefaultFG, 12}}, // Blink brightens the background
+		{1, 2, CanvasCell{'╔', DefaultFG, DefaultBG}},
+	}
+	for _, check := range checks {
+		if got := c.Rows[check.row][check.col]; got != check.cell {
+			t.Errorf("cell %d,%d is %+v, want %+v", check.row, check.col, got, check.cell)
+		}
+	}
+
+	again, _ := ParseANSIArt(c.EncodeANSIArt())
+	if got := again.ANSI(Depth16); got != c.ANSI(Depth16) {
+		t.Errorf("art changed when saved and read back:\n%q\nwant\n%q", got, c.ANSI(Depth16))
+	}
+}
+
+func TestParseANSIWraps(t *testing.T) {
+	c := ParseANSI("abcdef\r\ngh", 4)
+	if got, want := c.Text(), "abcd\nef\ngh"; got != want {
+		t.Errorf("text %q, want %q", got, want)
+	}
+}
+
+func TestSAUCERoundTrip(t *testing.T) {
+	record := SAUCE{
+		Title:    "Lava Lamp",
+		Author:   "Someone",
+		Group:    "Blocktronics",
+		Date:     time.Date(1996, 5, 4, 0, 0, 0, 0, time.UTC),
+		DataType: SAUCECharacter,
+		FileType: SAUCEANSi,
+		Width:    80,
+		Height:   25,
+		Flags:    SAUCEiCEColors,
+		Font:     "IBM VGA",
+		Comments: []string{"For the art pack"},
+	}
+	art := []byte("\x1b[31mhot\x1b[0m\r\n")
+	data := record.Append(art)
+	if len(data) != len(art)+1+5+64+128 {
+		t.Fatalf("%d bytes with the record, want %d", len(data), len(art)+1+5+64+128)
+	}
+	body, got := ParseSAUCE(data)
+	if !bytes.Equal(body, art) {
+		t.Errorf("art %q, want %q", body, art)
+	}
+	record.FileSize = len(art)
+	if got == nil || !reflect.DeepEqual(*got, record) {
+		t.Errorf("record %+v, want %+v", got, record)
+	}
+	if _, none := ParseSAUCE(art); none != nil {
+		t.Errorf("found a record in %q", art)
+	}
+}
+
+func TestParseXBin(t *testing.T) {
+	palette := bytes.Repeat([]byte{0, 0, 0}, 16)
+	copy(palette[4*3:], []byte{63, 32, 0}) // PC color 4 is red
+	data := append([]byte("XBIN\x1a\x02\x00\x03\x00\x10\x0d"), palette...)
+	data = append(data,
+		0x41, 0xdb, 0x04, 0x9e, // Repeated character
+		0x81, 0x07, 'o', 'k', // Repeated attribute
+		0xc1, ' ', 0x00, // Both repeated
+	)
+	c, _, err := ParseXBin(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.Text(), "██\nok"; got != want {
+		t.Errorf("text %q, want %q", got, want)
+	}
+	if got, want := c.Rows[0][1], (CanvasCell{'█', 11, 12}); got != want {
+		t.Errorf("cell 0,1 is %+v, want %+v", got, want)
+	}
+	if got, want := c.RGB(c.Rows[0][0].FG), (RGB{255, 130, 0}); got != want {
+		t.Errorf("palette red is %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package asciiart
+
+import (
+	"strings"
+	"unicode"
+)
+
+// BitmapFontName is the name the built-in bitmap font is found under
+const BitmapFontName = "bitmap"
+
+// BitmapFont is a 5-row block font that needs no font files. Lower case
+// letters are drawn as capitals.
+var BitmapFont Font = newBitmapFont()
+
+// bitmapGlyphs are drawn with '#' for a solid cell and '.' for a blank
+// one, rows separated by spaces
+var bitmapGlyphs = map[rune]string{
+	'A': ".#. #.# ### #.# #.#", 'B': "##. #.# ##. #.# ##.", 'C': ".## #.. #.. #.. .##",
+	'D': "##. #.# #.# #.# ##.", 'E': "### #.. ##. #.. ###", 'F': "### #.. ##. #.. #..",
+	'G': ".## #.. #.# #.# .##", 'H': "#.# #.# ### #.# #.#", 'I': "### .#. .#. .#. ###",
+	'J': "..# ..# ..# #.# .#.", 'K': "#.# #.# ##. #.# #.#", 'L': "#.. #.. #.. #.. ###",
+	'M': "#...# ##.## #.#.# #...# #...#", 'N': "#..# ##.# #.## #..# #..#",
+	'O': ".#. #.# #.# #.# .#.", 'P': "##. #.# ##. #.. #..", 'Q': ".#. #.# #.# ##. .##",
+	'R': "##. #.# ##. #.# #.#", 'S': ".## #.. .#. ..# ##.", 'T': "### .#. .#. .#. .#.",
+	'U': "#.# #.# #.# #.# ###", 'V': "#.# #.# #.# #.# .#.",
+	'W': "#...# #...# #.#.# ##.## #...#", 'X': "#.# #.# .#. #.# #.#",
+	'Y': "#.# #.# .#. .#. .#.", 'Z': "### ..# .#. #.. ###",
+
+	'0': "### #.# #.# #.# ###", '1': ".#. ##. .#. .#. ###", '2': "##. ..# .#. #.. ###",
+	'3': "##. ..# .#. ..# ##.", '4': "#.# #.# ### ..# ..#", '5': "### #.. ##. ..# ##.",
+	'6': ".## #.. ### #.# ###", '7': "### ..# .#. .#. .#.", '8': "### #.# ### #.# ###",
+	'9': "### #.# ### ..# ##.",
+
+	' ': ".. .. .. .. ..", '!': "# # # . #", '?': "##. ..# .#. ... .#.",
+	'.': ". . . . #", ',': ".. .. .. .# #.", ':': ". # . # .", ';': ".. .# .. .# #.",
+	'-': "... ... ### ... ...", '+': "... .#. ### .#. ...", '=': "... ### ... ### ...",
+	'_': "... ... ... ... ###", '/': "..# ..# .#. #.. #..", '(': ".# #. #. #. .#",
+	')': "#. .# .# .# #.", '\'': "# # . . .", '"': "#.# #.# ... ... ...",
+	'*': "#.# .#. #.# ... ...", '#': "#.# ### #.# ### #.#", '%': "#.# ..# .#. #.. #.#",
+	'<': "..# .#. #.. .#. ..#", '>': "#.. .#. ..# .#. #..",
+}
+
+type bitmapFont struct {
+	glyphs map[rune][]string
+}
+
+// newBitmapFont expands bitmapGlyphs into rows of block characters, with
+// a blank column after each glyph
+func newBitmapFont() *bitmapFont {
+	f := &bitmapFont{glyphs: make(map[rune][]string)}
+	cells := strings.NewReplacer("#", "█", ".", " ")
+	for r, pattern := range bitmapGlyphs {
+		var rows []string
+		for _, row := range strings.Fields(pattern) {
+			rows = append(rows, cells.Replace(row)+" ")
+		}
+		f.glyphs[r] = rows
+	}
+	return f
+}
+
+func (f *bitmapFont) Height() int { return 5 }
+
+func (f *bitmapFont) Glyph(r rune) []string {
+	return f.glyphs[unicode.ToUpper(r)]
+}
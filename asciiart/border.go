@@ -0,0 +1,120 @@
+package asciiart
+
+import "strings"
+
+// Corner positions, in the order of Decorator.Corners
+const (
+	TopLeft = iota
+	TopRight
+	BottomLeft
+	BottomRight
+)
+
+// BorderMetrics describes the layout of a frame around art
+type BorderMetrics struct {
+	Left    int // Width of the left column in cells
+	Right   int // Width of the right column in cells
+	Inner   int // Width between the columns, including Padding
+	Padding int // Blank cells between each side and the content
+}
+
+// Border draws a frame around art. Embedders implement it for frames
+// that Decorator's repeated pieces cannot express, such as ASCII art
+// frames whose edges change along their length or span several lines.
+//
+// Measure is called first with the size of the content and decides the
+// layout; the remaining methods then draw pieces to fit it. Edges and
+// corners may be several lines tall, and pieces narrower than their
+// column are padded with spaces.
+type Border interface {
+	Measure(width, height int) BorderMetrics
+	Top(width int) string // width is BorderMetrics.Inner
+	Bottom(width int) string
+	Left(row int) string // row counts content lines from 0
+	Right(row int) string
+	Corner(position int) string // TopLeft, TopRight, BottomLeft or BottomRight
+}
+
+// DrawBorder frames text with b. Unused interior cells on each line are
+// filled by repeating fill, or spaces if it is empty.
+func DrawBorder(text string, b Border, fill string) string {
+	lines := strings.Split(text, "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, DisplayWidth(line))
+	}
+	m := b.Measure(width, len(lines))
+	m.Inner = max(m.Inner, width+2*m.Padding)
+
+	if fill == "" {
+		fill = " "
+	}
+	margin := strings.Repeat(" ", m.Padding)
+
+	var result []string
+	result = append(result, borderBand(m, b.Corner(TopLeft), b.Top(m.Inner), b.Corner(TopRight))...)
+	for row, line := range lines {
+		padding := FillWidth(fill, m.Inner-2*m.Padding-DisplayWidth(line))
+		result = append(result, PadToWidth(b.Left(row), m.Left)+margin+line+padding+margin+leftPad(b.Right(row), m.Right))
+	}
+	result = append(result, borderBand(m, b.Corner(BottomLeft), b.Bottom(m.Inner), b.Corner(BottomRight))...)
+
+	return strings.Join(result, "\n")
+}
+
+// borderBand lines up the corners with a top or bottom edge, any of
+// which may span several lines
+func borderBand(m BorderMetrics, left, edge, right string) []string {
+	if left == "" && edge == "" && right == "" {
+		return nil
+	}
+	lefts, edges, rights := strings.Split(left, "\n"), strings.Split(edge, "\n"), strings.Split(right, "\n")
+	at := func(pieces []string, i int) string {
+		if i < len(pieces) {
+			return pieces[i]
+		}
+		return ""
+	}
+
+	var band []string
+	for i := range max(len(lefts), len(edges), len(rights)) {
+		band = append(band, PadToWidth(at(lefts, i), m.Left)+PadToWidth(at(edges, i), m.Inner)+leftPad(at(rights, i), m.Right))
+	}
+	return band
+}
+
+// decoratorBorder lays out a Decorator's repeated pieces as a Border
+type decoratorBorder struct {
+	d Decorator
+}
+
+// Measure makes the side columns as wide as their widest piece, so emoji
+// corners and edges of different widths still line up, and widens the
+// interior to hold a whole number of top and bottom segments
+func (b decoratorBorder) Measure(width, height int) BorderMetrics {
+	d := b.d
+	m := BorderMetrics{
+		Left:    max(DisplayWidth(d.Left), DisplayWidth(d.Corners[TopLeft]), DisplayWidth(d.Corners[BottomLeft])),
+		Right:   max(DisplayWidth(d.Right), DisplayWidth(d.Corners[TopRight]), DisplayWidth(d.Corners[BottomRight])),
+		Inner:   width + 2,
+		Padding: 1,
+	}
+	for m.Inner%max(1, DisplayWidth(d.Top)) != 0 || m.Inner%max(1, DisplayWidth(d.Bottom)) != 0 {
+		m.Inner++
+	}
+	return m
+}
+
+func (b decoratorBorder) Top(width int) string       { return FillWidth(b.d.Top, width) }
+func (b decoratorBorder) Bottom(width int) string    { return FillWidth(b.d.Bottom, width) }
+func (b decoratorBorder) Left(row int) string        { return b.d.Left }
+func (b decoratorBorder) Right(row int) string       { return b.d.Right }
+func (b decoratorBorder) Corner(position int) string { return b.d.Corners[position] }
+
+// leftPad pads s on the left with spaces to width cells
+func leftPad(s string, width int) string {
+	if w := DisplayWidth(s); w < width {
+		return strings.Repeat(" ", width-w) + s
+	}
+	return s
+}
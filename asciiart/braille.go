@@ -0,0 +1,75 @@
+package asciiart
+
+import "strings"
+
+// brailleBase is the empty Braille pattern; dots are bits added to it
+const brailleBase = 0x2800
+
+// brailleDots are the bits for the dots of a Braille cell, by row and
+// column of its 2x4 grid
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// Braille redraws art in Unicode Braille patterns: every cell of art that
+// is not blank becomes one dot, and each character holds a 2x4 block of
+// dots. Large block fonts come out as finely dotted letters. Blocks with
+// no dots are spaces, so colors and borders treat them as usual.
+func Braille(art string) string {
+	grid := inkGrid(art)
+	var rows []string
+	for y := 0; y < len(grid); y += 4 {
+		var b strings.Builder
+		for x := 0; x < len(grid[y]); x += 2 {
+			dots := rune(0)
+			for dy := range 4 {
+				for dx := range 2 {
+					if inkAt(grid, x+dx, y+dy) {
+						dots |= brailleDots[dy][dx]
+					}
+				}
+			}
+			if dots == 0 {
+				b.WriteByte(' ')
+			} else {
+				b.WriteRune(brailleBase + dots)
+			}
+		}
+		rows = append(rows, strings.TrimRight(b.String(), " "))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// inkGrid marks which terminal cells of art hold something other than a
+// blank. Rows are padded to the widest line, and a wide character marks
+// both of its cells.
+func inkGrid(art string) [][]bool {
+	lines := strings.Split(art, "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, DisplayWidth(line))
+	}
+	grid := make([][]bool, len(lines))
+	for y, line := range lines {
+		grid[y] = make([]bool, width)
+		x := 0
+		for line != "" {
+			cluster, cells := NextGrapheme(line)
+			line = line[len(cluster):]
+			ink := strings.TrimLeft(cluster, " \u2800") != ""
+			for range cells {
+				grid[y][x] = ink
+				x++
+			}
+		}
+	}
+	return grid
+}
+
+// inkAt reports whether (x, y) is inside grid and inked
+func inkAt(grid [][]bool, x, y int) bool {
+	return y < len(grid) && x < len(grid[y]) && grid[y][x]
+}
@@ -0,0 +1,118 @@
+package asciiart
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// Cell is one character of rendered art and where it sits. Row and Col
+// count from 0; Col is measured in terminal cells, so a wide character
+// advances it by two.
+type Cell struct {
+	Rune   rune
+	Row    int
+	Col    int
+	Width  int // Width of the whole art in cells
+	Height int // Height of the whole art in lines
+}
+
+// Colorizer chooses the color of each cell, so applications can plug in
+// their own strategies such as heat maps or audio-reactive effects.
+// Returning nil leaves the cell uncolored.
+type Colorizer interface {
+	ColorAt(cell Cell) *color.Color
+}
+
+// ColorizerFunc adapts a plain function to the Colorizer interface
+type ColorizerFunc func(cell Cell) *color.Color
+
+// ColorAt calls f(cell)
+func (f ColorizerFunc) ColorAt(cell Cell) *color.Color {
+	return f(cell)
+}
+
+// ColorAt cycles through the scheme's colors line by line, matching
+// ApplyColorScheme
+func (cs *ColorScheme) ColorAt(cell Cell) *color.Color {
+	switch cell.Row % 3 {
+	case 0:
+		return cs.Primary
+	case 1:
+		return cs.Secondary
+	}
+	return cs.Background
+}
+
+// Colorize colors text cell by cell with c. Neighbouring cells that get
+// the same *color.Color share one escape sequence.
+func Colorize(text string, c Colorizer) string {
+	var result strings.Builder
+	for row, runs := range colorRuns(text, c) {
+		if row > 0 {
+			result.WriteByte('\n')
+		}
+		for _, run := range runs {
+			if run.color != nil {
+				result.WriteString(run.color.Sprint(run.text))
+			} else {
+				result.WriteString(run.text)
+			}
+		}
+	}
+	return result.String()
+}
+
+// colorRun is a stretch of one line that a Colorizer gave one color
+type colorRun struct {
+	text  string
+	color *color.Color
+}
+
+// colorRuns splits every line of text into runs of cells c colors alike
+func colorRuns(text string, c Colorizer) [][]colorRun {
+	lines := strings.Split(text, "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, DisplayWidth(line))
+	}
+
+	result := make([][]colorRun, len(lines))
+	for row, line := range lines {
+		var run strings.Builder
+		var runColor *color.Color
+		flush := func() {
+			if run.Len() > 0 {
+				result[row] = append(result[row], colorRun{run.String(), runColor})
+			}
+			run.Reset()
+		}
+
+		// Color whole clusters, so no escape splits a character
+		col := 0
+		for rest := line; rest != ""; {
+			cluster, cells := NextGrapheme(rest)
+			rest = rest[len(cluster):]
+			r, _ := utf8.DecodeRuneInString(cluster)
+			cellColor := c.ColorAt(Cell{Rune: r, Row: row, Col: col, Width: width, Height: len(lines)})
+			if cellColor != runColor {
+				flush()
+				runColor = cellColor
+			}
+			run.WriteString(cluster)
+			col += cells
+		}
+		flush()
+	}
+	return result
+}
+
+// RenderColorized draws text in style and colors it with c, if not nil
+func (r *Renderer) RenderColorized(text string, style Style, c Colorizer) string {
+	art := r.Render(text, style, nil)
+	if c == nil {
+		return art
+	}
+	return Colorize(art, c)
+}
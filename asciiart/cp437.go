@@ -0,0 +1,74 @@
+package asciiart
+
+import "strings"
+
+// cp437 maps each byte of code page 437, the character set of the IBM PC
+// and of BBS-era ANSI art, to Unicode. The low control codes show as the
+// glyphs the PC drew for them.
+var cp437 = [256]rune{
+	' ', '☺', '☻', '♥', '♦', '♣', '♠', '•', '◘', '○', '◙', '♂', '♀', '♪', '♫', '☼',
+	'►', '◄', '↕', '‼', '¶', '§', '▬', '↨', '↑', '↓', '→', '←', '∟', '↔', '▲', '▼',
+	' ', '!', '"', '#', '$', '%', '&', '\'', '(', ')', '*', '+', ',', '-', '.', '/',
+	'0', '1', '2', '3', '4', '5', '6', '7', '8', '9', ':', ';', '<', '=', '>', '?',
+	'@', 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N', 'O',
+	'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z', '[', '\\', ']', '^', '_',
+	'`', 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o',
+	'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'z', '{', '|', '}', '~', '⌂',
+	'Ç', 'ü', 'é', 'â', 'ä', 'à', 'å', 'ç', 'ê', 'ë', 'è', 'ï', 'î', 'ì', 'Ä', 'Å',
+	'É', 'æ', 'Æ', 'ô', 'ö', 'ò', 'û', 'ù', 'ÿ', 'Ö', 'Ü', '¢', '£', '¥', '₧', 'ƒ',
+	'á', 'í', 'ó', 'ú', 'ñ', 'Ñ', 'ª', 'º', '¿', '⌐', '¬', '½', '¼', '¡', '«', '»',
+	'░', '▒', '▓', '│', '┤', '╡', '╢', '╖', '╕', '╣', '║', '╗', '╝', '╜', '╛', '┐',
+	'└', '┴', '┬', '├', '─', '┼', '╞', '╟', '╚', '╔', '╩', '╦', '╠', '═', '╬', '╧',
+	'╨', '╤', '╥', '╙', '╘', '╒', '╓', '╫', '╪', '┘', '┌', '█', '▄', '▌', '▐', '▀',
+	'α', 'ß', 'Γ', 'π', 'Σ', 'σ', 'µ', 'τ', 'Φ', 'Θ', 'Ω', 'δ', '∞', 'φ', 'ε', '∩',
+	'≡', '±', '≥', '≤', '⌠', '⌡', '÷', '≈', '°', '∙', '·', '√', 'ⁿ', '²', '■', '\u00a0',
+}
+
+// cp437Bytes maps Unicode back to code page 437
+var cp437Bytes = func() map[rune]byte {
+	bytes := make(map[rune]byte, len(cp437))
+	for i := len(cp437) - 1; i >= 0; i-- {
+		bytes[cp437[i]] = byte(i)
+	}
+	return bytes
+}()
+
+// cp437Controls are the bytes that keep their control meaning in text
+// and ANSI art, rather than showing as glyphs: tab, newline, carriage
+// return, the end of file mark before a SAUCE record, and escape
+const cp437Controls = "\t\n\r\x1a\x1b"
+
+// DecodeCP437 converts code page 437 text to UTF-8. Tabs, line breaks,
+// the end of file mark and escape stay control characters.
+func DecodeCP437(data []byte) string {
+	var b strings.Builder
+	b.Grow(len(data))
+	for _, c := range data {
+		if strings.IndexByte(cp437Controls, c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			b.WriteRune(cp437[c])
+		}
+	}
+	return b.String()
+}
+
+// EncodeCP437 converts UTF-8 text to code page 437, the reverse of
+// DecodeCP437. Characters code page 437 lacks become '?', and lossless
+// reports whether there were none.
+func EncodeCP437(s string) (data []byte, lossless bool) {
+	data = make([]byte, 0, len(s))
+	lossless = true
+	for _, r := range s {
+		if r < 0x80 && (r >= ' ' || strings.ContainsRune(cp437Controls, r)) {
+			data = append(data, byte(r))
+			continue
+		}
+		c, ok := cp437Bytes[r]
+		if !ok {
+			c, lossless = '?', false
+		}
+		data = append(data, c)
+	}
+	return data, lossless
+}
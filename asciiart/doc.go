@@ -0,0 +1,8 @@
+// Package asciiart renders text as styled, decorated and colored ASCII
+// art. It holds the style catalog, the decorator engine and the color
+// schemes used by the ascii-art command:
+//
+//	r := asciiart.NewRenderer()
+//	style := asciiart.DefaultCategories()[1].Styles[1] // Double Box
+//	fmt.Println(r.Render("Hello", style, &asciiart.DefaultColorSchemes()[0]))
+package asciiart
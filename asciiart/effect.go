@@ -0,0 +1,58 @@
+package asciiart
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Effect reshapes art before or after its border, such as adding a
+// shadow, so applications and plugins can contribute their own. Named
+// effects are what decorators refer to as their pre and post steps.
+type Effect interface {
+	Apply(art string) (string, error)
+}
+
+// EffectFunc adapts a plain function that cannot fail to the Effect
+// interface
+type EffectFunc func(art string) string
+
+// Apply calls f(art)
+func (f EffectFunc) Apply(art string) (string, error) {
+	return f(art), nil
+}
+
+var (
+	effectsMu sync.RWMutex
+	effects   = map[string]Effect{
+		"shadow":  EffectFunc(AddShadow),
+		"braille": EffectFunc(Braille),
+	}
+)
+
+// RegisterEffect makes effect available under name, replacing any effect
+// registered under it before. Names are case-insensitive.
+func RegisterEffect(name string, effect Effect) {
+	effectsMu.Lock()
+	defer effectsMu.Unlock()
+	effects[strings.ToLower(name)] = effect
+}
+
+// LookupEffect returns the effect registered under name, or nil
+func LookupEffect(name string) Effect {
+	effectsMu.RLock()
+	defer effectsMu.RUnlock()
+	return effects[strings.ToLower(name)]
+}
+
+// EffectNames returns the names of the registered effects, sorted
+func EffectNames() []string {
+	effectsMu.RLock()
+	defer effectsMu.RUnlock()
+	names := make([]string, 0, len(effects))
+	for name := range effects {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
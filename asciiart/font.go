@@ -0,0 +1,301 @@
+package asciiart
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/common-nighthawk/go-figure"
+)
+
+// Font draws individual characters. The renderer sets glyphs side by side,
+// so any backend works: FIGlet files, go-figure's bundled fonts, the
+// native JSON format or the built-in bitmap font.
+//
+// A font may also implement Baseline() int, after which blank rows are
+// dropped as FIGlet does, and RightToLeft() bool for fonts that print
+// from right to left.
+type Font interface {
+	Height() int
+	Glyph(r rune) []string // Height() rows, or nil if the font cannot draw r
+}
+
+type baseliner interface {
+	Baseline() int
+}
+
+type rightToLeft interface {
+	RightToLeft() bool
+}
+
+// DrawText renders one line of text in f, one row per line. Characters f
+// cannot draw are shown as '?' where the font has one.
+func DrawText(text string, f Font) string {
+	runes := []rune(text)
+	if rtl, ok := f.(rightToLeft); ok && rtl.RightToLeft() {
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+	}
+
+	var glyphs [][]string
+	for _, r := range runes {
+		glyph := f.Glyph(r)
+		if glyph == nil {
+			glyph = f.Glyph('?')
+		}
+		if glyph != nil {
+			glyphs = append(glyphs, glyph)
+		}
+	}
+
+	baseline := f.Height()
+	if b, ok := f.(baseliner); ok {
+		baseline = b.Baseline()
+	}
+
+	var result strings.Builder
+	for row := range f.Height() {
+		var line strings.Builder
+		for _, glyph := range glyphs {
+			if row < len(glyph) {
+				line.WriteString(glyph[row])
+			}
+		}
+		if row < baseline || strings.TrimSpace(line.String()) != "" {
+			result.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+		}
+	}
+	return result.String()
+}
+
+// missingGlyphs lists the runes of text that f cannot draw
+func missingGlyphs(text string, f Font) []rune {
+	var missing []rune
+	for _, r := range text {
+		if r != '\n' && f.Glyph(r) == nil {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// Font returns the named font from FontDirs (<font>.flf or <font>.json),
+// go-figure's bundled fonts or the bitmap font. Unknown fonts fall back to
+// FallbackFont with a warning.
+func (r *Renderer) Font(name string) Font {
+	if p, ok := r.findFont(name); ok {
+		f, err := LoadFont(p)
+		if err == nil {
+			return f
+		}
+		r.warnf("%v", err)
+	}
+
+	if name == BitmapFontName {
+		return BitmapFont
+	}
+	if !BuiltinFont(name) {
+		r.warnf("font %q not found, using %s", name, FallbackFont)
+		name = FallbackFont
+	}
+	f, err := GoFigureFont(name)
+	if err != nil {
+		// The bundled fonts always parse
+		panic(err)
+	}
+	return f
+}
+
+// LoadFont reads a FIGlet (.flf) or JSON (.json) font file
+func LoadFont(p string) (Font, error) {
+	file, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var f Font
+	if strings.EqualFold(filepath.Ext(p), ".json") {
+		f, err = ParseJSONFont(file)
+	} else {
+		f, err = ParseFLF(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", p, err)
+	}
+	return f, nil
+}
+
+// GoFigureFont returns one of the fonts bundled with go-figure
+func GoFigureFont(name string) (Font, error) {
+	data, err := figure.Asset(path.Join("fonts", name+".flf"))
+	if err != nil {
+		return nil, fmt.Errorf("font %q not found", name)
+	}
+	return ParseFLF(bytes.NewReader(data))
+}
+
+// figletGlyphs is the number of printable ASCII characters, ' ' to '~'
+const figletGlyphs = '~' - ' ' + 1
+
+// figletFont holds the printable ASCII glyphs of a FIGlet font
+type figletFont struct {
+	height   int
+	baseline int
+	reverse  bool
+	letters  [][]string // Glyphs for ' ' to '~'
+}
+
+// ParseFLF reads a FIGlet font, interpreting it the way go-figure does
+// so art looks the same whichever backend draws it
+func ParseFLF(r io.Reader) (Font, error) {
+	scanner := bufio.NewScanner(r)
+	f := &figletFont{}
+	var hardblank byte = ' '
+	found := false
+	for scanner.Scan() {
+		header := strings.Fields(scanner.Text())
+		if len(header) < 3 || !strings.HasPrefix(header[0], "flf2") {
+			continue
+		}
+		f.height, _ = strconv.Atoi(header[1])
+		f.baseline, _ = strconv.Atoi(header[2])
+		if blank := header[0][len(header[0])-1]; blank != 'a' && blank != '2' {
+			hardblank = blank
+		}
+		f.reverse = len(header) > 6 && header[6] == "1"
+		found = true
+		break
+	}
+	if !found || f.height <= 0 {
+		return nil, fmt.Errorf("not a FIGlet font")
+	}
+
+	// go-figure draws the space as two blanks and skips the header
+	// comments along with the font's own space glyph
+	space := make([]string, f.height)
+	for i := range space {
+		space[i] = "  "
+	}
+	f.letters = [][]string{space}
+
+	letter := 0
+	for len(f.letters) <= figletGlyphs && scanner.Scan() {
+		line := scanner.Text()
+		last, cut := f.endsGlyph(line), 1
+		if last {
+			f.letters = append(f.letters, []string{})
+			if f.height > 1 {
+				cut = 2
+			}
+		}
+		if letter > 0 {
+			text := ""
+			if len(line) > 1 {
+				text = strings.ReplaceAll(line[:len(line)-cut], string(hardblank), " ")
+			}
+			f.letters[letter] = append(f.letters[letter], text)
+		}
+		if last {
+			letter++
+		}
+	}
+	if last := len(f.letters) - 1; len(f.letters[last]) == 0 {
+		f.letters = f.letters[:last]
+	}
+	return f, scanner.Err()
+}
+
+// endsGlyph reports whether line is the last row of a glyph, which ends
+// in a doubled end mark (a single one for one-row fonts)
+func (f *figletFont) endsGlyph(line string) bool {
+	length := 2
+	if f.height == 1 && len(line) > 0 {
+		length = 1
+	}
+	if len(line) < length {
+		return false
+	}
+	end := line[len(line)-length:]
+	for _, mark := range []string{"@", "#", "$"} {
+		if end == strings.Repeat(mark, length) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *figletFont) Height() int       { return f.height }
+func (f *figletFont) Baseline() int     { return f.baseline }
+func (f *figletFont) RightToLeft() bool { return f.reverse }
+
+func (f *figletFont) Glyph(r rune) []string {
+	if r < ' ' || r > '~' || int(r-' ') >= len(f.letters) || len(f.letters[r-' ']) == 0 {
+		return nil
+	}
+	return f.letters[r-' ']
+}
+
+// jsonFont is the native font format: a height and a table of glyphs,
+// each a list of rows
+//
+//	{"height": 2, "glyphs": {"A": ["/\\", "/--\\"], " ": ["  ", "  "]}}
+type jsonFont struct {
+	height int
+	glyphs map[rune][]string
+}
+
+// ParseJSONFont reads a font in the native JSON format. Rows are padded
+// so every glyph is a rectangle.
+func ParseJSONFont(r io.Reader) (Font, error) {
+	var file struct {
+		Height int                 `json:"height"`
+		Glyphs map[string][]string `json:"glyphs"`
+	}
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+	if file.Height <= 0 {
+		return nil, fmt.Errorf("font height must be positive")
+	}
+
+	f := &jsonFont{height: file.Height, glyphs: make(map[rune][]string)}
+	for key, rows := range file.Glyphs {
+		char, size := utf8.DecodeRuneInString(key)
+		if size == 0 || size != len(key) {
+			return nil, fmt.Errorf("glyph key %q must be a single character", key)
+		}
+		if len(rows) > file.Height {
+			return nil, fmt.Errorf("glyph %q has %d rows, font height is %d", key, len(rows), file.Height)
+		}
+		width := 0
+		for _, row := range rows {
+			width = max(width, DisplayWidth(row))
+		}
+		glyph := make([]string, file.Height)
+		for i := range glyph {
+			if i < len(rows) {
+				glyph[i] = PadToWidth(rows[i], width)
+			} else {
+				glyph[i] = strings.Repeat(" ", width)
+			}
+		}
+		f.glyphs[char] = glyph
+	}
+	return f, nil
+}
+
+func (f *jsonFont) Height() int { return f.height }
+
+func (f *jsonFont) Glyph(r rune) []string {
+	return f.glyphs[r]
+}
@@ -0,0 +1,148 @@
+package asciiart
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Gradient directions
+const (
+	GradientHorizontal = "horizontal"
+	GradientVertical   = "vertical"
+	GradientDiagonal   = "diagonal"
+)
+
+// gradientSteps is how many distinct colors a gradient is quantized to.
+// Neighbouring cells then share colors and escape sequences.
+const gradientSteps = 64
+
+// GradientPresets are named gradients usable wherever hex stops are
+var GradientPresets = map[string][]RGB{
+	"fire":   {{0x8b, 0x00, 0x00}, {0xff, 0x45, 0x00}, {0xff, 0xd7, 0x00}},
+	"ocean":  {{0x00, 0x1f, 0x3f}, {0x00, 0x74, 0xd9}, {0x7f, 0xdb, 0xff}},
+	"pride":  {{0xe4, 0x03, 0x03}, {0xff, 0x8c, 0x00}, {0xff, 0xed, 0x00}, {0x00, 0x80, 0x26}, {0x24, 0x40, 0x8e}, {0x73, 0x29, 0x82}},
+	"sunset": {{0xff, 0x5f, 0x6d}, {0xff, 0xc3, 0x71}},
+	"forest": {{0x13, 0x4e, 0x5e}, {0x71, 0xb2, 0x80}},
+}
+
+// Gradient colors art by its position, blending between color stops, or
+// cycling through the hues for a rainbow. It implements Colorizer and is
+// safe for concurrent use.
+type Gradient struct {
+	direction string
+	stops     []RGB
+	palette   []*color.Color
+	rainbow   *rainbowWave // Set for NewRainbow gradients
+}
+
+// NewGradient blends stops in direction, downgrading colors to depth
+func NewGradient(stops []RGB, direction string, depth int) (*Gradient, error) {
+	if len(stops) == 0 {
+		return nil, fmt.Errorf("gradient needs at least one color")
+	}
+	switch direction {
+	case "":
+		direction = GradientHorizontal
+	case GradientHorizontal, GradientVertical, GradientDiagonal:
+	default:
+		return nil, fmt.Errorf("unknown gradient direction %q", direction)
+	}
+
+	g := &Gradient{direction: direction, stops: stops}
+	for i := range gradientSteps {
+		g.palette = append(g.palette, blend(stops, float64(i)/(gradientSteps-1)).Color(depth))
+	}
+	return g, nil
+}
+
+// ParseGradient reads a preset name or colon/comma separated hex stops
+// such as "#ff0000:#0000ff"
+func ParseGradient(spec string) ([]RGB, error) {
+	if stops, ok := GradientPresets[strings.ToLower(spec)]; ok {
+		return stops, nil
+	}
+	var stops []RGB
+	for _, part := range strings.FieldsFunc(spec, func(r rune) bool { return r == ':' || r == ',' }) {
+		stop, err := ParseHex(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("%v (or use a preset: %s)", err, strings.Join(GradientNames(), ", "))
+		}
+		stops = append(stops, stop)
+	}
+	if len(stops) == 0 {
+		return nil, fmt.Errorf("empty gradient %q", spec)
+	}
+	return stops, nil
+}
+
+// GradientNames lists the presets in alphabetical order
+func GradientNames() []string {
+	var names []string
+	for name := range GradientPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ColorAt returns the gradient color at the cell's position
+func (g *Gradient) ColorAt(cell Cell) *color.Color {
+	var t float64
+	switch g.direction {
+	case GradientHorizontal:
+		t = fraction(cell.Col, cell.Width)
+	case GradientVertical:
+		t = fraction(cell.Row, cell.Height)
+	case GradientDiagonal:
+		// Cells are about twice as tall as wide, so rows count double
+		t = fraction(cell.Col+2*cell.Row, cell.Width+2*cell.Height-1)
+	case gradientRainbow:
+		t = g.rainbow.at(cell)
+	}
+	return g.palette[int(t*float64(len(g.palette)-1)+0.5)]
+}
+
+// WithDepth returns g with its colors downgraded to depth instead, such
+// as DepthTrue for output that is not shown on this terminal
+func (g *Gradient) WithDepth(depth int) *Gradient {
+	if g.rainbow != nil {
+		return NewRainbow(g.rainbow.frequency, g.rainbow.phase, depth)
+	}
+	rebuilt, _ := NewGradient(g.stops, g.direction, depth)
+	return rebuilt
+}
+
+// Forced returns a copy of g whose colors ignore color.NoColor
+func (g *Gradient) Forced() *Gradient {
+	forced := &Gradient{direction: g.direction, stops: g.stops, rainbow: g.rainbow}
+	for _, c := range g.palette {
+		clone := *c
+		clone.EnableColor()
+		forced.palette = append(forced.palette, &clone)
+	}
+	return forced
+}
+
+// fraction maps i in [0, n) onto [0, 1]
+func fraction(i, n int) float64 {
+	if n <= 1 {
+		return 0
+	}
+	return min(1, float64(i)/float64(n-1))
+}
+
+// blend returns the color at t in [0, 1] along evenly spaced stops
+func blend(stops []RGB, t float64) RGB {
+	if len(stops) == 1 {
+		return stops[0]
+	}
+	pos := t * float64(len(stops)-1)
+	i := min(int(pos), len(stops)-2)
+	f := pos - float64(i)
+	a, b := stops[i], stops[i+1]
+	mix := func(x, y int) int { return x + int(f*float64(y-x)+0.5) }
+	return RGB{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B)}
+}
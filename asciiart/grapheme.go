@@ -0,0 +1,73 @@
+package asciiart
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	zeroWidthJoiner = '\u200d'
+	emojiVariation  = '\ufe0f' // Variation selector 16, emoji presentation
+)
+
+// NextGrapheme returns the grapheme cluster at the start of s, what a
+// reader sees as one character, and the terminal cells it occupies.
+// Combining marks, variation selectors and skin tones stay with the
+// character they modify, emoji joined by zero width joiners form one
+// cluster, and so do the pairs of regional indicators that make flags.
+func NextGrapheme(s string) (cluster string, width int) {
+	if s == "" {
+		return "", 0
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	width = runeWidth(r)
+	if unicode.IsControl(r) {
+		return s[:size], width
+	}
+	if isRegionalIndicator(r) {
+		if next, n := utf8.DecodeRuneInString(s[size:]); isRegionalIndicator(next) {
+			return s[:size+n], 2
+		}
+	}
+
+	i := size
+	for i < len(s) {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == zeroWidthJoiner:
+			i += n
+			// The joiner glues on the next character, which draws
+			// into the same cells
+			if next, n := utf8.DecodeRuneInString(s[i:]); i < len(s) && !unicode.IsControl(next) {
+				i += n
+			}
+		case r == emojiVariation:
+			width = max(width, 2)
+			i += n
+		case unicode.Is(unicode.Mc, r):
+			// Spacing marks join the cluster but still take a cell
+			width += runeWidth(r)
+			i += n
+		case runeWidth(r) == 0 || isSkinTone(r) || isHangulTrailer(r):
+			i += n
+		default:
+			return s[:i], width
+		}
+	}
+	return s, width
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// isSkinTone reports whether r is an emoji skin tone modifier
+func isSkinTone(r rune) bool {
+	return r >= 0x1F3FB && r <= 0x1F3FF
+}
+
+// isHangulTrailer reports whether r is a medial vowel or final consonant
+// jamo, which combine with a leading consonant into one syllable
+func isHangulTrailer(r rune) bool {
+	return r >= 0x1160 && r <= 0x11FF
+}
@@ -0,0 +1,193 @@
+package asciiart
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// Half-block characters; each terminal cell shows two pixels stacked
+const (
+	upperHalf = "▀"
+	lowerHalf = "▄"
+	fullBlock = "█"
+)
+
+// Pixel is one dot of a Raster
+type Pixel struct {
+	Ink   bool // Drawn, rather than left to the terminal background
+	Color *RGB // nil for the terminal's default foreground
+}
+
+// Raster is a grid of pixels, rows top to bottom, to be drawn with
+// HalfBlocks at two pixels to a character cell
+type Raster [][]Pixel
+
+// RasterizeArt turns rendered art into a raster with one pixel for each
+// cell of art, inked where the art is not blank and colored by c, if it
+// is not nil. Block fonts then draw at twice their usual vertical
+// resolution, with square pixels.
+func RasterizeArt(art string, c Colorizer) Raster {
+	lines := strings.Split(art, "\n")
+	width := artWidth(art)
+	raster := make(Raster, len(lines))
+	for y, line := range lines {
+		raster[y] = make([]Pixel, width)
+		x := 0
+		for line != "" {
+			cluster, cells := NextGrapheme(line)
+			line = line[len(cluster):]
+			if strings.TrimLeft(cluster, " \u2800") == "" {
+				x += cells
+				continue
+			}
+			pixel := Pixel{Ink: true}
+			if c != nil {
+				cell := Cell{Rune: []rune(cluster)[0], Row: y, Col: x, Width: width, Height: len(lines)}
+				if col := c.ColorAt(cell); col != nil {
+					if rgb, ok := ColorRGB(col); ok {
+						pixel.Color = &rgb
+					}
+				}
+			}
+			for range cells {
+				raster[y][x] = pixel
+				x++
+			}
+		}
+	}
+	return raster
+}
+
+// RasterizeImage samples img into a raster sized like ConvertImage would
+// size it, allowing for two pixels to a cell: opts.Height still counts
+// lines of output. Each pixel takes the average color beneath it, and
+// mostly transparent areas are left uninked. The ramp, gamma and invert
+// options do not apply.
+func RasterizeImage(img image.Image, opts ImageOptions) Raster {
+	if opts.CellAspect <= 0 {
+		opts.CellAspect = DefaultCellAspect
+	}
+	opts.CellAspect *= 2
+	opts.Height *= 2
+	return rasterizeImage(img, opts)
+}
+
+// rasterizeImage samples img into a raster of opts.Width by opts.Height
+// pixels, or sized to fit one of them
+func rasterizeImage(img image.Image, opts ImageOptions) Raster {
+	bounds := img.Bounds()
+	width, height := imageSize(bounds.Dx(), bounds.Dy(), opts)
+	if width == 0 || height == 0 {
+		return nil
+	}
+	raster := make(Raster, height)
+	for y := range height {
+		raster[y] = make([]Pixel, width)
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := range width {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+			if rgb, opaque := averageColor(img, x0, y0, x1, y1); opaque {
+				raster[y][x] = Pixel{Ink: true, Color: &rgb}
+			}
+		}
+	}
+	return raster
+}
+
+// averageColor returns the mean color of the pixels in [x0,x1) x [y0,y1),
+// weighted by their alpha, and whether they are mostly opaque
+func averageColor(img image.Image, x0, y0, x1, y1 int) (RGB, bool) {
+	var r, g, b, a float64
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			pr, pg, pb, pa := img.At(x, y).RGBA()
+			r, g, b, a = r+float64(pr), g+float64(pg), b+float64(pb), a+float64(pa)
+		}
+	}
+	if a == 0 || a/float64((x1-x0)*(y1-y0)) < 0x7fff {
+		return RGB{}, false
+	}
+	// Colors are alpha-premultiplied, so dividing by alpha undoes it
+	return RGB{int(r / a * 255), int(g / a * 255), int(b / a * 255)}, true
+}
+
+// HalfBlocks draws r with ▀, ▄ and █, two pixels to a character: the
+// upper pixel in the foreground color and the lower one in the background
+// color when both are inked in different colors. Colors are written for a
+// terminal showing depth colors; a depth of 0 draws the shapes alone.
+func (r Raster) HalfBlocks(depth int) string {
+	var lines []string
+	for y := 0; y < len(r); y += 2 {
+		var b strings.Builder
+		current := ""
+		for x := range r[y] {
+			top, bottom := r[y][x], Pixel{}
+			if y+1 < len(r) {
+				bottom = r[y+1][x]
+			}
+			if depth == 0 {
+				top.Color, bottom.Color = nil, nil
+			}
+
+			text, sgr := " ", ""
+			switch {
+			case top.Ink && bottom.Ink && sameColor(top.Color, bottom.Color):
+				text, sgr = fullBlock, colorSGR(top.Color, depth, false)
+			case top.Ink && bottom.Ink && bottom.Color == nil:
+				// The default foreground cannot be a background, so
+				// swap the halves
+				text, sgr = lowerHalf, colorSGR(top.Color, depth, true)
+			case top.Ink && bottom.Ink:
+				text, sgr = upperHalf, colorSGR(top.Color, depth, false)+colorSGR(bottom.Color, depth, true)
+			case top.Ink:
+				text, sgr = upperHalf, colorSGR(top.Color, depth, false)
+			case bottom.Ink:
+				text, sgr = lowerHalf, colorSGR(bottom.Color, depth, false)
+			}
+			if sgr != current {
+				if current != "" {
+					b.WriteString("\x1b[0m")
+				}
+				b.WriteString(sgr)
+				current = sgr
+			}
+			b.WriteString(text)
+		}
+		if current != "" {
+			b.WriteString("\x1b[0m")
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func sameColor(a, b *RGB) bool {
+	return a == b || (a != nil && b != nil && *a == *b)
+}
+
+// colorSGR returns the escape sequence setting c as the foreground, or
+// the background, for a terminal showing depth colors. A nil c is the
+// default foreground and needs no sequence.
+func colorSGR(c *RGB, depth int, background bool) string {
+	if c == nil || depth == 0 {
+		return ""
+	}
+	layer := 38
+	if background {
+		layer = 48
+	}
+	switch {
+	case depth >= DepthTrue:
+		return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", layer, c.R, c.G, c.B)
+	case depth >= Depth256:
+		return fmt.Sprintf("\x1b[%d;5;%dm", layer, c.index256())
+	}
+	attr := int(c.nearestBasic())
+	if background {
+		attr += 10
+	}
+	return fmt.Sprintf("\x1b[%dm", attr)
+}
@@ -0,0 +1,56 @@
+package asciiart
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// HTML wraps art in a <pre> block for web pages and emails. Colors from
+// c, if not nil, are carried by spans with inline CSS rather than ANSI
+// escape codes.
+func HTML(text string, c Colorizer) string {
+	if c == nil {
+		return "<pre>" + html.EscapeString(text) + "</pre>"
+	}
+
+	styles := make(map[*color.Color]string)
+	css := func(c *color.Color) string {
+		if c == nil {
+			return ""
+		}
+		style, ok := styles[c]
+		if !ok {
+			if rgb, found := ColorRGB(c); found {
+				style = fmt.Sprintf("color:#%02x%02x%02x", rgb.R, rgb.G, rgb.B)
+			}
+			styles[c] = style
+		}
+		return style
+	}
+
+	var result strings.Builder
+	result.WriteString("<pre>")
+	for row, runs := range colorRuns(text, c) {
+		if row > 0 {
+			result.WriteByte('\n')
+		}
+		// Runs in different colors can still share a CSS color
+		for i := 0; i < len(runs); {
+			style := css(runs[i].color)
+			var span strings.Builder
+			for ; i < len(runs) && css(runs[i].color) == style; i++ {
+				span.WriteString(runs[i].text)
+			}
+			if style == "" {
+				result.WriteString(html.EscapeString(span.String()))
+			} else {
+				fmt.Fprintf(&result, `<span style="%s">%s</span>`, style, html.EscapeString(span.String()))
+			}
+		}
+	}
+	result.WriteString("</pre>")
+	return result.String()
+}
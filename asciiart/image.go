@@ -0,0 +1,98 @@
+package asciiart
+
+import (
+	"image"
+	"math"
+	"strings"
+)
+
+// DefaultRamp lists characters from least to most ink
+const DefaultRamp = " .:-=+*#%@"
+
+// DefaultCellAspect is the width/height ratio of a typical terminal cell
+const DefaultCellAspect = 0.5
+
+// ImageOptions control how images are converted to characters
+type ImageOptions struct {
+	Width      int     // Output width in characters; 0 derives it from Height
+	Height     int     // Output height in lines; 0 derives it from Width
+	Ramp       string  // Characters from least to most ink; DefaultRamp if empty
+	Gamma      float64 // Brightness curve applied before mapping; 1 is linear
+	Invert     bool    // Draw dark pixels with dense characters, for light backgrounds
+	CellAspect float64 // Cell width/height ratio used to keep proportions
+}
+
+// ConvertImage draws img with characters from the ramp, brighter areas
+// using denser characters. Each character covers the average brightness
+// of the pixels beneath it.
+func ConvertImage(img image.Image, opts ImageOptions) string {
+	ramp := []rune(opts.Ramp)
+	if len(ramp) == 0 {
+		ramp = []rune(DefaultRamp)
+	}
+	if opts.Gamma <= 0 {
+		opts.Gamma = 1
+	}
+	if opts.CellAspect <= 0 {
+		opts.CellAspect = DefaultCellAspect
+	}
+
+	bounds := img.Bounds()
+	width, height := imageSize(bounds.Dx(), bounds.Dy(), opts)
+	if width == 0 || height == 0 {
+		return ""
+	}
+
+	var result strings.Builder
+	for row := range height {
+		if row > 0 {
+			result.WriteByte('\n')
+		}
+		y0 := bounds.Min.Y + row*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(row+1)*bounds.Dy()/height)
+		for col := range width {
+			x0 := bounds.Min.X + col*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(col+1)*bounds.Dx()/width)
+
+			level := math.Pow(averageLuminance(img, x0, y0, x1, y1), opts.Gamma)
+			if opts.Invert {
+				level = 1 - level
+			}
+			index := min(len(ramp)-1, int(level*float64(len(ramp))))
+			result.WriteRune(ramp[index])
+		}
+	}
+	return result.String()
+}
+
+// imageSize works out the character grid for a w x h pixel image
+func imageSize(w, h int, opts ImageOptions) (int, int) {
+	if w == 0 || h == 0 {
+		return 0, 0
+	}
+	width, height := opts.Width, opts.Height
+	switch {
+	case width > 0 && height > 0:
+	case width > 0:
+		height = int(math.Round(float64(width) * float64(h) / float64(w) * opts.CellAspect))
+	case height > 0:
+		width = int(math.Round(float64(height) * float64(w) / float64(h) / opts.CellAspect))
+	default:
+		width = min(w, 80)
+		height = int(math.Round(float64(width) * float64(h) / float64(w) * opts.CellAspect))
+	}
+	return max(1, width), max(1, height)
+}
+
+// averageLuminance returns the mean relative luminance, 0 to 1, of the
+// pixels in [x0,x1) x [y0,y1). Transparent pixels count as black.
+func averageLuminance(img image.Image, x0, y0, x1, y1 int) float64 {
+	var sum float64
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			sum += (0.2126*float64(r) + 0.7152*float64(g) + 0.0722*float64(b)) / 0xffff
+		}
+	}
+	return sum / float64((x1-x0)*(y1-y0))
+}
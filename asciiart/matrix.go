@@ -0,0 +1,124 @@
+package asciiart
+
+import "encoding/binary"
+
+// Raster returns the canvas as a raster for an LED matrix or the like:
+// each cell that is not blank becomes a pixel one wide and two tall, the
+// shape it has on a terminal. Cells in the default foreground have no
+// color of their own.
+func (c *Canvas) Raster() Raster {
+	rows := c.trimmed()
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	raster := make(Raster, 0, len(rows)*2)
+	for _, row := range rows {
+		line := make([]Pixel, width)
+		for x, cell := range row {
+			if cell.Rune == 0 && x > 0 {
+				// The second cell of a wide character
+				line[x] = line[x-1]
+				continue
+			}
+			if cell.Rune == ' ' || cell.Rune == '\u2800' {
+				continue
+			}
+			line[x].Ink = true
+			if cell.FG != DefaultFG {
+				rgb := c.RGB(cell.FG)
+				line[x].Color = &rgb
+			}
+		}
+		raster = append(raster, line, line)
+	}
+	return raster
+}
+
+// Fit returns r scaled to fit a matrix of width by height pixels and
+// centered on it: enlarged by a whole factor if it is smaller, so pixels
+// stay crisp, and sampled down if it is larger.
+func (r Raster) Fit(width, height int) Raster {
+	w, h := 0, len(r)
+	for _, row := range r {
+		w = max(w, len(row))
+	}
+	fitted := make(Raster, height)
+	for y := range fitted {
+		fitted[y] = make([]Pixel, width)
+	}
+	if w == 0 || h == 0 {
+		return fitted
+	}
+
+	scale := min(float64(width)/float64(w), float64(height)/float64(h))
+	if scale >= 1 {
+		scale = float64(int(scale))
+	}
+	sw, sh := int(float64(w)*scale), int(float64(h)*scale)
+	left, top := (width-sw)/2, (height-sh)/2
+	for y := range sh {
+		row := r[min(int(float64(y)/scale), h-1)]
+		for x := range sw {
+			if sx := int(float64(x) / scale); sx < len(row) {
+				fitted[top+y][left+x] = row[sx]
+			}
+		}
+	}
+	return fitted
+}
+
+// pixelRGB is the color an LED shows for p: off when it is not inked,
+// white when it has no color of its own
+func pixelRGB(p Pixel) RGB {
+	switch {
+	case !p.Ink:
+		return RGB{}
+	case p.Color == nil:
+		return RGB{255, 255, 255}
+	}
+	return *p.Color
+}
+
+// Mono packs r one bit per pixel, set where it is inked: rows top to
+// bottom, the leftmost pixel in the most significant bit, and each row
+// padded to a whole byte
+func (r Raster) Mono() []byte {
+	var data []byte
+	for _, row := range r {
+		packed := make([]byte, (len(row)+7)/8)
+		for x, p := range row {
+			if p.Ink {
+				packed[x/8] |= 0x80 >> (x % 8)
+			}
+		}
+		data = append(data, packed...)
+	}
+	return data
+}
+
+// RGB565 packs r 16 bits per pixel, five for red, six for green and five
+// for blue, big-endian, rows top to bottom
+func (r Raster) RGB565() []byte {
+	var data []byte
+	for _, row := range r {
+		for _, p := range row {
+			c := pixelRGB(p)
+			data = binary.BigEndian.AppendUint16(data, uint16(c.R>>3<<11|c.G>>2<<5|c.B>>3))
+		}
+	}
+	return data
+}
+
+// RGB888 packs r three bytes per pixel, red, green and blue, rows top to
+// bottom
+func (r Raster) RGB888() []byte {
+	var data []byte
+	for _, row := range r {
+		for _, p := range row {
+			c := pixelRGB(p)
+			data = append(data, byte(c.R), byte(c.G), byte(c.B))
+		}
+	}
+	return data
+}
@@ -0,0 +1,44 @@
+package asciiart
+
+import "math"
+
+// Rainbow defaults, as in lolcat
+const (
+	DefaultRainbowFrequency = 0.1
+	// rainbowSpread is how many characters along a line advance the hue
+	// as much as one line down
+	rainbowSpread = 3.0
+)
+
+// gradientRainbow is the direction of NewRainbow gradients
+const gradientRainbow = "rainbow"
+
+// rainbowWave places cells along the hue cycle
+type rainbowWave struct {
+	frequency, phase float64
+}
+
+// at returns where the cell falls in the hue cycle, in [0, 1)
+func (w *rainbowWave) at(cell Cell) float64 {
+	angle := w.frequency * (w.phase + float64(cell.Row) + float64(cell.Col)/rainbowSpread)
+	t := math.Mod(angle/(2*math.Pi), 1)
+	if t < 0 {
+		t++
+	}
+	return t
+}
+
+// NewRainbow returns a lolcat-style rainbow that shifts hue with every
+// character and line rather than every line. frequency is how fast the
+// hue changes, in radians per line; phase shifts where the cycle starts,
+// in lines.
+func NewRainbow(frequency, phase float64, depth int) *Gradient {
+	g := &Gradient{direction: gradientRainbow, rainbow: &rainbowWave{frequency: frequency, phase: phase}}
+	// The palette covers one full cycle, ending on the color it starts with
+	for i := range gradientSteps {
+		angle := 2 * math.Pi * float64(i) / (gradientSteps - 1)
+		wave := func(offset float64) int { return int(math.Sin(angle+offset)*127 + 128) }
+		g.palette = append(g.palette, RGB{wave(0), wave(2 * math.Pi / 3), wave(4 * math.Pi / 3)}.Color(depth))
+	}
+	return g
+}
@@ -0,0 +1,178 @@
+package asciiart
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/common-nighthawk/go-figure"
+	"github.com/fatih/color"
+)
+
+// FallbackFont is used when a style names a font that is not available
+const FallbackFont = "standard"
+
+// Renderer turns text into ASCII art
+type Renderer struct {
+	// FontDirs are searched in order for <font>.flf files, which take
+	// precedence over go-figure's bundled fonts
+	FontDirs []string
+	// Warnings receives notes about missing fonts and glyphs; nil
+	// discards them
+	Warnings io.Writer
+}
+
+// NewRenderer returns a Renderer that loads fonts from fontDirs and
+// writes warnings to stderr
+func NewRenderer(fontDirs ...string) *Renderer {
+	return &Renderer{FontDirs: fontDirs, Warnings: os.Stderr}
+}
+
+// Render draws text in style and colors it with colorScheme, if not nil
+func (r *Renderer) Render(text string, style Style, colorScheme *ColorScheme) string {
+	var asciiArt string
+	if style.Font != "" {
+		figureText, font, missing := prepareFigureText(text, style.Font)
+		f := r.Font(font)
+		r.reportCoverageGaps(font, append(missing, missingGlyphs(figureText, f)...))
+
+		var rendered []string
+		for _, line := range strings.Split(figureText, "\n") {
+			rendered = append(rendered, strings.TrimRight(DrawText(line, f), "\n"))
+		}
+		asciiArt = strings.Join(rendered, "\n")
+	} else {
+		asciiArt = text
+	}
+
+	d := style.Decorator
+	if d.Pre != nil {
+		asciiArt = d.Pre(asciiArt)
+	}
+
+	if d.Border != nil || d.Top != "" || d.Bottom != "" || d.Left != "" || d.Right != "" || d.Fill != "" {
+		asciiArt = ApplyDecorator(asciiArt, d)
+	}
+
+	if d.Post != nil {
+		asciiArt = d.Post(asciiArt)
+	}
+
+	if colorScheme != nil {
+		asciiArt = ApplyColorScheme(asciiArt, colorScheme)
+	}
+
+	return asciiArt
+}
+
+// Figure renders one line of text in the named font. Unknown fonts fall
+// back to FallbackFont with a warning.
+func (r *Renderer) Figure(text, font string) string {
+	return DrawText(text, r.Font(font))
+}
+
+// FontExists reports whether font is in FontDirs or built in
+func (r *Renderer) FontExists(font string) bool {
+	_, ok := r.findFont(font)
+	return ok || BuiltinFont(font)
+}
+
+// FontFile returns the file in FontDirs that text is drawn from in font,
+// after any switch to a font for its script, or false if the font is
+// built in
+func (r *Renderer) FontFile(text, font string) (string, bool) {
+	_, font, _ = prepareFigureText(text, font)
+	return r.findFont(font)
+}
+
+// findFont looks for <font>.flf, then <font>.json, in each of FontDirs.
+// Names are bare file names: one with a path separator, "..", or a
+// volume such as C: could reach a file outside FontDirs, so it is never
+// found there.
+func (r *Renderer) findFont(font string) (string, bool) {
+	if !validFontName(font) {
+		return "", false
+	}
+	for _, dir := range r.FontDirs {
+		for _, ext := range []string{".flf", ".json"} {
+			p := filepath.Join(dir, font+ext)
+			if _, err := os.Stat(p); err == nil {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+func validFontName(font string) bool {
+	return font != "" && !strings.ContainsAny(font, `/\`) && !strings.Contains(font, "..") && filepath.VolumeName(font) == ""
+}
+
+// BuiltinFont reports whether the named font is bundled with go-figure
+// or is the bitmap font
+func BuiltinFont(name string) bool {
+	if name == BitmapFontName {
+		return true
+	}
+	if !validFontName(name) {
+		return false
+	}
+	_, err := figure.Asset(path.Join("fonts", name+".flf"))
+	return err == nil
+}
+
+// BuiltinFonts returns the names of the fonts bundled with go-figure and
+// the bitmap font, sorted
+func BuiltinFonts() []string {
+	names := []string{BitmapFontName}
+	for _, asset := range figure.AssetNames() {
+		if name, ok := strings.CutSuffix(path.Base(asset), ".flf"); ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (r *Renderer) warnf(format string, args ...any) {
+	if r.Warnings != nil {
+		fmt.Fprintln(r.Warnings, color.YellowString("Warning: "+format, args...))
+	}
+}
+
+// ApplyDecorator draws d around text, using d.Border if it is set. Corner
+// and edge pieces may be wide characters such as emoji; the layout is
+// measured in terminal cells.
+func ApplyDecorator(text string, d Decorator) string {
+	if d.Border != nil {
+		return DrawBorder(text, d.Border, d.Fill)
+	}
+	return DrawBorder(text, decoratorBorder{d}, d.Fill)
+}
+
+// ApplyColorScheme colors text line by line, cycling through the
+// scheme's primary, secondary and background colors.
+func ApplyColorScheme(text string, cs *ColorScheme) string {
+	lines := strings.Split(text, "\n")
+	var result []string
+
+	for i, line := range lines {
+		colorIndex := i % 3
+		var colored string
+		switch colorIndex {
+		case 0:
+			colored = cs.Primary.Sprint(line)
+		case 1:
+			colored = cs.Secondary.Sprint(line)
+		case 2:
+			colored = cs.Background.Sprint(line)
+		}
+		result = append(result, colored)
+	}
+
+	return strings.Join(result, "\n")
+}
@@ -0,0 +1,53 @@
+package asciiart
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/common-nighthawk/go-figure"
+)
+
+func TestFindFontStaysInFontDirs(t *testing.T) {
+	root := t.TempDir()
+	fonts := filepath.Join(root, "fonts")
+	if err := os.Mkdir(fonts, 0755); err != nil {
+		t.Fatal(err)
+	}
+	standard, err := figure.Asset("fonts/standard.flf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{filepath.Join(fonts, "mine.flf"), filepath.Join(root, "secret.flf")} {
+		if err := os.WriteFile(p, standard, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := &Renderer{FontDirs: []string{fonts}}
+	tests := []struct {
+		name  string
+		found bool
+	}{
+		{"mine", true},
+		{"../secret", false},
+		{"..", false},
+		{"sub/../mine", false},
+		{filepath.Join(root, "secret"), false},
+		{`..\secret`, false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if _, found := r.findFont(test.name); found != test.found {
+			t.Errorf("findFont(%q) found = %v, want %v", test.name, found, test.found)
+		}
+	}
+	for _, name := range []string{"../fonts/standard", "fonts/../standard"} {
+		if r.FontExists(name) {
+			t.Errorf("FontExists(%q) = true, want names with paths refused", name)
+		}
+	}
+	if !r.FontExists("standard") {
+		t.Error("FontExists(\"standard\") = false for a built-in font")
+	}
+}
@@ -0,0 +1,137 @@
+package asciiart
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"time"
+)
+
+// SAUCE record layout: a 128 byte record at the very end of a file, after
+// an end of file mark, optionally preceded by a block of 64 byte comment
+// lines
+const (
+	sauceSize        = 128
+	sauceCommentSize = 64
+	sauceID          = "SAUCE00"
+	sauceCommentID   = "COMNT"
+	sauceDate        = "20060102"
+)
+
+// SAUCE data and file types for character art and binary text
+const (
+	SAUCECharacter  = 1 // Data type
+	SAUCEASCII      = 0 // File types of SAUCECharacter
+	SAUCEANSi       = 1
+	SAUCEBinaryText = 5 // Data type; the file type is half the width
+	SAUCEXBin       = 6 // Data type
+)
+
+// SAUCE is the metadata record the ANSI art scene appends to its files:
+// who made the art, when, and how it is meant to be shown
+type SAUCE struct {
+	Title    string
+	Author   string
+	Group    string
+	Date     time.Time // Zero if the record has none
+	FileSize int       // Size of the art without the record
+	DataType byte
+	FileType byte
+	Width    int // Characters per line (TInfo1)
+	Height   int // Lines (TInfo2)
+	Flags    byte
+	Font     string // Font the art was drawn in, such as "IBM VGA"
+	Comments []string
+}
+
+// SAUCE flag bits of character art
+const (
+	SAUCEiCEColors = 1 << 0 // Blink selects bright backgrounds
+)
+
+// ParseSAUCE splits data into the art and its SAUCE record, if it ends
+// with one. The art excludes the end of file mark and any comments.
+func ParseSAUCE(data []byte) (art []byte, record *SAUCE) {
+	if len(data) < sauceSize || !bytes.HasPrefix(data[len(data)-sauceSize:], []byte(sauceID)) {
+		return data, nil
+	}
+	r := data[len(data)-sauceSize:]
+	art = data[:len(data)-sauceSize]
+	field := func(from, to int) string {
+		return strings.TrimRight(DecodeCP437(bytes.TrimRight(r[from:to], "\x00")), " ")
+	}
+	record = &SAUCE{
+		Title:    field(7, 42),
+		Author:   field(42, 62),
+		Group:    field(62, 82),
+		FileSize: int(binary.LittleEndian.Uint32(r[90:94])),
+		DataType: r[94],
+		FileType: r[95],
+		Width:    int(binary.LittleEndian.Uint16(r[96:98])),
+		Height:   int(binary.LittleEndian.Uint16(r[98:100])),
+		Flags:    r[105],
+		Font:     field(106, 128),
+	}
+	record.Date, _ = time.Parse(sauceDate, field(82, 90))
+
+	if n := int(r[104]); n > 0 {
+		start := len(art) - len(sauceCommentID) - n*sauceCommentSize
+		if start >= 0 && bytes.HasPrefix(art[start:], []byte(sauceCommentID)) {
+			block := art[start+len(sauceCommentID):]
+			for i := range n {
+				line := block[i*sauceCommentSize : (i+1)*sauceCommentSize]
+				record.Comments = append(record.Comments, strings.TrimRight(DecodeCP437(bytes.TrimRight(line, "\x00")), " "))
+			}
+			art = art[:start]
+		}
+	}
+	return bytes.TrimSuffix(art, []byte{'\x1a'}), record
+}
+
+// Append returns art followed by an end of file mark and the record,
+// with FileSize set to the size of art. Text longer than a field is cut
+// short, and characters code page 437 lacks become '?'.
+func (s SAUCE) Append(art []byte) []byte {
+	var b bytes.Buffer
+	b.Write(art)
+	b.WriteByte('\x1a')
+	comments := s.Comments[:min(len(s.Comments), 255)]
+	if len(comments) > 0 {
+		b.WriteString(sauceCommentID)
+		for _, line := range comments {
+			b.Write(sauceField(line, sauceCommentSize))
+		}
+	}
+
+	b.WriteString(sauceID)
+	b.Write(sauceField(s.Title, 35))
+	b.Write(sauceField(s.Author, 20))
+	b.Write(sauceField(s.Group, 20))
+	date := "        "
+	if !s.Date.IsZero() {
+		date = s.Date.Format(sauceDate)
+	}
+	b.WriteString(date)
+	binary.Write(&b, binary.LittleEndian, uint32(len(art)))
+	b.WriteByte(s.DataType)
+	b.WriteByte(s.FileType)
+	binary.Write(&b, binary.LittleEndian, uint16(s.Width))
+	binary.Write(&b, binary.LittleEndian, uint16(s.Height))
+	b.Write(make([]byte, 4)) // TInfo3 and TInfo4
+	b.WriteByte(byte(len(comments)))
+	b.WriteByte(s.Flags)
+	font := make([]byte, 22)
+	copy(font, s.Font)
+	b.Write(font)
+	return b.Bytes()
+}
+
+// sauceField encodes s in code page 437, padded with spaces or cut to n
+// bytes
+func sauceField(s string, n int) []byte {
+	data, _ := EncodeCP437(s)
+	if len(data) > n {
+		data = data[:n]
+	}
+	return append(data, bytes.Repeat([]byte{' '}, n-len(data))...)
+}
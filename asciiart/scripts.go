@@ -0,0 +1,119 @@
+package asciiart
+
+import (
+	"strings"
+	"unicode"
+)
+
+// scriptFont is a FIGlet font that draws a non-Latin script. The bundled
+// fonts reuse ASCII slots for their letters, so input runes are translated
+// to the slot holding the matching glyph before rendering.
+type scriptFont struct {
+	script   string
+	table    *unicode.RangeTable
+	font     string
+	glyphs   map[rune]rune // Script rune -> ASCII slot in font
+	reserved string        // ASCII slots sacrificed for script glyphs
+}
+
+var scriptFonts = []scriptFont{
+	{
+		script: "Cyrillic",
+		table:  unicode.Cyrillic,
+		font:   "moscow",
+		glyphs: map[rune]rune{
+			'А': 'A', 'Б': 'B', 'В': 'V', 'Г': 'G', 'Д': 'D', 'Е': 'E', 'Ё': 'E',
+			'Ж': 'J', 'З': 'Z', 'И': 'I', 'Й': '>', 'К': 'K', 'Л': 'L', 'М': 'M',
+			'Н': 'N', 'О': 'O', 'П': 'P', 'Р': 'R', 'С': 'S', 'Т': 'T', 'У': 'U',
+			'Ф': 'F', 'Х': 'H', 'Ц': 'Q', 'Ч': 'C', 'Ш': 'W', 'Щ': 'X', 'Ъ': '\\',
+			'Ы': '|', 'Ь': '/', 'Э': '~', 'Ю': '`', 'Я': 'Y',
+		},
+		reserved: "\\/|~`>",
+	},
+	{
+		script: "Greek",
+		table:  unicode.Greek,
+		font:   "ntgreek",
+		glyphs: map[rune]rune{
+			'Α': 'A', 'Β': 'B', 'Γ': 'G', 'Δ': 'D', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H',
+			'Θ': 'Q', 'Ι': 'I', 'Κ': 'K', 'Λ': 'L', 'Μ': 'M', 'Ν': 'N', 'Ξ': 'X',
+			'Ο': 'O', 'Π': 'P', 'Ρ': 'R', 'Σ': 'S', 'Τ': 'T', 'Υ': 'U', 'Φ': 'F',
+			'Χ': 'C', 'Ψ': 'Y', 'Ω': 'W',
+			'α': 'a', 'β': 'b', 'γ': 'g', 'δ': 'd', 'ε': 'e', 'ζ': 'z', 'η': 'h',
+			'θ': 'q', 'ι': 'i', 'κ': 'k', 'λ': 'l', 'μ': 'm', 'ν': 'n', 'ξ': 'x',
+			'ο': 'o', 'π': 'p', 'ρ': 'r', 'σ': 's', 'ς': 'V', 'τ': 't', 'υ': 'u',
+			'φ': 'f', 'χ': 'c', 'ψ': 'y', 'ω': 'w', 'ϑ': 'J', 'ϕ': 'j',
+			'Ά': 'A', 'Έ': 'E', 'Ή': 'H', 'Ί': 'I', 'Ό': 'O', 'Ύ': 'U', 'Ώ': 'W',
+			'ά': 'a', 'έ': 'e', 'ή': 'h', 'ί': 'i', 'ό': 'o', 'ύ': 'u', 'ώ': 'w',
+			'ϊ': 'i', 'ϋ': 'u', 'ΐ': 'i', 'ΰ': 'u',
+		},
+		reserved: "()'`^:_",
+	},
+}
+
+// detectScriptFont returns the script font for the dominant non-Latin
+// script in text, or nil when the text is Latin.
+func detectScriptFont(text string) *scriptFont {
+	counts := make([]int, len(scriptFonts))
+	for _, r := range text {
+		for i, sf := range scriptFonts {
+			if unicode.Is(sf.table, r) {
+				counts[i]++
+			}
+		}
+	}
+
+	best := -1
+	for i, count := range counts {
+		if count > 0 && (best < 0 || count > counts[best]) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	return &scriptFonts[best]
+}
+
+// translate maps text onto the font's ASCII slots. Runes the font cannot
+// draw are replaced with '?' and returned as missing.
+func (sf *scriptFont) translate(text string) (string, []rune) {
+	var missing []rune
+	mapped := strings.Map(func(r rune) rune {
+		if slot, ok := sf.glyphs[r]; ok {
+			return slot
+		}
+		if slot, ok := sf.glyphs[unicode.ToUpper(r)]; ok {
+			return slot
+		}
+		if r == '\n' || r == ' ' || (r < unicode.MaxASCII && !unicode.IsLetter(r) && !strings.ContainsRune(sf.reserved, r)) {
+			return r
+		}
+		missing = append(missing, r)
+		return '?'
+	}, text)
+	return mapped, missing
+}
+
+// prepareFigureText picks the font that covers the text best and returns
+// the text ready for it, along with any runes a script font cannot draw.
+// Gaps in other fonts are found from their glyphs.
+func prepareFigureText(text, font string) (string, string, []rune) {
+	if sf := detectScriptFont(text); sf != nil {
+		mapped, missing := sf.translate(text)
+		return mapped, sf.font, missing
+	}
+	return text, font, nil
+}
+
+// reportCoverageGaps warns once per character the chosen font cannot draw.
+func (r *Renderer) reportCoverageGaps(font string, missing []rune) {
+	seen := make(map[rune]bool)
+	for _, m := range missing {
+		if seen[m] {
+			continue
+		}
+		seen[m] = true
+		r.warnf("font %q has no glyph for %q (U+%04X)", font, m, m)
+	}
+}
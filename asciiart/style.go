@@ -0,0 +1,237 @@
+package asciiart
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// StyleCategory represents a category of text styles
+type StyleCategory struct {
+	Name        string
+	Description string
+	Styles      []Style
+}
+
+// Style represents an ASCII art style configuration
+type Style struct {
+	Name        string
+	Description string
+	Font        string    // go-figure or FIGlet font name; empty for plain text
+	Decorator   Decorator // Optional decorator for additional styling
+}
+
+// Decorator provides additional styling to the ASCII art
+type Decorator struct {
+	Top     string
+	Bottom  string
+	Left    string
+	Right   string
+	Corners [4]string // TL, TR, BL, BR
+	Fill    string
+	Border  Border              // Custom frame; replaces the pieces above when set
+	Pre     func(string) string // Pre-processing function
+	Post    func(string) string // Post-processing function
+}
+
+// ColorScheme represents a color configuration
+type ColorScheme struct {
+	Name       string
+	Primary    *color.Color
+	Secondary  *color.Color
+	Background *color.Color
+}
+
+// Predefined decorators
+var (
+	BoxDecorator = Decorator{
+		Top:     "─",
+		Bottom:  "─",
+		Left:    "│",
+		Right:   "│",
+		Corners: [4]string{"┌", "┐", "└", "┘"},
+	}
+
+	DoubleBoxDecorator = Decorator{
+		Top:     "═",
+		Bottom:  "═",
+		Left:    "║",
+		Right:   "║",
+		Corners: [4]string{"╔", "╗", "╚", "╝"},
+	}
+
+	RoundBoxDecorator = Decorator{
+		Top:     "─",
+		Bottom:  "─",
+		Left:    "│",
+		Right:   "│",
+		Corners: [4]string{"╭", "╮", "╰", "╯"},
+	}
+
+	DottedBoxDecorator = Decorator{
+		Top:     "┈",
+		Bottom:  "┈",
+		Left:    "┊",
+		Right:   "┊",
+		Corners: [4]string{"·", "·", "·", "·"},
+	}
+
+	Stars3DDecorator = Decorator{
+		Top:     "★",
+		Bottom:  "★",
+		Left:    "★",
+		Right:   "★",
+		Corners: [4]string{"★", "★", "★", "★"},
+		Pre: func(s string) string {
+			return AddShadow(s)
+		},
+	}
+
+	WavyDecorator = Decorator{
+		Top:     "～",
+		Bottom:  "～",
+		Left:    "※",
+		Right:   "※",
+		Corners: [4]string{"∿", "∿", "∿", "∿"},
+	}
+)
+
+// Forced returns a copy of cs whose colors ignore color.NoColor, for
+// output that must carry escape codes even when stdout is not a
+// terminal. The shared scheme is left untouched, so Forced is safe to
+// call from several goroutines.
+func (cs ColorScheme) Forced() *ColorScheme {
+	force := func(c *color.Color) *color.Color {
+		clone := *c
+		clone.EnableColor()
+		return &clone
+	}
+	cs.Primary, cs.Secondary, cs.Background = force(cs.Primary), force(cs.Secondary), force(cs.Background)
+	return &cs
+}
+
+// DefaultCategories returns the built-in style catalog
+func DefaultCategories() []StyleCategory {
+	return []StyleCategory{
+		{
+			Name:        "Classic",
+			Description: "Traditional ASCII art styles",
+			Styles: []Style{
+				{"Standard", "Classic ASCII art", "", Decorator{}},
+				{"Big", "Large block letters", "big", Decorator{}},
+				{"Slim", "Thin elegant letters", "slim", Decorator{}},
+				{"Small", "Compact letters", "small", Decorator{}},
+			},
+		},
+		{
+			Name:        "Boxed",
+			Description: "Styles with different types of borders",
+			Styles: []Style{
+				{"Single Box", "Single-line border", "", BoxDecorator},
+				{"Double Box", "Double-line border", "", DoubleBoxDecorator},
+				{"Round Box", "Rounded corners", "", RoundBoxDecorator},
+				{"Dotted Box", "Dotted border style", "", DottedBoxDecorator},
+			},
+		},
+		{
+			Name:        "3D Effects",
+			Description: "Three-dimensional looking styles",
+			Styles: []Style{
+				{"Shadow", "Letters with shadow", "shadow", Decorator{}},
+				{"Deep 3D", "Enhanced 3D effect", "standard", Stars3DDecorator},
+				{"Block 3D", "Solid 3D blocks", "block", Decorator{
+					Pre: func(s string) string { return AddShadow(s) },
+				}},
+			},
+		},
+		{
+			Name:        "Decorative",
+			Description: "Fancy and ornamental styles",
+			Styles: []Style{
+				{"Wavy", "Wavy border style", "", WavyDecorator},
+				{"Stars", "Starred border", "", Stars3DDecorator},
+				{"Script", "Cursive style", "script", Decorator{}},
+				{"Bubble", "Rounded bubble letters", "bubble", RoundBoxDecorator},
+			},
+		},
+		{
+			Name:        "Numeric",
+			Description: "Fonts suited to counters and dashboards",
+			Styles: []Style{
+				{"Seven Segment", "LCD-style digits", "lcd", Decorator{}},
+				{"Block Digits", "Compact 3x5 block digits", "3x5", Decorator{}},
+				{"Digital", "Boxed digital readout", "digital", Decorator{}},
+			},
+		},
+	}
+}
+
+// DefaultColorSchemes returns the built-in color schemes
+func DefaultColorSchemes() []ColorScheme {
+	return []ColorScheme{
+		{
+			"Ocean",
+			color.New(color.FgBlue),
+			color.New(color.FgCyan),
+			color.New(color.FgHiBlue),
+		},
+		{
+			"Forest",
+			color.New(color.FgGreen),
+			color.New(color.FgHiGreen),
+			color.New(color.FgWhite),
+		},
+		{
+			"Sunset",
+			color.New(color.FgRed),
+			color.New(color.FgYellow),
+			color.New(color.FgHiRed),
+		},
+		{
+			"Royal",
+			color.New(color.FgMagenta),
+			color.New(color.FgHiMagenta),
+			color.New(color.FgWhite),
+		},
+		{
+			"Monochrome",
+			color.New(color.FgWhite),
+			color.New(color.FgHiWhite),
+			color.New(color.FgBlack),
+		},
+		{
+			"Neon",
+			color.New(color.FgHiGreen),
+			color.New(color.FgHiYellow),
+			color.New(color.FgHiCyan),
+		},
+		{
+			"Rainbow",
+			color.New(color.FgRed),
+			color.New(color.FgGreen),
+			color.New(color.FgBlue),
+		},
+	}
+}
+
+// AddShadow adds a light shade shadow line under every line of text
+func AddShadow(text string) string {
+	lines := strings.Split(text, "\n")
+	result := make([]string, len(lines))
+
+	for i, line := range lines {
+		if i < len(lines)-1 {
+			shadowLine := strings.Map(func(r rune) rune {
+				if r != ' ' {
+					return '░'
+				}
+				return ' '
+			}, line)
+			result[i] = line + "\n" + strings.Repeat(" ", 2) + shadowLine
+		} else {
+			result[i] = line
+		}
+	}
+
+	return strings.Join(result, "\n")
+}
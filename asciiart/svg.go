@@ -0,0 +1,87 @@
+package asciiart
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// SVG layout, in pixels. Monospace fonts are about 0.6em wide.
+const (
+	svgFontSize   = 14
+	svgCellWidth  = 8.4
+	svgLineHeight = 17
+	svgPadding    = 8
+)
+
+// SVG draws art as an SVG image of monospace text on a dark background,
+// for crisp banners in READMEs and docs. Colors from c, if not nil,
+// become fill colors. Every run is placed at its column, so borders line
+// up whatever font the viewer picks.
+func SVG(text string, c Colorizer) string {
+	if c == nil {
+		c = ColorizerFunc(func(Cell) *color.Color { return nil })
+	}
+	lines := colorRuns(text, c)
+	width := 0
+	for _, line := range strings.Split(text, "\n") {
+		width = max(width, DisplayWidth(line))
+	}
+
+	var b strings.Builder
+	w := float64(width)*svgCellWidth + 2*svgPadding
+	h := len(lines)*svgLineHeight + 2*svgPadding
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%d" viewBox="0 0 %g %d">`+"\n", w, h, w, h)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="#1e1e1e"/>`+"\n")
+	fmt.Fprintf(&b, `<g font-family="Menlo, Consolas, 'DejaVu Sans Mono', monospace" font-size="%d" fill="#e5e5e5" xml:space="preserve">`+"\n", svgFontSize)
+
+	for row, runs := range lines {
+		if len(runs) == 0 {
+			continue
+		}
+		y := svgPadding + row*svgLineHeight + svgFontSize
+		fmt.Fprintf(&b, `<text y="%d">`, y)
+		col := 0
+		for _, run := range runs {
+			fill := ""
+			if run.color != nil {
+				if rgb, ok := ColorRGB(run.color); ok {
+					fill = fmt.Sprintf(` fill="#%02x%02x%02x"`, rgb.R, rgb.G, rgb.B)
+				}
+			}
+			// Wide characters get a tspan of their own, since fonts rarely
+			// draw them exactly two cells wide
+			for _, piece := range splitWide(run.text) {
+				x := svgPadding + float64(col)*svgCellWidth
+				fmt.Fprintf(&b, `<tspan x="%g"%s>%s</tspan>`, x, fill, html.EscapeString(piece))
+				col += DisplayWidth(piece)
+			}
+		}
+		b.WriteString("</text>\n")
+	}
+	b.WriteString("</g>\n</svg>")
+	return b.String()
+}
+
+// splitWide cuts s before and after every wide grapheme cluster
+func splitWide(s string) []string {
+	var pieces []string
+	start := 0
+	for i := 0; i < len(s); {
+		cluster, width := NextGrapheme(s[i:])
+		if width == 2 {
+			if i > start {
+				pieces = append(pieces, s[start:i])
+			}
+			pieces = append(pieces, cluster)
+			start = i + len(cluster)
+		}
+		i += len(cluster)
+	}
+	if start < len(s) {
+		pieces = append(pieces, s[start:])
+	}
+	return pieces
+}
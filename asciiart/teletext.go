@@ -0,0 +1,125 @@
+package asciiart
+
+import (
+	"image"
+	"strings"
+)
+
+// TeletextWidth is the number of character cells across a teletext or
+// Minitel page
+const TeletextWidth = 40
+
+// TeletextPalette holds the eight colors of teletext, at full intensity,
+// in ANSI order
+var TeletextPalette = [8]RGB{
+	{0, 0, 0}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{0, 0, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// Teletext mosaics: the 2x3 block sextants of Unicode's legacy computing
+// symbols, plus the four patterns older blocks already cover
+const (
+	sextantBase  = 0x1fb00
+	sextantLeft  = 0b010101 // Left column, ▌
+	sextantRight = 0b101010 // Right column, ▐
+	sextantFull  = 0b111111
+)
+
+// sextant returns the mosaic character for bits, one per cell of the 2x3
+// grid from the top left, row by row
+func sextant(bits int) string {
+	switch bits {
+	case 0:
+		return " "
+	case sextantLeft:
+		return "▌"
+	case sextantRight:
+		return "▐"
+	case sextantFull:
+		return fullBlock
+	}
+	// The sextants are in order of their bits, skipping the columns
+	index := bits - 1
+	if bits > sextantLeft {
+		index--
+	}
+	if bits > sextantRight {
+		index--
+	}
+	return string(rune(sextantBase + index))
+}
+
+// nearestTeletext returns the index of the teletext color closest to c;
+// pixels without a color of their own are white
+func nearestTeletext(c *RGB) int {
+	if c == nil {
+		return 7
+	}
+	best := 0
+	for i, v := range TeletextPalette {
+		if c.distance(v) < c.distance(TeletextPalette[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// Teletext draws r the way a teletext page would, with 2x3 mosaics in the
+// eight teletext colors, one color to a character: the one most of its
+// inked pixels are nearest to. Pixels nearest to black are left out, as
+// the page's background. Colors are written for a terminal showing depth
+// colors; a depth of 0 draws the shapes alone.
+func (r Raster) Teletext(depth int) string {
+	var lines []string
+	for y := 0; y < len(r); y += 3 {
+		var b strings.Builder
+		current := ""
+		for x := 0; x < len(r[y]); x += 2 {
+			var votes [8]int
+			bits := 0
+			for dy := range 3 {
+				for dx := range 2 {
+					if y+dy >= len(r) || x+dx >= len(r[y+dy]) || !r[y+dy][x+dx].Ink {
+						continue
+					}
+					if index := nearestTeletext(r[y+dy][x+dx].Color); index > 0 {
+						votes[index]++
+						bits |= 1 << (dy*2 + dx)
+					}
+				}
+			}
+			chosen := 7
+			for i := range votes {
+				if votes[i] > votes[chosen] {
+					chosen = i
+				}
+			}
+
+			if sgr := colorSGR(&TeletextPalette[chosen], depth, false); bits != 0 && sgr != current {
+				if current != "" {
+					b.WriteString("\x1b[0m")
+				}
+				b.WriteString(sgr)
+				current = sgr
+			}
+			b.WriteString(sextant(bits))
+		}
+		if current != "" {
+			b.WriteString("\x1b[0m")
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RasterizeMosaic is RasterizeImage for Teletext, with 2x3 pixels to a
+// character cell. opts.Width and opts.Height still count cells.
+func RasterizeMosaic(img image.Image, opts ImageOptions) Raster {
+	if opts.CellAspect <= 0 {
+		opts.CellAspect = DefaultCellAspect
+	}
+	opts.CellAspect *= 1.5
+	opts.Width *= 2
+	opts.Height *= 3
+	return rasterizeImage(img, opts)
+}
@@ -0,0 +1,169 @@
+package asciiart
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Color depths, as the number of colors a terminal can show
+const (
+	Depth16   = 16
+	Depth256  = 256
+	DepthTrue = 1 << 24
+)
+
+// The xterm 256-color cube levels
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// basicColors are the usual RGB values of the 16 ANSI colors
+var basicColors = []struct {
+	attr    color.Attribute
+	r, g, b int
+}{
+	{color.FgBlack, 0, 0, 0}, {color.FgRed, 205, 0, 0},
+	{color.FgGreen, 0, 205, 0}, {color.FgYellow, 205, 205, 0},
+	{color.FgBlue, 0, 0, 238}, {color.FgMagenta, 205, 0, 205},
+	{color.FgCyan, 0, 205, 205}, {color.FgWhite, 229, 229, 229},
+	{color.FgHiBlack, 127, 127, 127}, {color.FgHiRed, 255, 0, 0},
+	{color.FgHiGreen, 0, 255, 0}, {color.FgHiYellow, 255, 255, 0},
+	{color.FgHiBlue, 92, 92, 255}, {color.FgHiMagenta, 255, 0, 255},
+	{color.FgHiCyan, 0, 255, 255}, {color.FgHiWhite, 255, 255, 255},
+}
+
+// RGB is a 24-bit color
+type RGB struct {
+	R, G, B int
+}
+
+// ParseHex parses "#ff6600", "ff6600" or the short form "#f60"
+func ParseHex(s string) (RGB, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return RGB{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	return RGB{int(n >> 16), int(n >> 8 & 0xff), int(n & 0xff)}, nil
+}
+
+// Color returns a foreground color for c, downgraded to the nearest
+// 256-color or basic ANSI color when the terminal shows fewer than
+// DepthTrue colors
+func (c RGB) Color(depth int) *color.Color {
+	switch {
+	case depth >= DepthTrue:
+		return color.RGB(c.R, c.G, c.B)
+	case depth >= Depth256:
+		return color.New(38, 5, color.Attribute(c.index256()))
+	}
+	return color.New(c.nearestBasic())
+}
+
+// index256 returns the closest entry of the xterm 256-color palette,
+// from either the color cube or the grayscale ramp
+func (c RGB) index256() int {
+	level := func(v int) int {
+		best := 0
+		for i, l := range cubeLevels {
+			if abs(v-l) < abs(v-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	r, g, b := level(c.R), level(c.G), level(c.B)
+	cube := RGB{cubeLevels[r], cubeLevels[g], cubeLevels[b]}
+
+	gray := min(23, max(0, ((c.R+c.G+c.B)/3-8+5)/10))
+	grayValue := 8 + gray*10
+	if c.distance(RGB{grayValue, grayValue, grayValue}) < c.distance(cube) {
+		return 232 + gray
+	}
+	return 16 + 36*r + 6*g + b
+}
+
+func (c RGB) nearestBasic() color.Attribute {
+	best := basicColors[0]
+	for _, basic := range basicColors[1:] {
+		if c.distance(RGB{basic.r, basic.g, basic.b}) < c.distance(RGB{best.r, best.g, best.b}) {
+			best = basic
+		}
+	}
+	return best.attr
+}
+
+func (c RGB) distance(o RGB) int {
+	dr, dg, db := c.R-o.R, c.G-o.G, c.B-o.B
+	return dr*dr + dg*dg + db*db
+}
+
+// ColorRGB returns the RGB value a foreground color is usually shown
+// in, for drawing art outside a terminal. It reports false for colors
+// without a foreground, such as plain bold.
+func ColorRGB(c *color.Color) (RGB, bool) {
+	clone := *c
+	clone.EnableColor()
+	sgr, _, _ := strings.Cut(strings.TrimPrefix(clone.Sprint(""), "\x1b["), "m")
+
+	var params []int
+	for _, field := range strings.Split(sgr, ";") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return RGB{}, false
+		}
+		params = append(params, n)
+	}
+
+	var rgb RGB
+	found := false
+	for i := 0; i < len(params); i++ {
+		switch p := params[i]; {
+		case p == 38 && i+4 < len(params) && params[i+1] == 2:
+			rgb, found = RGB{params[i+2], params[i+3], params[i+4]}, true
+			i += 4
+		case p == 38 && i+2 < len(params) && params[i+1] == 5:
+			rgb, found = palette256(params[i+2]), true
+			i += 2
+		case p >= 30 && p <= 37:
+			rgb, found = basicRGB(p-30), true
+		case p >= 90 && p <= 97:
+			rgb, found = basicRGB(p-90+8), true
+		}
+	}
+	return rgb, found
+}
+
+// palette256 returns the RGB value of an xterm 256-color palette entry
+func palette256(n int) RGB {
+	switch {
+	case n < 16:
+		return basicRGB(n)
+	case n < 232:
+		n -= 16
+		return RGB{cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6]}
+	}
+	gray := 8 + (n-232)*10
+	return RGB{gray, gray, gray}
+}
+
+func basicRGB(n int) RGB {
+	b := basicColors[min(max(n, 0), len(basicColors)-1)]
+	return RGB{b.r, b.g, b.b}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// SolidScheme is a color scheme that draws every line in one color
+func SolidScheme(name string, c *color.Color) ColorScheme {
+	return ColorScheme{Name: name, Primary: c, Secondary: c, Background: c}
+}
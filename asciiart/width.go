@@ -0,0 +1,116 @@
+package asciiart
+
+import (
+	"strings"
+	"unicode"
+)
+
+// wideRanges are East Asian Wide/Fullwidth blocks and emoji that take two
+// terminal cells, after the W and F classes of Unicode's EastAsianWidth.txt
+// and its Emoji_Presentation characters. width_test.go pins the cases
+// borders depend on; update both when Unicode adds wide characters.
+var wideRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x1100, 0x115F, 1}, // Hangul Jamo initials
+		{0x231A, 0x231B, 1}, // Watch, hourglass
+		{0x23E9, 0x23EC, 1},
+		{0x23F0, 0x23F0, 1},
+		{0x23F3, 0x23F3, 1},
+		{0x25FD, 0x25FE, 1},
+		{0x2614, 0x2615, 1},
+		{0x2648, 0x2653, 1}, // Zodiac
+		{0x267F, 0x267F, 1},
+		{0x2693, 0x2693, 1},
+		{0x26A1, 0x26A1, 1},
+		{0x26AA, 0x26AB, 1},
+		{0x26BD, 0x26BE, 1},
+		{0x26C4, 0x26C5, 1},
+		{0x26CE, 0x26CE, 1},
+		{0x26D4, 0x26D4, 1},
+		{0x26EA, 0x26EA, 1},
+		{0x26F2, 0x26F3, 1},
+		{0x26F5, 0x26F5, 1},
+		{0x26FA, 0x26FA, 1},
+		{0x26FD, 0x26FD, 1},
+		{0x2705, 0x2705, 1},
+		{0x270A, 0x270B, 1},
+		{0x2728, 0x2728, 1},
+		{0x274C, 0x274C, 1},
+		{0x274E, 0x274E, 1},
+		{0x2753, 0x2755, 1},
+		{0x2757, 0x2757, 1},
+		{0x2795, 0x2797, 1},
+		{0x27B0, 0x27B0, 1},
+		{0x27BF, 0x27BF, 1},
+		{0x2B1B, 0x2B1C, 1},
+		{0x2B50, 0x2B50, 1},
+		{0x2B55, 0x2B55, 1},
+		{0x2E80, 0x303E, 1}, // CJK radicals, punctuation
+		{0x3041, 0x33FF, 1}, // Kana, CJK compatibility
+		{0x3400, 0x4DBF, 1}, // CJK extension A
+		{0x4E00, 0x9FFF, 1}, // CJK unified ideographs
+		{0xA000, 0xA4CF, 1}, // Yi
+		{0xAC00, 0xD7A3, 1}, // Hangul syllables
+		{0xF900, 0xFAFF, 1}, // CJK compatibility ideographs
+		{0xFE30, 0xFE4F, 1}, // CJK compatibility forms
+		{0xFF00, 0xFF60, 1}, // Fullwidth forms
+		{0xFFE0, 0xFFE6, 1},
+	},
+	R32: []unicode.Range32{
+		{0x1F004, 0x1F004, 1},
+		{0x1F0CF, 0x1F0CF, 1},
+		{0x1F18E, 0x1F18E, 1},
+		{0x1F191, 0x1F19A, 1},
+		{0x1F200, 0x1F251, 1},
+		{0x1F300, 0x1F64F, 1}, // Pictographs, emoticons
+		{0x1F680, 0x1F6FF, 1}, // Transport and map
+		{0x1F7E0, 0x1F7EB, 1},
+		{0x1F90C, 0x1F9FF, 1}, // Supplemental symbols and pictographs
+		{0x1FA70, 0x1FAFF, 1},
+		{0x20000, 0x2FFFD, 1}, // CJK extensions B-F
+		{0x30000, 0x3FFFD, 1},
+	},
+}
+
+// runeWidth returns the number of terminal cells r occupies
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || r == '\u200d' || (r >= '\ufe00' && r <= '\ufe0f'):
+		return 0 // NUL, zero width joiner, variation selectors
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0 // Combining marks and format characters
+	case unicode.Is(wideRanges, r):
+		return 2
+	}
+	return 1
+}
+
+// DisplayWidth returns the number of terminal cells s occupies, counting
+// each grapheme cluster once
+func DisplayWidth(s string) int {
+	width := 0
+	for s != "" {
+		cluster, w := NextGrapheme(s)
+		width += w
+		s = s[len(cluster):]
+	}
+	return width
+}
+
+// PadToWidth pads s on the right with spaces to width cells
+func PadToWidth(s string, width int) string {
+	if w := DisplayWidth(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// FillWidth repeats pattern to cover exactly width cells, topping up
+// with spaces when the pattern's cell width does not divide evenly.
+func FillWidth(pattern string, width int) string {
+	pw := DisplayWidth(pattern)
+	if pattern == "" || pw == 0 {
+		return strings.Repeat(" ", width)
+	}
+	return strings.Repeat(pattern, width/pw) + strings.Repeat(" ", width%pw)
+}
@@ -0,0 +1,94 @@
+package asciiart
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		name, s string
+		want    int
+	}{
+		{"empty", "", 0},
+		{"ASCII", "Hello", 5},
+		{"box drawing", "┌─┐", 3},
+		{"CJK", "日本語", 6},
+		{"hangul syllables", "한국", 4},
+		{"hangul jamo", "\u1100\u1161\u11a8", 2},
+		{"kana and ASCII", "カナa", 5},
+		{"fullwidth", "ＡＢ", 4},
+		{"halfwidth katakana", "ｶﾅ", 2},
+		{"emoji", "🎉", 2},
+		{"emoji with skin tone", "👍🏽", 2},
+		{"ZWJ family", "👨\u200d👩\u200d👧\u200d👦", 2},
+		{"ZWJ profession", "👩\u200d💻!", 3},
+		{"flag", "🇯🇵", 2},
+		{"two flags", "🇯🇵🇫🇷", 4},
+		{"lone regional indicator", "🇯", 1},
+		{"VS16 heart", "❤\ufe0f", 2},
+		{"text heart", "❤", 1},
+		{"VS15", "☺\ufe0e", 1},
+		{"combining acute", "e\u0301", 1},
+		{"stacked marks", "a\u0301\u0323\u0308", 1},
+		{"precomposed", "é", 1},
+		{"devanagari spacing mark", "कि", 2},
+		{"zero width space", "a\u200bb", 2},
+		{"tab", "\t", 1},
+	}
+	for _, test := range tests {
+		if got := DisplayWidth(test.s); got != test.want {
+			t.Errorf("%s: DisplayWidth(%q) = %d, want %d", test.name, test.s, got, test.want)
+		}
+	}
+}
+
+func TestNextGrapheme(t *testing.T) {
+	tests := []struct {
+		name, s string
+		want    []string
+	}{
+		{"ASCII", "ab", []string{"a", "b"}},
+		{"combining marks", "e\u0301x\u0323\u0308", []string{"e\u0301", "x\u0323\u0308"}},
+		{"ZWJ family", "👨\u200d👩\u200d👧a", []string{"👨\u200d👩\u200d👧", "a"}},
+		{"skin tone", "👍🏽👍", []string{"👍🏽", "👍"}},
+		{"flags", "🇯🇵🇫🇷🇩", []string{"🇯🇵", "🇫🇷", "🇩"}},
+		{"VS16", "❤\ufe0f❤", []string{"❤\ufe0f", "❤"}},
+		{"hangul jamo", "\u1100\u1161\u11a8\u1100", []string{"\u1100\u1161\u11a8", "\u1100"}},
+		{"CRLF stays apart", "\r\n", []string{"\r", "\n"}},
+		{"joiner at the end", "a\u200d", []string{"a\u200d"}},
+		{"joiner before newline", "👩\u200d\nx", []string{"👩\u200d", "\n", "x"}},
+	}
+	for _, test := range tests {
+		var got []string
+		for rest := test.s; rest != ""; {
+			cluster, _ := NextGrapheme(rest)
+			got = append(got, cluster)
+			rest = rest[len(cluster):]
+		}
+		if strings.Join(got, "|") != strings.Join(test.want, "|") {
+			t.Errorf("%s: clusters of %q = %q, want %q", test.name, test.s, got, test.want)
+		}
+	}
+}
+
+func TestApplyDecoratorAlignsWideText(t *testing.T) {
+	d := Decorator{Top: "─", Bottom: "─", Left: "│", Right: "│", Corners: [4]string{"┌", "┐", "└", "┘"}}
+	for _, text := range []string{
+		"日本語\nabc",
+		"👩\u200d💻 dev\nok",
+		"🇯🇵 e\u0301\n❤\ufe0f",
+		"한국어 텍스트\n\u1100\u1161\u11a8",
+	} {
+		lines := strings.Split(ApplyDecorator(text, d), "\n")
+		want := DisplayWidth(lines[0])
+		for i, line := range lines {
+			if got := DisplayWidth(line); got != want {
+				t.Errorf("border around %q: line %d is %d cells, want %d:\n%s", text, i, got, want, strings.Join(lines, "\n"))
+			}
+			if !strings.HasSuffix(line, "│") && !strings.HasSuffix(line, "┐") && !strings.HasSuffix(line, "┘") {
+				t.Errorf("border around %q: line %d does not end in the border: %q", text, i, line)
+			}
+		}
+	}
+}
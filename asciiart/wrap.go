@@ -0,0 +1,84 @@
+package asciiart
+
+import "strings"
+
+// Wrap breaks text into lines so that its art in style is at most width
+// cells wide, breaking between words where it can and inside words that
+// are too wide on their own. Existing line breaks are kept. Text that
+// cannot fit, such as a single glyph wider than width, is left as is.
+func (r *Renderer) Wrap(text string, style Style, width int) string {
+	if width <= 0 {
+		return text
+	}
+	// Trial renders must not repeat font warnings
+	quiet := *r
+	quiet.Warnings = nil
+	// Post effects can be slow, such as effect plugins that run a
+	// program, so trial renders leave them out and allow for the width
+	// they added to the first one instead
+	trial := style
+	trial.Decorator.Post = nil
+	extra := -1
+	fits := func(line string) bool {
+		art := quiet.Render(line, trial, nil)
+		if extra < 0 {
+			extra = 0
+			if style.Decorator.Post != nil {
+				extra = max(0, artWidth(style.Decorator.Post(art))-artWidth(art))
+			}
+		}
+		return artWidth(art)+extra <= width
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if fits(line) {
+			lines = append(lines, line)
+			continue
+		}
+		current := ""
+		for _, word := range strings.Fields(line) {
+			if current != "" && fits(current+" "+word) {
+				current += " " + word
+				continue
+			}
+			if current != "" {
+				lines = append(lines, current)
+			}
+			current = word
+			// Split a word that is too wide by itself
+			for !fits(current) {
+				head := splitToFit(current, fits)
+				if head == "" {
+					break
+				}
+				lines = append(lines, head)
+				current = current[len(head):]
+			}
+		}
+		lines = append(lines, current)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitToFit returns the longest prefix of word that fits, or "" if not
+// even the first character does
+func splitToFit(word string, fits func(string) bool) string {
+	best := ""
+	for i := range word {
+		if i > 0 && !fits(word[:i]) {
+			break
+		}
+		best = word[:i]
+	}
+	return best
+}
+
+// artWidth returns the widest line of art in terminal cells
+func artWidth(art string) int {
+	width := 0
+	for _, line := range strings.Split(art, "\n") {
+		width = max(width, DisplayWidth(line))
+	}
+	return width
+}
@@ -0,0 +1,21 @@
+package asciiart
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapRunsPostOnce(t *testing.T) {
+	calls := 0
+	style := Style{Decorator: Decorator{Post: func(art string) string {
+		calls++
+		return "  " + strings.ReplaceAll(art, "\n", "\n  ")
+	}}}
+	got := (&Renderer{}).Wrap("one two three four", style, 12)
+	if want := "one two\nthree four"; got != want {
+		t.Errorf("Wrap = %q, want %q", got, want)
+	}
+	if calls != 1 {
+		t.Errorf("Wrap ran the Post effect %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,168 @@
+package asciiart
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// XBinID starts every XBIN file
+const XBinID = "XBIN\x1a"
+
+// BinaryTextWidth is the width of binary text (.bin) without a SAUCE
+// record saying otherwise
+const BinaryTextWidth = 160
+
+// XBIN header flags
+const (
+	xbinPalette  = 1 << 0
+	xbinFont     = 1 << 1
+	xbinCompress = 1 << 2
+	xbinNonBlink = 1 << 3 // The attribute's top bit brightens the background
+	xbin512Chars = 1 << 4
+)
+
+// pcColors maps the colors of a PC text attribute, in the order of the
+// VGA's blue, green and red bits, to the ANSI order of a Canvas
+var pcColors = [8]int{0, 4, 2, 6, 1, 5, 3, 7}
+
+// ParseXBin reads an XBIN file, the binary art format of the ANSI scene:
+// a header, an optional palette and font, and the character and
+// attribute of every cell, optionally run-length compressed. A palette
+// becomes the canvas palette; fonts are skipped, so the art shows in
+// code page 437.
+func ParseXBin(data []byte) (*Canvas, *SAUCE, error) {
+	data, record := ParseSAUCE(data)
+	if !bytes.HasPrefix(data, []byte(XBinID)) || len(data) < 11 {
+		return nil, nil, errors.New("not an XBIN file")
+	}
+	width := int(binary.LittleEndian.Uint16(data[5:7]))
+	height := int(binary.LittleEndian.Uint16(data[7:9]))
+	fontSize, flags := int(data[9]), data[10]
+	data = data[11:]
+
+	c := &Canvas{Width: width}
+	if flags&xbinPalette != 0 {
+		if len(data) < 48 {
+			return nil, nil, errors.New("XBIN palette cut short")
+		}
+		c.Palette = make([]RGB, 16)
+		for i := range 16 {
+			// Six bits per channel, in PC color order
+			v := data[i*3 : i*3+3]
+			c.Palette[pcColors[i%8]+i&8] = RGB{scale6(v[0]), scale6(v[1]), scale6(v[2])}
+		}
+		data = data[48:]
+	}
+	if flags&xbinFont != 0 {
+		size := fontSize * 256
+		if flags&xbin512Chars != 0 {
+			size *= 2
+		}
+		if len(data) < size {
+			return nil, nil, errors.New("XBIN font cut short")
+		}
+		data = data[size:]
+	}
+
+	cells := data
+	if flags&xbinCompress != 0 {
+		var err error
+		if cells, err = decompressXBin(data, width*height); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(cells) < width*height*2 {
+		return nil, nil, fmt.Errorf("XBIN image cut short: %d of %d cells", len(cells)/2, width*height)
+	}
+	c.setBinaryText(cells[:width*height*2], flags&xbinNonBlink != 0, flags&xbin512Chars != 0)
+	return c, record, nil
+}
+
+// decompressXBin expands XBIN's run-length compression to n character and
+// attribute pairs. Each run starts with a byte whose top two bits say
+// whether the character, the attribute, both or neither repeat, and
+// whose low six bits give the run length less one.
+func decompressXBin(data []byte, n int) ([]byte, error) {
+	out := make([]byte, 0, n*2)
+	for len(out) < n*2 {
+		if len(data) == 0 {
+			return nil, errors.New("XBIN image cut short")
+		}
+		kind, count := data[0]>>6, int(data[0]&0x3f)+1
+		data = data[1:]
+		need := 2
+		switch kind {
+		case 0:
+			need = count * 2
+		case 1, 2:
+			need = 1 + count
+		}
+		if len(data) < need {
+			return nil, errors.New("XBIN image cut short")
+		}
+		for i := range count {
+			switch kind {
+			case 0:
+				out = append(out, data[i*2], data[i*2+1])
+			case 1: // Repeated character
+				out = append(out, data[0], data[1+i])
+			case 2: // Repeated attribute
+				out = append(out, data[1+i], data[0])
+			case 3:
+				out = append(out, data[0], data[1])
+			}
+		}
+		data = data[need:]
+	}
+	return out[:n*2], nil
+}
+
+// ParseBinaryText reads binary text (.bin): the character and attribute
+// of every cell, as in the PC's video memory, 160 columns wide unless a
+// SAUCE record gives the width. The attribute's top bit brightens the
+// background if the record asks for iCE colors, and is dropped otherwise,
+// since blinking cannot be shown.
+func ParseBinaryText(data []byte) (*Canvas, *SAUCE) {
+	data, record := ParseSAUCE(data)
+	width, ice := BinaryTextWidth, false
+	if record != nil && record.DataType == SAUCEBinaryText {
+		if record.FileType > 0 {
+			width = int(record.FileType) * 2
+		}
+		ice = record.Flags&SAUCEiCEColors != 0
+	}
+	c := &Canvas{Width: width}
+	c.setBinaryText(data[:len(data)/2*2], ice, false)
+	return c, record
+}
+
+// setBinaryText fills the canvas with character and attribute pairs, a
+// row of c.Width at a time. With chars512 the attribute's bright bit
+// selects the second half of a 512 character font instead, which code
+// page 437 cannot show.
+func (c *Canvas) setBinaryText(cells []byte, ice, chars512 bool) {
+	if c.Width <= 0 {
+		return
+	}
+	for i := 0; i+1 < len(cells); i += 2 {
+		char, attr := cells[i], cells[i+1]
+		fg := pcColors[attr&7] + int(attr&8)
+		if chars512 {
+			fg = pcColors[attr&7]
+		}
+		bg := pcColors[attr>>4&7]
+		if ice {
+			bg += int(attr >> 4 & 8)
+		}
+		n := i / 2
+		c.set(n/c.Width, n%c.Width, CanvasCell{cp437[char], fg, bg})
+	}
+}
+
+// scale6 widens a six bit VGA palette channel to eight bits
+func scale6(v byte) int {
+	v &= 0x3f
+	return int(v<<2 | v>>4)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// Asset kinds, each a subdirectory of an asset directory
+const (
+	assetFonts   = "fonts"
+	assetThemes  = "themes"
+	assetCows    = "cows"
+	assetEffects = "effects"
+)
+
+// systemAssetDirs are shared by every user on the host so admins can
+// distribute org-standard themes, fonts and config. ASCIIART_SYSTEM_DIR
+// replaces them.
+func systemAssetDirs() []string {
+	if dir := os.Getenv("ASCIIART_SYSTEM_DIR"); dir != "" {
+		return filepath.SplitList(dir)
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("ProgramData"); dir != "" {
+			return []string{filepath.Join(dir, "asciiart")}
+		}
+		return nil
+	}
+	return []string{"/etc/asciiart", "/usr/share/asciiart"}
+}
+
+// assetDirs lists asset directories from highest to lowest priority:
+// the per-user directory first, then the system-wide ones.
+func assetDirs() []string {
+	var dirs []string
+	if dir, err := configDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	return append(dirs, systemAssetDirs()...)
+}
+
+// listAssets maps asset names to files of the given kind and extension.
+// A name found in several directories resolves to the highest priority one.
+func listAssets(kind, ext string) map[string]string {
+	assets := make(map[string]string)
+	dirs := assetDirs()
+	for i := len(dirs) - 1; i >= 0; i-- {
+		matches, _ := filepath.Glob(filepath.Join(dirs[i], kind, "*"+ext))
+		for _, match := range matches {
+			assets[strings.TrimSuffix(filepath.Base(match), ext)] = match
+		}
+	}
+	return assets
+}
+
+func findAsset(kind, name, ext string) (string, bool) {
+	for _, dir := range assetDirs() {
+		p := filepath.Join(dir, kind, name+ext)
+		if _, err := os.Stat(p); err == nil {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// fontDirs lists the fonts directory inside every asset directory
+func fontDirs() []string {
+	var dirs []string
+	for _, dir := range assetDirs() {
+		dirs = append(dirs, filepath.Join(dir, assetFonts))
+	}
+	return dirs
+}
+
+// renderer draws all art. Fonts in the asset directories take precedence
+// over go-figure's bundled fonts.
+var renderer = asciiart.NewRenderer(fontDirs()...)
+
+// ThemeFile is a color scheme distributed as themes/<name>.yaml
+type ThemeFile struct {
+	Name       string `yaml:"name"`
+	Primary    string `yaml:"primary"`
+	Secondary  string `yaml:"secondary"`
+	Background string `yaml:"background"`
+}
+
+var colorNames = map[string]color.Attribute{
+	"black": color.FgBlack, "red": color.FgRed, "green": color.FgGreen,
+	"yellow": color.FgYellow, "blue": color.FgBlue, "magenta": color.FgMagenta,
+	"cyan": color.FgCyan, "white": color.FgWhite,
+	"hiblack": color.FgHiBlack, "hired": color.FgHiRed, "higreen": color.FgHiGreen,
+	"hiyellow": color.FgHiYellow, "hiblue": color.FgHiBlue, "himagenta": color.FgHiMagenta,
+	"hicyan": color.FgHiCyan, "hiwhite": color.FgHiWhite,
+	"gray": color.FgHiBlack, "grey": color.FgHiBlack,
+}
+
+// parseColorName accepts color names such as "bright-blue" and hex RGB
+// colors such as "#ff6600", which are downgraded to what the terminal
+// can show
+func parseColorName(name string) (*color.Color, error) {
+	if strings.HasPrefix(name, "#") {
+		rgb, err := asciiart.ParseHex(name)
+		if err != nil {
+			return nil, err
+		}
+		return rgb.Color(detectColorDepth()), nil
+	}
+
+	key := strings.NewReplacer("-", "", "_", "", " ", "", "bright", "hi").Replace(strings.ToLower(name))
+	attr, ok := colorNames[key]
+	if !ok {
+		return nil, fmt.Errorf("unknown color %q", name)
+	}
+	return color.New(attr), nil
+}
+
+func (t ThemeFile) colorScheme() (asciiart.ColorScheme, error) {
+	scheme := asciiart.ColorScheme{Name: t.Name}
+	for _, c := range []struct {
+		value  string
+		target **color.Color
+	}{
+		{t.Primary, &scheme.Primary},
+		{t.Secondary, &scheme.Secondary},
+		{t.Background, &scheme.Background},
+	} {
+		parsed, err := parseColorName(c.value)
+		if err != nil {
+			return scheme, err
+		}
+		*c.target = parsed
+	}
+	return scheme, nil
+}
+
+// loadThemes adds the color schemes from every asset directory. A theme
+// with the same name as a built-in scheme replaces it.
+func (config *AppConfig) loadThemes() {
+	themes := listAssets(assetThemes, ".yaml")
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		scheme, err := loadTheme(themes[name], name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, color.YellowString("Warning: theme %s: %v", themes[name], err))
+			continue
+		}
+		config.addColorScheme(scheme)
+	}
+}
+
+func loadTheme(p, name string) (asciiart.ColorScheme, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return asciiart.ColorScheme{}, err
+	}
+	theme := ThemeFile{Name: name}
+	if err := yaml.Unmarshal(data, &theme); err != nil {
+		return asciiart.ColorScheme{}, err
+	}
+	return theme.colorScheme()
+}
+
+func (config *AppConfig) addColorScheme(scheme asciiart.ColorScheme) {
+	for i := range config.colors {
+		if strings.EqualFold(config.colors[i].Name, scheme.Name) {
+			config.colors[i] = scheme
+			return
+		}
+	}
+	config.colors = append(config.colors, scheme)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	auditMaxSizeMB = 10 // Default size a log grows to before rotating
+	auditBackups   = 5  // Rotated logs kept as audit.log.1 ... audit.log.5
+)
+
+// AuditEntry is one JSON line of the audit log
+type AuditEntry struct {
+	Time        time.Time `json:"time"`
+	Client      string    `json:"client"`
+	TextHash    string    `json:"text_sha256"`
+	Text        string    `json:"text,omitempty"` // Left out when redacting
+	Style       string    `json:"style,omitempty"`
+	Font        string    `json:"font,omitempty"`
+	ColorScheme string    `json:"colorscheme,omitempty"`
+	Format      string    `json:"format,omitempty"`
+	Bytes       int       `json:"bytes"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// AuditLog records every served render to a file that rotates once it
+// reaches maxSize, for deployments that must keep a trail of requests
+type AuditLog struct {
+	path    string
+	maxSize int64
+	redact  bool
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// openAuditLog appends to the log at path, rotating at maxSizeMB. With
+// redact set only a hash of each text is kept. It returns nil when path
+// is empty.
+func openAuditLog(path string, maxSizeMB int, redact bool) (*AuditLog, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if maxSizeMB <= 0 {
+		return nil, fmt.Errorf("audit log size must be positive, got %d MB", maxSizeMB)
+	}
+	a := &AuditLog{path: path, maxSize: int64(maxSizeMB) << 20, redact: redact}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *AuditLog) open() error {
+	file, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("audit log: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("audit log: %v", err)
+	}
+	a.file, a.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts audit.log to audit.log.1, .1 to .2 and so on, dropping
+// the oldest, and starts a new file. If the rename fails, logging carries
+// on in the old file.
+func (a *AuditLog) rotate() error {
+	a.file.Close()
+	for i := auditBackups - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", a.path, i), fmt.Sprintf("%s.%d", a.path, i+1))
+	}
+	renameErr := os.Rename(a.path, a.path+".1")
+	if err := a.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return fmt.Errorf("audit log: %v", renameErr)
+	}
+	return nil
+}
+
+// record logs one answered request. A nil log records nothing.
+func (a *AuditLog) record(client string, request RenderRequest, response RenderResponse) {
+	if a == nil {
+		return
+	}
+
+	hash := sha256.Sum256([]byte(request.Text))
+	entry := AuditEntry{
+		Time:        time.Now().UTC(),
+		Client:      client,
+		TextHash:    hex.EncodeToString(hash[:]),
+		Style:       request.Style,
+		Font:        request.Font,
+		ColorScheme: request.ColorScheme,
+		Format:      request.Format,
+		Bytes:       len(response.Output),
+		Error:       response.Error,
+	}
+	if !a.redact {
+		entry.Text = request.Text
+	}
+	if response.Art != nil {
+		if data, err := json.Marshal(response.Art); err == nil {
+			entry.Bytes = len(data)
+		}
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.size > 0 && a.size+int64(len(line)) > a.maxSize {
+		if err := a.rotate(); err != nil {
+			fmt.Fprintln(os.Stderr, color.YellowString("Warning: %v", err))
+		}
+	}
+	n, err := a.file.Write(line)
+	a.size += int64(n)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, color.YellowString("Warning: audit log: %v", err))
+	}
+}
+
+// setAudit opens the audit log for the server modes, exiting on failure
+func (config *AppConfig) setAudit(path string, maxSizeMB int, redact bool) {
+	audit, err := openAuditLog(path, maxSizeMB, redact)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	config.audit = audit
+}
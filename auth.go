@@ -0,0 +1,172 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables read when the matching serve flag is not given,
+// which keeps secrets out of process listings and systemd units
+const (
+	apiKeysEnv   = "ASCIIART_API_KEYS"
+	basicAuthEnv = "ASCIIART_BASIC_AUTH"
+)
+
+// authRealm names the server in basic-auth prompts
+const authRealm = "ascii-art"
+
+// KeyUsage counts the requests made with one API key or basic-auth user
+type KeyUsage struct {
+	Requests int64     `json:"requests"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// ServerAuth checks API keys, from an "Authorization: Bearer" or
+// X-Api-Key header, and basic-auth credentials, counting the requests
+// each one makes
+type ServerAuth struct {
+	keys     map[string]string // Key to the name it is counted under
+	user     string
+	password string
+
+	mu    sync.Mutex
+	usage map[string]*KeyUsage
+}
+
+// newServerAuth parses keys, a comma-separated list of keys optionally
+// named as name=key, and basic, a user:password pair. Empty arguments
+// fall back to ASCIIART_API_KEYS and ASCIIART_BASIC_AUTH. It returns nil
+// when neither is set, leaving the server open.
+func newServerAuth(keys, basic string) (*ServerAuth, error) {
+	if keys == "" {
+		keys = os.Getenv(apiKeysEnv)
+	}
+	if basic == "" {
+		basic = os.Getenv(basicAuthEnv)
+	}
+	if keys == "" && basic == "" {
+		return nil, nil
+	}
+
+	a := &ServerAuth{keys: make(map[string]string), usage: make(map[string]*KeyUsage)}
+	for _, entry := range strings.Split(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, key, named := strings.Cut(entry, "=")
+		if !named {
+			name, key = hashedKeyName(entry), entry
+		}
+		if name == "" || key == "" {
+			return nil, fmt.Errorf("invalid API key %q (use key or name=key)", entry)
+		}
+		a.keys[key] = name
+	}
+	if basic != "" {
+		user, password, ok := strings.Cut(basic, ":")
+		if !ok || user == "" || password == "" {
+			return nil, fmt.Errorf("invalid basic auth (use user:password)")
+		}
+		a.user, a.password = user, password
+	}
+	return a, nil
+}
+
+// hashedKeyName is the name unnamed keys are counted under, so /usage
+// never shows a key
+func hashedKeyName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "key-" + hex.EncodeToString(sum[:4])
+}
+
+// identify returns the name r's credentials are counted under, or false
+// if it has none that are valid
+func (a *ServerAuth) identify(r *http.Request) (string, bool) {
+	key := r.Header.Get("X-Api-Key")
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		key = bearer
+	}
+	if key != "" {
+		// Compare against every key so the time taken does not say which
+		// one was nearly right
+		found := ""
+		for candidate, name := range a.keys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(candidate)) == 1 {
+				found = name
+			}
+		}
+		return found, found != ""
+	}
+	if user, password, ok := r.BasicAuth(); ok && a.user != "" {
+		userOK := subtle.ConstantTimeCompare([]byte(user), []byte(a.user))
+		passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(a.password))
+		return user, userOK&passwordOK == 1
+	}
+	return "", false
+}
+
+// wrap lets through only requests with valid credentials, counting them.
+// A nil ServerAuth lets everything through.
+func (a *ServerAuth) wrap(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := a.identify(r)
+		if !ok {
+			if a.user != "" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", authRealm))
+			} else {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q", authRealm))
+			}
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		a.count(name)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *ServerAuth) count(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	usage := a.usage[name]
+	if usage == nil {
+		usage = &KeyUsage{}
+		a.usage[name] = usage
+	}
+	usage.Requests++
+	usage.LastUsed = time.Now().UTC()
+}
+
+// counts returns a copy of the counters, by key name
+func (a *ServerAuth) counts() map[string]KeyUsage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	usage := make(map[string]KeyUsage, len(a.usage))
+	for name, u := range a.usage {
+		usage[name] = *u
+	}
+	return usage
+}
+
+// handleUsage returns the request counters of every key that has been
+// used since the server started
+func (a *ServerAuth) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", formatTypes["json"])
+	json.NewEncoder(w).Encode(a.counts())
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestServerAuth(t *testing.T) {
+	var current atomic.Pointer[AppConfig]
+	current.Store(newAppConfig())
+	auth, err := newServerAuth("ci=one, two", "admin:hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(serverMux(&current, auth))
+	defer server.Close()
+
+	basic := func(user, password string) http.Header {
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(user, password)
+		return req.Header
+	}
+	tests := []struct {
+		name   string
+		header http.Header
+		status int
+	}{
+		{"no credentials", http.Header{}, http.StatusUnauthorized},
+		{"named key", http.Header{"X-Api-Key": {"one"}}, http.StatusOK},
+		{"unnamed key as bearer", http.Header{"Authorization": {"Bearer two"}}, http.StatusOK},
+		{"unknown key", http.Header{"X-Api-Key": {"three"}}, http.StatusUnauthorized},
+		{"key prefix", http.Header{"X-Api-Key": {"on"}}, http.StatusUnauthorized},
+		{"basic auth", basic("admin", "hunter2"), http.StatusOK},
+		{"wrong password", basic("admin", "hunter3"), http.StatusUnauthorized},
+		{"wrong user", basic("root", "hunter2"), http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		status, _ := fetch(t, server, http.MethodGet, "/render?text=Hi", "", test.header)
+		if status != test.status {
+			t.Errorf("%s: status %d, want %d", test.name, status, test.status)
+		}
+	}
+
+	status, body := fetch(t, server, http.MethodGet, "/usage", "", http.Header{"X-Api-Key": {"one"}})
+	if status != http.StatusOK {
+		t.Fatalf("/usage: status %d", status)
+	}
+	var usage map[string]KeyUsage
+	if err := json.Unmarshal([]byte(body), &usage); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int64{"ci": 2, "admin": 1, hashedKeyName("two"): 1}
+	for name, requests := range want {
+		if usage[name].Requests != requests {
+			t.Errorf("usage[%q].Requests = %d, want %d", name, usage[name].Requests, requests)
+		}
+	}
+	if len(usage) != len(want) {
+		t.Errorf("usage counts %d names, want %d: %v", len(usage), len(want), usage)
+	}
+}
+
+func TestServerAuthOff(t *testing.T) {
+	t.Setenv(apiKeysEnv, "")
+	t.Setenv(basicAuthEnv, "")
+	auth, err := newServerAuth("", "")
+	if auth != nil || err != nil {
+		t.Errorf("newServerAuth with nothing set = %v, %v; want nil, nil", auth, err)
+	}
+
+	t.Setenv(basicAuthEnv, "admin")
+	if _, err := newServerAuth("", ""); err == nil {
+		t.Errorf("newServerAuth accepted basic auth without a password")
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+const defaultBackgroundColor = "gray"
+
+// maxBackgroundSize caps a -background file: a tile, not a picture
+const maxBackgroundSize = 16 * 1024
+
+// backgroundPatterns are the generated -background tiles, repeated
+// across the art from its top left corner
+var backgroundPatterns = map[string][]string{
+	"weave":   {"╱╲", "╲╱"},
+	"hatch":   {"╱"},
+	"dots":    {"·   ", "  · "},
+	"grid":    {"┼───", "│   "},
+	"checker": {"░ ", " ░"},
+	"shade":   {"░"},
+	"waves":   {"∿∿  ", "  ∿∿"},
+	"stars": {
+		"  .       *          .    ",
+		"      +        .          ",
+		".          .        *     ",
+		"     *         +       .  ",
+	},
+}
+
+// Background is a pattern layer under the art, from -background: it
+// shows through every blank cell, dimmed in its own color
+type Background struct {
+	Tile  [][]string // Rows of single-width cells
+	Color *color.Color
+}
+
+// newBackground reads a -background spec: pattern:NAME (or just the name)
+// for a generated pattern, or file:PATH for a tile from a text file
+func newBackground(spec, colorName string) (*Background, error) {
+	kind, value, ok := strings.Cut(spec, ":")
+	if !ok {
+		kind, value = "pattern", spec
+	}
+	var rows []string
+	switch kind {
+	case "pattern":
+		pattern, ok := backgroundPatterns[strings.ToLower(value)]
+		if !ok {
+			return nil, fmt.Errorf("unknown background pattern %q (use %s, or file:PATH)", value, strings.Join(backgroundNames(), ", "))
+		}
+		rows = pattern
+	case "file":
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxBackgroundSize {
+			return nil, fmt.Errorf("%s: a background tile can be up to %d KB", value, maxBackgroundSize/1024)
+		}
+		text := strings.TrimRight(strings.ReplaceAll(expandTabs(string(data), 8), "\r\n", "\n"), "\n")
+		rows = strings.Split(text, "\n")
+	default:
+		return nil, fmt.Errorf("unknown background %q (use pattern:NAME or file:PATH)", spec)
+	}
+
+	tile, err := backgroundTile(rows)
+	if err != nil {
+		return nil, fmt.Errorf("background %s: %v", value, err)
+	}
+	c, err := parseColorName(colorName)
+	if err != nil {
+		return nil, err
+	}
+	return &Background{Tile: tile, Color: c}, nil
+}
+
+// backgroundTile splits rows into cells, padding them to the widest so
+// the tile repeats evenly
+func backgroundTile(rows []string) ([][]string, error) {
+	width := blockWidth(rows)
+	if width == 0 {
+		return nil, errors.New("the pattern is empty")
+	}
+	var tile [][]string
+	for _, row := range rows {
+		var cells []string
+		for row != "" {
+			cluster, w := asciiart.NextGrapheme(row)
+			if w > 1 {
+				return nil, fmt.Errorf("%q is wider than one cell", cluster)
+			}
+			if w == 1 {
+				cells = append(cells, cluster)
+			}
+			row = row[len(cluster):]
+		}
+		for len(cells) < width {
+			cells = append(cells, " ")
+		}
+		tile = append(tile, cells)
+	}
+	return tile, nil
+}
+
+func backgroundNames() []string {
+	var names []string
+	for name := range backgroundPatterns {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// withBackground returns style with the background composited under the
+// art once the effects and any clipart are in place
+func withBackground(style asciiart.Style, bg *Background) asciiart.Style {
+	previous := style.Decorator.Post
+	style.Decorator.Post = func(art string) string {
+		if previous != nil {
+			art = previous(art)
+		}
+		return bg.composite(art)
+	}
+	return style
+}
+
+// composite fills the blank cells of art, up to its widest line, with the
+// tile repeated from the top left corner
+func (bg *Background) composite(art string) string {
+	lines := strings.Split(art, "\n")
+	width := blockWidth(lines)
+	for row, line := range lines {
+		var result strings.Builder
+		col := 0
+		for line != "" {
+			cluster, w := asciiart.NextGrapheme(line)
+			if cluster == " " {
+				result.WriteString(bg.at(row, col))
+			} else {
+				result.WriteString(cluster)
+			}
+			col += w
+			line = line[len(cluster):]
+		}
+		for ; col < width; col++ {
+			result.WriteString(bg.at(row, col))
+		}
+		lines[row] = result.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// at is the tile's cell for row and col of the art
+func (bg *Background) at(row, col int) string {
+	cells := bg.Tile[row%len(bg.Tile)]
+	return cells[col%len(cells)]
+}
+
+// colorizer colors the cells of art the background shows through in its
+// own color and the rest with c. bare is the same art rendered without
+// the background, whose blank cells they are. forced keeps the color even
+// when stdout is not a terminal.
+func (bg *Background) colorizer(bare string, c asciiart.Colorizer, forced bool) asciiart.Colorizer {
+	var blank [][]bool
+	for _, line := range strings.Split(bare, "\n") {
+		var cells []bool
+		for line != "" {
+			cluster, w := asciiart.NextGrapheme(line)
+			for range w {
+				cells = append(cells, cluster == " ")
+			}
+			line = line[len(cluster):]
+		}
+		blank = append(blank, cells)
+	}
+	dim := bg.Color
+	if forced {
+		clone := *bg.Color
+		clone.EnableColor()
+		dim = &clone
+	}
+	return asciiart.ColorizerFunc(func(cell asciiart.Cell) *color.Color {
+		if cell.Row < len(blank) && (cell.Col >= len(blank[cell.Row]) || blank[cell.Row][cell.Col]) {
+			return dim
+		}
+		if c == nil {
+			return nil
+		}
+		return c.ColorAt(cell)
+	})
+}
+
+// listBackgrounds shows a swatch of every background pattern
+func listBackgrounds() {
+	fmt.Println(color.BlueString("Background patterns:"))
+	for _, name := range backgroundNames() {
+		tile, _ := backgroundTile(backgroundPatterns[name])
+		bg := &Background{Tile: tile}
+		fmt.Printf("\n  %s\n", color.CyanString(name))
+		swatch := bg.composite(strings.Repeat(" ", 24) + "\n\n")
+		for _, line := range strings.Split(swatch, "\n") {
+			fmt.Println("    " + line)
+		}
+	}
+}
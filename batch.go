@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// batchExtensions name -out-dir files after the -format they hold
+var batchExtensions = map[string]string{"": ".txt", "text": ".txt", "ansi": ".ans", "ans": ".ans", "html": ".html", "svg": ".svg"}
+
+// readBatch returns the non-blank lines of path, or of stdin for "-"
+func readBatch(path string) ([]string, error) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRequestSize)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// batchFileNames names one file in dir per text after a slug of the
+// text, numbering repeats: getting-started.txt, getting-started-2.txt
+func (config *AppConfig) batchFileNames(dir string, texts []string, format string) []string {
+	ext := batchExtensions[format]
+	seen := make(map[string]int)
+	names := make([]string, len(texts))
+	for i, text := range texts {
+		// Name files after the text as rendered, placeholders filled in
+		if expanded, err := config.expandTemplate(text); err == nil {
+			text = expanded
+		}
+		slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(text), "-"), "-.")
+		if slug == "" {
+			slug = "banner"
+		}
+		seen[slug]++
+		if n := seen[slug]; n > 1 {
+			slug = fmt.Sprintf("%s-%d", slug, n)
+		}
+		names[i] = filepath.Join(dir, slug+ext)
+	}
+	return names
+}
+
+// runBatch renders every text in turn with the same options, each to
+// its own file when outDir is set
+func runBatch(config *AppConfig, options RenderOptions, texts []string, outDir string, number *NumberFormat) {
+	if len(texts) == 0 {
+		fmt.Println("Error: No text provided for batch mode")
+		os.Exit(1)
+	}
+	var paths []string
+	if outDir != "" {
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			fmt.Printf("Error creating output directory: %v\n", err)
+			os.Exit(1)
+		}
+		paths = config.batchFileNames(outDir, texts, options.format)
+	}
+
+	options.unattended()
+	options.limited = true
+	for i, text := range texts {
+		if number != nil {
+			formatted, err := formatNumber(text, *number)
+			if err != nil {
+				fmt.Printf("Error: %q: %v\n", text, err)
+				os.Exit(1)
+			}
+			text = formatted
+		}
+		if paths != nil {
+			options.outputFile = paths[i]
+		}
+		processText(text, config, options)
+	}
+}
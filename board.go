@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+const boardPollInterval = time.Second
+
+// BoardItem is one line of a notice board file
+type BoardItem struct {
+	text     string
+	done     bool
+	priority int // 0 none, 1 low, 2 medium, 3 high
+}
+
+// parseBoard reads a todo/notes file: one item per line, "[x]" or "[ ]"
+// done markers, and priority given as leading "!" (one to three) or a
+// todo.txt style "(A)".."(C)". A first line starting with "# " is the title.
+func parseBoard(path string) (string, []BoardItem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	var title string
+	var items []BoardItem
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "# ") && title == "" && len(items) == 0:
+			title = strings.TrimSpace(line[2:])
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		}
+
+		item := BoardItem{}
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "- "), "* "))
+		if lower := strings.ToLower(line); strings.HasPrefix(lower, "[x]") {
+			item.done, line = true, line[3:]
+		} else if strings.HasPrefix(line, "[ ]") {
+			line = line[3:]
+		}
+		line = strings.TrimSpace(line)
+
+		if bangs := len(line) - len(strings.TrimLeft(line, "!")); bangs > 0 {
+			item.priority, line = min(bangs, 3), line[bangs:]
+		} else if len(line) >= 3 && line[0] == '(' && line[2] == ')' && line[1] >= 'A' && line[1] <= 'C' {
+			item.priority, line = 3-int(line[1]-'A'), line[3:]
+		}
+		item.text = strings.TrimSpace(line)
+		items = append(items, item)
+	}
+	return title, items, scanner.Err()
+}
+
+var priorityColors = map[int]*color.Color{
+	1: color.New(color.FgCyan),
+	2: color.New(color.FgYellow),
+	3: color.New(color.FgRed, color.Bold),
+}
+
+// renderBoard draws the title in a figure font above the items, all
+// inside a double-line box.
+func renderBoard(title string, items []BoardItem, font string) string {
+	type row struct{ plain, colored string }
+	var rows []row
+
+	if title != "" {
+		for _, line := range strings.Split(strings.TrimRight(renderer.Figure(title, font), "\n"), "\n") {
+			rows = append(rows, row{line, color.HiWhiteString(line)})
+		}
+		rows = append(rows, row{})
+	}
+
+	for _, item := range items {
+		mark := "☐"
+		if item.done {
+			mark = "☑"
+		}
+		plain := mark + " " + item.text
+		colored := plain
+		switch {
+		case item.done:
+			colored = color.New(color.FgHiBlack, color.CrossedOut).Sprint(plain)
+		case priorityColors[item.priority] != nil:
+			colored = priorityColors[item.priority].Sprint(plain)
+		}
+		rows = append(rows, row{plain, colored})
+	}
+	if len(items) == 0 {
+		rows = append(rows, row{"(nothing here)", color.HiBlackString("(nothing here)")})
+	}
+
+	width := 0
+	for _, r := range rows {
+		width = max(width, asciiart.DisplayWidth(r.plain))
+	}
+
+	d := asciiart.DoubleBoxDecorator
+	var b strings.Builder
+	b.WriteString(d.Corners[0] + strings.Repeat(d.Top, width+2) + d.Corners[1] + "\n")
+	for _, r := range rows {
+		padding := strings.Repeat(" ", width-asciiart.DisplayWidth(r.plain))
+		b.WriteString(d.Left + " " + r.colored + padding + " " + d.Right + "\n")
+	}
+	b.WriteString(d.Corners[2] + strings.Repeat(d.Bottom, width+2) + d.Corners[3])
+	return b.String()
+}
+
+// runBoard renders a notice board from a tasks file, optionally
+// re-rendering whenever the file changes.
+func runBoard(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("board", flag.ExitOnError)
+	title := flags.String("title", "", "Board title (default: the file's \"# \" heading)")
+	font := flags.String("font", "small", "Figure font for the title")
+	watch := flags.Bool("watch", false, "Re-render whenever the file changes")
+	cue := addCueFlags(flags, "when -watch sees the last open item done")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Println("Usage: ascii-art board [-title T] [-font F] [-watch [-bell] [-sound FILE]] tasks.txt")
+		os.Exit(1)
+	}
+	path := flags.Arg(0)
+
+	scr := newScreen(os.Stdout)
+	pending := -1 // Items not done, unknown until the board is first read
+	draw := func() {
+		heading, items, err := parseBoard(path)
+		if err != nil {
+			fmt.Printf("Error reading board: %v\n", err)
+			if !*watch {
+				os.Exit(1)
+			}
+			// Start afresh once the file reads again
+			scr.invalidate()
+			return
+		}
+		if *title != "" {
+			heading = *title
+		}
+		if *watch {
+			scr.draw(renderBoard(heading, items, *font))
+			left := 0
+			for _, item := range items {
+				if !item.done {
+					left++
+				}
+			}
+			if pending > 0 && left == 0 {
+				cue.play()
+			}
+			pending = left
+			return
+		}
+		fmt.Println(renderBoard(heading, items, *font))
+	}
+
+	if !*watch {
+		draw()
+		return
+	}
+	scr.open()
+	defer scr.close()
+	resized, stopResize := watchResize()
+	defer stopResize()
+	draw()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	ticker := time.NewTicker(boardPollInterval)
+	defer ticker.Stop()
+
+	lastMod := modTime(path)
+	for {
+		select {
+		case <-interrupt:
+			return
+		case <-resized:
+			scr.invalidate()
+			draw()
+		case <-ticker.C:
+			if mod := modTime(path); !mod.Equal(lastMod) {
+				lastMod = mod
+				draw()
+			}
+		}
+	}
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"ascii-art/asciiart"
+)
+
+// renderFormats are the formats a render request can ask for
+var renderFormats = []string{"text", "ansi", "html", "svg", "json"}
+
+// maxPreviewText bounds the sample text of /preview, which is rendered
+// once per style
+const maxPreviewText = 64
+
+// Catalog lists everything a render request can name, for front-ends
+// building style pickers. Fonts holds the built-in fonts, then the
+// installed ones.
+type Catalog struct {
+	Categories   []CatalogCategory `json:"categories"`
+	ColorSchemes []CatalogScheme   `json:"colorschemes"`
+	Fonts        []string          `json:"fonts"`
+	Formats      []string          `json:"formats"`
+}
+
+type CatalogCategory struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Styles      []CatalogStyle `json:"styles"`
+}
+
+// CatalogStyle is one style; ID is the "category.style" form accepted
+// wherever a style is named
+type CatalogStyle struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Font        string `json:"font,omitempty"`
+}
+
+type CatalogScheme struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// PreviewEntry is one style rendered by /preview
+type PreviewEntry struct {
+	ID       string      `json:"id"`
+	Category string      `json:"category"`
+	Style    string      `json:"style"`
+	Art      RenderedArt `json:"art"`
+}
+
+func (config *AppConfig) catalog() Catalog {
+	catalog := Catalog{
+		Fonts:   append(asciiart.BuiltinFonts(), installedFonts()...),
+		Formats: renderFormats,
+	}
+	for i, category := range config.categories {
+		entry := CatalogCategory{Name: category.Name, Description: category.Description}
+		for j, style := range category.Styles {
+			entry.Styles = append(entry.Styles, CatalogStyle{
+				ID:          fmt.Sprintf("%d.%d", i+1, j+1),
+				Name:        style.Name,
+				Description: style.Description,
+				Font:        style.Font,
+			})
+		}
+		catalog.Categories = append(catalog.Categories, entry)
+	}
+	for i, scheme := range config.colors {
+		catalog.ColorSchemes = append(catalog.ColorSchemes, CatalogScheme{ID: i + 1, Name: scheme.Name})
+	}
+	return catalog
+}
+
+// handleStyles returns the catalog of styles, color schemes and formats
+func (config *AppConfig) handleStyles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", formatTypes["json"])
+	json.NewEncoder(w).Encode(config.catalog())
+}
+
+// handlePreview renders the sample text in every style, or every style
+// of one category, as JSON or, with format=html, as a page of cards
+//
+//	GET /preview?text=Hi&colorscheme=Ocean&category=2&format=html
+func (config *AppConfig) handlePreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	text := query.Get("text")
+	if text == "" {
+		text = gallerySample
+	}
+	if utf8.RuneCountInString(text) > maxPreviewText {
+		http.Error(w, fmt.Sprintf("preview text is longer than %d characters", maxPreviewText), http.StatusBadRequest)
+		return
+	}
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "html" {
+		http.Error(w, fmt.Sprintf("unknown preview format %q (use json or html)", format), http.StatusBadRequest)
+		return
+	}
+	only := 0
+	if spec := query.Get("category"); spec != "" {
+		n, err := strconv.Atoi(spec)
+		if err != nil || n < 1 || n > len(config.categories) {
+			http.Error(w, fmt.Sprintf("unknown category %q", spec), http.StatusBadRequest)
+			return
+		}
+		only = n
+	}
+
+	var entries []PreviewEntry
+	for i, category := range config.categories {
+		if only != 0 && only != i+1 {
+			continue
+		}
+		for j, style := range category.Styles {
+			request := RenderRequest{
+				Text:        text,
+				Style:       fmt.Sprintf("%d.%d", i+1, j+1),
+				ColorScheme: query.Get("colorscheme"),
+				Format:      "json",
+			}
+			response := config.answer(request)
+			config.audit.record(r.RemoteAddr, request, response)
+			if response.Error != "" {
+				http.Error(w, response.Error, http.StatusBadRequest)
+				return
+			}
+			entries = append(entries, PreviewEntry{ID: request.Style, Category: category.Name, Style: style.Name, Art: *response.Art})
+		}
+	}
+
+	w.Header().Set("Content-Type", formatTypes[format])
+	if format == "json" {
+		json.NewEncoder(w).Encode(entries)
+		return
+	}
+	fmt.Fprint(w, previewPage(entries))
+}
+
+// previewPage lays the previews out as a grid of captioned cards
+func previewPage(entries []PreviewEntry) string {
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>ASCII Art Styles</title>
+<style>
+body { background: #111; color: #e5e5e5; font-family: sans-serif; }
+.grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(24rem, 1fr)); gap: 1rem; }
+figure { margin: 0; padding: 0.5rem; background: #1e1e1e; border-radius: 6px; overflow-x: auto; }
+figcaption { font-size: 0.9rem; margin-bottom: 0.5rem; }
+pre { margin: 0; }
+</style></head>
+<body><div class="grid">
+`)
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "<figure><figcaption>%s %s <small>%s</small></figcaption>%s</figure>\n",
+			entry.ID, html.EscapeString(entry.Style), html.EscapeString(entry.Category), entry.Art.HTML)
+	}
+	b.WriteString("</div></body></html>\n")
+	return b.String()
+}
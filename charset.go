@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+// runConvertCharset translates an art file between code page 437 and
+// UTF-8, one character for one, so its layout stays as it was. A SAUCE
+// record at the end is kept, with its file size updated.
+func runConvertCharset(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("convert-charset", flag.ExitOnError)
+	to := flags.String("to", "", "Character set to write: utf8 or cp437 (default: the one the input is not in)")
+	out := flags.String("o", "", "File to write (default: stdout)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: ascii-art convert-charset [-to utf8|cp437] [-o FILE] [FILE]")
+		flags.PrintDefaults()
+	}
+	files := parseInterspersed(flags, args)
+	if len(files) > 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	var data []byte
+	var err error
+	if len(files) == 0 || files[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(files[0])
+	}
+	if err != nil {
+		fmt.Printf("Error reading art: %v\n", err)
+		os.Exit(1)
+	}
+
+	art, record := asciiart.ParseSAUCE(data)
+	if *to == "" {
+		*to = "utf8"
+		if isUTF8Text(art) {
+			*to = "cp437"
+		}
+	}
+	switch *to {
+	case "utf8", "utf-8":
+		art = []byte(asciiart.DecodeCP437(art))
+	case "cp437":
+		marks := bytes.Count(art, []byte("?"))
+		var lossless bool
+		if art, lossless = asciiart.EncodeCP437(string(art)); !lossless {
+			fmt.Fprintln(os.Stderr, color.YellowString("Warning: characters code page 437 lacks became '?' (%d)", bytes.Count(art, []byte("?"))-marks))
+		}
+	default:
+		fmt.Printf("Error: unknown character set %q (use utf8 or cp437)\n", *to)
+		os.Exit(1)
+	}
+	if record != nil {
+		art = record.Append(art)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(art)
+		return
+	}
+	if err := os.WriteFile(*out, art, 0644); err != nil {
+		fmt.Printf("Error saving to file: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// isUTF8Text reports whether data is UTF-8 with characters beyond ASCII,
+// as opposed to code page 437, which is rarely valid UTF-8
+func isUTF8Text(data []byte) bool {
+	return utf8.Valid(data) && bytes.ContainsFunc(data, func(r rune) bool { return r >= utf8.RuneSelf })
+}
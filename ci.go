@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+// CIProvider describes how a CI system groups log output
+type CIProvider struct {
+	name   string
+	width  int  // Usable log width in columns
+	ansi   bool // Whether the log viewer renders ANSI colors
+	detect func() bool
+	start  func(title, slug string) string
+	end    func(title, slug string) string
+}
+
+var ciProviders = []CIProvider{
+	{
+		name:   "github",
+		width:  120,
+		ansi:   true,
+		detect: func() bool { return os.Getenv("GITHUB_ACTIONS") == "true" },
+		start:  func(title, slug string) string { return "::group::" + title },
+		end:    func(title, slug string) string { return "::endgroup::" },
+	},
+	{
+		name:   "gitlab",
+		width:  120,
+		ansi:   true,
+		detect: func() bool { return os.Getenv("GITLAB_CI") != "" },
+		start: func(title, slug string) string {
+			return fmt.Sprintf("\033[0Ksection_start:%d:%s[collapsed=false]\r\033[0K%s", time.Now().Unix(), slug, title)
+		},
+		end: func(title, slug string) string {
+			return fmt.Sprintf("\033[0Ksection_end:%d:%s\r\033[0K", time.Now().Unix(), slug)
+		},
+	},
+	{
+		name:   "jenkins",
+		width:  100,
+		detect: func() bool { return os.Getenv("JENKINS_URL") != "" || os.Getenv("JENKINS_HOME") != "" },
+	},
+	{
+		name:   "generic",
+		width:  80,
+		detect: func() bool { return true },
+	},
+}
+
+func detectCIProvider(name string) (CIProvider, error) {
+	for _, p := range ciProviders {
+		if name == p.name || (name == "" && p.detect()) {
+			return p, nil
+		}
+	}
+	return CIProvider{}, fmt.Errorf("unknown CI provider %q", name)
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+// ciStageFonts are tried in order until the banner fits the log width
+var ciStageFonts = []string{"standard", "small", "mini"}
+
+// runCIStage prints a stage header banner wrapped in the CI provider's
+// group/section syntax. Run it again with -end to close the section.
+func runCIStage(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("ci-stage", flag.ExitOnError)
+	provider := flags.String("provider", "", "CI provider: github, gitlab, jenkins, generic (default: detect)")
+	end := flags.Bool("end", false, "Close the section opened for this title")
+	colorFlag := flags.Int("colorscheme", 1, "Color scheme number (0 for none)")
+	positional := parseInterspersed(flags, args)
+
+	title := strings.Join(positional, " ")
+	if title == "" {
+		fmt.Println("Usage: ascii-art ci-stage [-provider P] [-end] \"Stage name\"")
+		os.Exit(1)
+	}
+
+	p, err := detectCIProvider(*provider)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(title), "_"), "_")
+
+	if *end {
+		if p.end != nil {
+			fmt.Println(p.end(title, slug))
+		}
+		return
+	}
+
+	// CI logs are never a terminal, so honour the provider's ANSI support
+	color.NoColor = !p.ansi
+	var scheme *asciiart.ColorScheme
+	if p.ansi && *colorFlag > 0 && *colorFlag <= len(config.colors) {
+		scheme = &config.colors[*colorFlag-1]
+	}
+
+	banner := title
+	for _, font := range ciStageFonts {
+		art := strings.TrimRight(renderer.Figure(title, font), "\n")
+		if blockWidth(strings.Split(art, "\n")) <= p.width {
+			banner = art
+			break
+		}
+	}
+	if scheme != nil {
+		banner = asciiart.ApplyColorScheme(banner, scheme)
+	}
+
+	if p.start != nil {
+		fmt.Println(p.start(title, slug))
+	}
+	fmt.Println(banner)
+}
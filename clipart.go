@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+// -art-position values
+const (
+	artLeft  = "left"
+	artRight = "right"
+	artAbove = "above"
+	artBelow = "below"
+)
+
+const clipartGap = 2
+
+// Clipart is a small figure from the built-in library, drawn next to or
+// above the banner with -art
+type Clipart struct {
+	Name     string
+	Category string
+	Art      string
+}
+
+var clipartLibrary = []Clipart{
+	{"cat", "animals", ` /\_/\
+( o.o )
+ > ^ <`},
+	{"owl", "animals", ` ,_,
+(O,O)
+(   )
+ " "`},
+	{"fish", "animals", `><(((º>`},
+	{"bunny", "animals", `(\(\
+( -.-)
+o_(")(")`},
+	{"bat", "animals", `/\                 /\
+/ \'._   (\_/)   _.'/ \
+|.''._'--(o.o)--'_.''.|
+ \_ / ';'-._-.'; \ _/
+   '        ''      '`},
+	{"arrow-right", "arrows", `---->`},
+	{"arrow-left", "arrows", `<----`},
+	{"arrow-up", "arrows", `  ^
+ /|\
+  |
+  |`},
+	{"arrow-down", "arrows", `  |
+  |
+ \|/
+  v`},
+	{"heart", "logos", ` ,d88b.d88b,
+ 88888888888
+ 'Y8888888Y'
+   'Y888Y'
+     'Y'`},
+	{"star", "logos", `    .
+   /.\
+.-'   '-.
+ '.   .'
+ /.' '.\`},
+	{"coffee", "logos", `  ( (
+   ) )
+ ........
+ |      |]
+ \      /
+  '----'`},
+	{"rocket", "logos", `   /\
+  |==|
+  |  |
+ /|##|\
+/_|__|_\
+  /\/\`},
+	{"gopher", "logos", ` ,_---~~~~~----._
+/  O     O       \
+|    .--.        |
+ \   '--'       /
+  '-.________.-'`},
+	{"wave", "dividers", `.-~~-.-~~-.-~~-.-~~-.-~~-.`},
+	{"dots", "dividers", `• · • · • · • · • · • · •`},
+	{"vine", "dividers", `~@~~~@~~~@~~~@~~~@~~~@~`},
+	{"rule", "dividers", `════════════ ◆ ════════════`},
+}
+
+func findClipart(name string) (*Clipart, error) {
+	for i := range clipartLibrary {
+		if strings.EqualFold(clipartLibrary[i].Name, name) {
+			return &clipartLibrary[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown art %q (-list-art shows them)", name)
+}
+
+func checkArtPosition(position string) error {
+	switch position {
+	case artLeft, artRight, artAbove, artBelow:
+		return nil
+	}
+	return fmt.Errorf("unknown art position %q (use left, right, above or below)", position)
+}
+
+// withClipart returns style with clip drawn beside its finished art,
+// vertically centered, or centered above or below it
+func withClipart(style asciiart.Style, clip *Clipart, position string) asciiart.Style {
+	previous := style.Decorator.Post
+	style.Decorator.Post = func(art string) string {
+		if previous != nil {
+			art = previous(art)
+		}
+		return placeClipart(art, clip.Art, position)
+	}
+	return style
+}
+
+func placeClipart(art, clip, position string) string {
+	artLines, clipLines := strings.Split(art, "\n"), strings.Split(clip, "\n")
+	switch position {
+	case artAbove, artBelow:
+		width := max(blockWidth(artLines), blockWidth(clipLines))
+		artLines, clipLines = centerBlock(artLines, width), centerBlock(clipLines, width)
+		if position == artAbove {
+			return strings.Join(append(clipLines, artLines...), "\n")
+		}
+		return strings.Join(append(artLines, clipLines...), "\n")
+	}
+
+	// Pad the shorter block above so the two share a middle line
+	offset := (len(artLines) - len(clipLines)) / 2
+	if offset > 0 {
+		clipLines = append(make([]string, offset), clipLines...)
+	} else {
+		artLines = append(make([]string, -offset), artLines...)
+	}
+	blocks := [][]string{clipLines, artLines}
+	if position == artRight {
+		blocks = [][]string{artLines, clipLines}
+	}
+	return strings.Join(joinHorizontal(blocks, clipartGap), "\n")
+}
+
+// centerBlock shifts every line of block right to center it as a whole
+// within width, keeping the lines aligned with each other
+func centerBlock(block []string, width int) []string {
+	indent := strings.Repeat(" ", max(0, (width-blockWidth(block))/2))
+	centered := make([]string, len(block))
+	for i, line := range block {
+		centered[i] = indent + line
+	}
+	return centered
+}
+
+// listClipart prints the library by category, each figure under its name
+func listClipart() {
+	category := ""
+	for i, clip := range clipartLibrary {
+		if clip.Category != category {
+			category = clip.Category
+			fmt.Println(color.BlueString("\n%s:", strings.ToUpper(category[:1])+category[1:]))
+		}
+		fmt.Printf("\n%2d. %s\n", i+1, color.CyanString(clip.Name))
+		for _, line := range strings.Split(clip.Art, "\n") {
+			fmt.Println("    " + line)
+		}
+	}
+}
+
+// browseClipart lists the library and asks for a figure by number or
+// name, for ":art" in interactive mode. An empty answer picks none.
+func browseClipart(reader *bufio.Reader) *Clipart {
+	listClipart()
+	for {
+		fmt.Print(color.GreenString("\nPick art by number or name (Enter for none): "))
+		answer, err := reader.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if answer == "" || err != nil {
+			return nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(clipartLibrary) {
+			return &clipartLibrary[n-1]
+		}
+		if clip, err := findClipart(answer); err == nil {
+			return clip
+		}
+		fmt.Println(color.RedString("Invalid selection. Please try again."))
+	}
+}
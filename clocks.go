@@ -0,0 +1,145 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+)
+
+const clocksGap = 4
+
+// ClockConfig is one entry of the clocks list in config.yaml
+type ClockConfig struct {
+	Label string `yaml:"label"`
+	Zone  string `yaml:"zone"`
+}
+
+type clock struct {
+	label    string
+	location *time.Location
+}
+
+var defaultClocks = []ClockConfig{
+	{"San Francisco", "America/Los_Angeles"},
+	{"New York", "America/New_York"},
+	{"London", "Europe/London"},
+	{"Tokyo", "Asia/Tokyo"},
+}
+
+// runClocks renders the current time in several time zones side by side
+// with city labels, refreshing each minute.
+func runClocks(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("clocks", flag.ExitOnError)
+	zones := flags.String("zones", "", "Comma-separated Label=Zone list, e.g. \"Berlin=Europe/Berlin,UTC=UTC\"")
+	font := flags.String("font", "small", "Figure font for the times")
+	colorFlag := flags.Int("colorscheme", 1, "Color scheme number (0 for none)")
+	seconds := flags.Bool("seconds", false, "Show seconds and refresh every second")
+	once := flags.Bool("once", false, "Print the clocks once and exit")
+	cue := addCueFlags(flags, "when the clocks stop")
+	fps, cpuLimit := addFrameFlags(flags)
+	flags.Parse(args)
+	limiter := mustFrameLimiter(*fps, *cpuLimit)
+
+	entries := defaultClocks
+	if cfg, err := loadUserConfig(); err == nil && len(cfg.Clocks) > 0 {
+		entries = cfg.Clocks
+	}
+	if *zones != "" {
+		entries = parseClockList(*zones)
+	}
+
+	var clocks []clock
+	for _, entry := range entries {
+		location, err := time.LoadLocation(entry.Zone)
+		if err != nil {
+			fmt.Printf("Error: unknown time zone %q\n", entry.Zone)
+			os.Exit(1)
+		}
+		clocks = append(clocks, clock{entry.Label, location})
+	}
+
+	var scheme *asciiart.ColorScheme
+	if *colorFlag > 0 && *colorFlag <= len(config.colors) {
+		scheme = &config.colors[*colorFlag-1]
+	}
+
+	layout, interval := "15:04", time.Minute
+	if *seconds {
+		layout, interval = "15:04:05", time.Second
+	}
+
+	draw := func(now time.Time) string {
+		art := strings.Join(renderClocks(clocks, now, layout, *font), "\n")
+		if scheme != nil {
+			art = asciiart.ApplyColorScheme(art, scheme)
+		}
+		return art
+	}
+
+	if *once {
+		fmt.Println(draw(time.Now()))
+		cue.play()
+		return
+	}
+
+	// Deferred first so it runs last, once the screen is restored
+	defer cue.play()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	scr := newScreen(os.Stdout)
+	scr.open()
+	defer scr.close()
+	resized, stopResize := watchResize()
+	defer stopResize()
+	for {
+		limiter.begin()
+		now := time.Now()
+		scr.draw(draw(now))
+		// Wake on the next minute (or second) boundary so clocks tick
+		// together, unless the limiter asks for a longer rest
+		select {
+		case <-time.After(max(now.Truncate(interval).Add(interval).Sub(now), limiter.next(0))):
+		case <-resized:
+			scr.invalidate()
+		case <-interrupt:
+			return
+		}
+	}
+}
+
+// renderClocks draws each clock as a time above its centered label and
+// joins them horizontally.
+func renderClocks(clocks []clock, now time.Time, layout, font string) []string {
+	var blocks [][]string
+	for _, c := range clocks {
+		block := strings.Split(strings.TrimRight(renderer.Figure(now.In(c.location).Format(layout), font), "\n"), "\n")
+		width := max(blockWidth(block), len([]rune(c.label)))
+		block = append(block, "", centerLine(c.label, width))
+		blocks = append(blocks, block)
+	}
+	return joinHorizontal(blocks, clocksGap)
+}
+
+// parseClockList parses "Label=Zone,Label=Zone". A bare zone is labelled
+// with its city name.
+func parseClockList(list string) []ClockConfig {
+	var clocks []ClockConfig
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		label, zone, ok := strings.Cut(item, "=")
+		if !ok {
+			zone = label
+			label = strings.ReplaceAll(zone[strings.LastIndex(zone, "/")+1:], "_", " ")
+		}
+		clocks = append(clocks, ClockConfig{strings.TrimSpace(label), strings.TrimSpace(zone)})
+	}
+	return clocks
+}
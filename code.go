@@ -0,0 +1,587 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// Kinds of code tokens, each highlighted in its own color
+const (
+	tokenPlain = iota
+	tokenKeyword
+	tokenType // Built-in types and constants
+	tokenFunction
+	tokenString
+	tokenNumber
+	tokenComment
+)
+
+// tokenColors are the SGR codes of each token kind, a palette that reads
+// on dark and light terminals alike
+var tokenColors = map[int]string{
+	tokenKeyword:  "\x1b[35m",
+	tokenType:     "\x1b[36m",
+	tokenFunction: "\x1b[34m",
+	tokenString:   "\x1b[32m",
+	tokenNumber:   "\x1b[33m",
+	tokenComment:  "\x1b[90m",
+}
+
+// Colors of the card itself
+const (
+	cardFrame = "\x1b[90m"
+	cardTitle = "\x1b[1m"
+)
+
+// cardButtons are the colors of the window buttons at the start of the
+// title bar
+var cardButtons = []string{"\x1b[31m", "\x1b[33m", "\x1b[32m"}
+
+const cardButtonsWidth = 5 // "● ● ●"
+
+type codeToken struct {
+	kind int
+	text string
+}
+
+// codeLanguage is what the highlighter knows of a language: enough to
+// pick out comments, strings, numbers and words, not a full grammar
+type codeLanguage struct {
+	name         string
+	aliases      []string // Other names and file extensions
+	keywords     []string
+	types        []string
+	lineComments []string
+	blockComment [2]string
+	quotes       string // Characters that start a string
+	multiline    string // Quotes whose strings may span lines
+	triple       bool   // Python's """ and ''' strings
+	ignoreCase   bool   // Keywords and types in any case, as in SQL
+}
+
+var codeLanguages = []*codeLanguage{
+	{
+		name:    "go",
+		aliases: []string{"golang"},
+		keywords: strings.Fields(`break case chan const continue default defer else fallthrough for func go goto
+			if import interface map package range return select struct switch type var`),
+		types: strings.Fields(`bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune
+			string uint uint8 uint16 uint32 uint64 uintptr any comparable true false nil iota`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+		multiline:    "`",
+	},
+	{
+		name:    "python",
+		aliases: []string{"py"},
+		keywords: strings.Fields(`and as assert async await break class continue def del elif else except finally
+			for from global if import in is lambda match case nonlocal not or pass raise return try while with yield`),
+		types:        strings.Fields(`True False None int float str bool list dict set tuple bytes object self`),
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		triple:       true,
+	},
+	{
+		name:    "javascript",
+		aliases: []string{"js", "mjs", "cjs", "jsx", "typescript", "ts", "tsx"},
+		keywords: strings.Fields(`async await break case catch class const continue debugger default delete do else
+			export extends finally for function if import in instanceof let new of return static super switch this
+			throw try typeof var void while with yield interface type enum implements`),
+		types: strings.Fields(`true false null undefined NaN Infinity number string boolean any unknown never
+			object symbol bigint Array Object Promise Map Set`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+		multiline:    "`",
+	},
+	{
+		name:    "rust",
+		aliases: []string{"rs"},
+		keywords: strings.Fields(`as async await break const continue crate dyn else enum extern fn for if impl in
+			let loop match mod move mut pub ref return self Self static struct super trait type unsafe use where while`),
+		types: strings.Fields(`bool char i8 i16 i32 i64 i128 isize u8 u16 u32 u64 u128 usize f32 f64 str String
+			Vec Option Result Box Some None Ok Err true false`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"`,
+		multiline:    `"`,
+	},
+	{
+		name:    "c",
+		aliases: []string{"h", "cpp", "c++", "cc", "cxx", "hpp", "hh"},
+		keywords: strings.Fields(`auto break case catch class const constexpr continue default delete do else enum
+			extern for goto if inline namespace new noexcept operator private protected public register return
+			sizeof static struct switch template this throw try typedef typename union using virtual volatile while
+			#include #define #ifdef #ifndef #endif #if #else #pragma`),
+		types: strings.Fields(`bool char double float int long short signed unsigned void size_t int8_t int16_t
+			int32_t int64_t uint8_t uint16_t uint32_t uint64_t std string vector true false NULL nullptr`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+	},
+	{
+		name:    "java",
+		aliases: []string{"kotlin", "kt"},
+		keywords: strings.Fields(`abstract assert break case catch class continue default do else enum extends final
+			finally for if implements import instanceof interface native new package private protected public return
+			static super switch synchronized this throw throws try var val fun while when object`),
+		types: strings.Fields(`boolean byte char double float int long short void String Object Integer List Map
+			true false null`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `"'`,
+	},
+	{
+		name:    "shell",
+		aliases: []string{"sh", "bash", "zsh"},
+		keywords: strings.Fields(`if then else elif fi case esac for while until do done in function return exit
+			local export readonly set unset shift source alias echo printf cd test`),
+		types:        strings.Fields(`true false`),
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+		multiline:    `"'`,
+	},
+	{
+		name:    "ruby",
+		aliases: []string{"rb"},
+		keywords: strings.Fields(`alias and begin break case class def defined? do else elsif end ensure for if in
+			module next not or redo rescue retry return self super then undef unless until when while yield
+			require attr_reader attr_accessor puts`),
+		types:        strings.Fields(`true false nil`),
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+	},
+	{
+		name: "sql",
+		keywords: strings.Fields(`select from where and or not insert into values update set delete create table
+			drop alter index join left right inner outer on group by order having limit as distinct union all
+			primary key references default`),
+		types:        strings.Fields(`int integer text varchar boolean date timestamp null true false`),
+		lineComments: []string{"--"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       `'"`,
+		ignoreCase:   true,
+	},
+	{
+		name:         "json",
+		types:        strings.Fields(`true false null`),
+		quotes:       `"`,
+		lineComments: []string{},
+	},
+	{
+		name:         "yaml",
+		aliases:      []string{"yml"},
+		types:        strings.Fields(`true false null yes no on off ~`),
+		lineComments: []string{"#"},
+		quotes:       `"'`,
+	},
+	{
+		name:    "text",
+		aliases: []string{"txt", "plain"},
+	},
+}
+
+// findLanguage returns the language called name, by its name, an alias
+// or a file extension
+func findLanguage(name string) (*codeLanguage, bool) {
+	name = strings.ToLower(strings.TrimPrefix(name, "."))
+	for _, lang := range codeLanguages {
+		if lang.name == name || slices.Contains(lang.aliases, name) {
+			return lang, true
+		}
+	}
+	return nil, false
+}
+
+// languageNames lists every language name and alias, for errors and the
+// list command
+func languageNames() []string {
+	var names []string
+	for _, lang := range codeLanguages {
+		names = append(names, lang.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func listLanguages() {
+	fmt.Println(color.BlueString("Languages:"))
+	for _, name := range languageNames() {
+		lang, _ := findLanguage(name)
+		aliases := ""
+		if len(lang.aliases) > 0 {
+			aliases = " (" + strings.Join(lang.aliases, ", ") + ")"
+		}
+		fmt.Printf("  %s%s\n", color.CyanString(name), color.HiBlackString(aliases))
+	}
+}
+
+var codeNumber = regexp.MustCompile(`^(0[xX][0-9a-fA-F_]+|0[bB][01_]+|[0-9][0-9_]*(\.[0-9_]+)?([eE][+-]?[0-9]+)?)`)
+
+// tokens splits src into highlighted tokens. Strings and block comments
+// may run over several lines.
+func (lang *codeLanguage) tokens(src string) []codeToken {
+	keywords, types := make(map[string]bool), make(map[string]bool)
+	for _, word := range lang.keywords {
+		keywords[word] = true
+	}
+	for _, word := range lang.types {
+		types[word] = true
+	}
+
+	var tokens []codeToken
+	emit := func(kind int, text string) {
+		if n := len(tokens); n > 0 && tokens[n-1].kind == kind {
+			tokens[n-1].text += text
+			return
+		}
+		tokens = append(tokens, codeToken{kind, text})
+	}
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		if open, end := lang.blockComment[0], lang.blockComment[1]; open != "" && strings.HasPrefix(rest, open) {
+			n := len(rest)
+			if j := strings.Index(rest[len(open):], end); j >= 0 {
+				n = len(open) + j + len(end)
+			}
+			emit(tokenComment, rest[:n])
+			i += n
+			continue
+		}
+		if lang.lineComment(rest) {
+			n := strings.IndexByte(rest, '\n')
+			if n < 0 {
+				n = len(rest)
+			}
+			emit(tokenComment, rest[:n])
+			i += n
+			continue
+		}
+		if lang.triple && (strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, `'''`)) {
+			n := len(rest)
+			if j := strings.Index(rest[3:], rest[:3]); j >= 0 {
+				n = 3 + j + 3
+			}
+			emit(tokenString, rest[:n])
+			i += n
+			continue
+		}
+		if lang.quotes != "" && strings.IndexByte(lang.quotes, rest[0]) >= 0 {
+			n := stringLength(rest, strings.IndexByte(lang.multiline, rest[0]) >= 0)
+			emit(tokenString, rest[:n])
+			i += n
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case r < utf8.RuneSelf && r >= '0' && r <= '9' && !wordBefore(src, i):
+			n := len(codeNumber.FindString(rest))
+			emit(tokenNumber, rest[:n])
+			i += n
+		case r == '_' || r == '#' && keywords["#include"] || unicode.IsLetter(r):
+			n := size
+			for n < len(rest) {
+				next, size := utf8.DecodeRuneInString(rest[n:])
+				if next != '_' && next != '?' && !unicode.IsLetter(next) && !unicode.IsDigit(next) {
+					break
+				}
+				n += size
+			}
+			word, kind := rest[:n], tokenPlain
+			key := word
+			if lang.ignoreCase {
+				key = strings.ToLower(word)
+			}
+			switch {
+			case keywords[key]:
+				kind = tokenKeyword
+			case types[key]:
+				kind = tokenType
+			case strings.HasPrefix(strings.TrimLeft(rest[n:], " "), "(") && len(lang.keywords) > 0:
+				kind = tokenFunction
+			}
+			emit(kind, word)
+			i += n
+		default:
+			emit(tokenPlain, rest[:size])
+			i += size
+		}
+	}
+	return tokens
+}
+
+// lineComment reports whether text starts with a line comment
+func (lang *codeLanguage) lineComment(text string) bool {
+	for _, prefix := range lang.lineComments {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// wordBefore reports whether src[i] continues a word, as the 8 in utf8
+func wordBefore(src string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(src[:i])
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// stringLength is the length of the string literal text starts with,
+// up to its closing quote, skipping escaped ones; an unclosed string
+// ends at the line unless it may span lines
+func stringLength(text string, multiline bool) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case '\n':
+			if !multiline {
+				return i
+			}
+		case quote:
+			return i + 1
+		}
+	}
+	return len(text)
+}
+
+// expandTabs replaces tabs with spaces up to the next multiple of width
+func expandTabs(src string, width int) string {
+	var b strings.Builder
+	column := 0
+	for _, r := range src {
+		switch r {
+		case '\t':
+			n := width - column%width
+			b.WriteString(strings.Repeat(" ", n))
+			column += n
+		case '\n':
+			b.WriteRune(r)
+			column = 0
+		default:
+			b.WriteRune(r)
+			column += asciiart.DisplayWidth(string(r))
+		}
+	}
+	return b.String()
+}
+
+// codeLines splits tokens into lines, a token running over several lines
+// becoming one piece on each
+func codeLines(tokens []codeToken) [][]codeToken {
+	lines := [][]codeToken{nil}
+	for _, token := range tokens {
+		for i, piece := range strings.Split(token.text, "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if piece != "" {
+				lines[len(lines)-1] = append(lines[len(lines)-1], codeToken{token.kind, piece})
+			}
+		}
+	}
+	return lines
+}
+
+// codeCard draws the lines of code in a rounded box under a title bar
+// with window buttons, like a screenshot of an editor window, in color
+// when colors is set
+func codeCard(title string, lines [][]codeToken, lineNumbers, colors bool) string {
+	paint := func(sgr, text string) string {
+		if !colors || sgr == "" || text == "" {
+			return text
+		}
+		return sgr + text + sgrReset
+	}
+
+	gutter := 0
+	if lineNumbers {
+		gutter = len(fmt.Sprint(len(lines))) + 2
+	}
+	titleWidth := asciiart.DisplayWidth(title)
+	width := titleWidth + 2*(cardButtonsWidth+2)
+	for _, line := range lines {
+		lineWidth := gutter
+		for _, token := range line {
+			lineWidth += asciiart.DisplayWidth(token.text)
+		}
+		width = max(width, lineWidth)
+	}
+
+	var b strings.Builder
+	frame := func(s string) string { return paint(cardFrame, s) }
+	b.WriteString(frame("╭"+strings.Repeat("─", width+2)+"╮") + "\n")
+
+	var buttons []string
+	for _, sgr := range cardButtons {
+		buttons = append(buttons, paint(sgr, "●"))
+	}
+	start := max(cardButtonsWidth+2, (width-titleWidth)/2)
+	b.WriteString(frame("│") + " " + strings.Join(buttons, " ") + strings.Repeat(" ", start-cardButtonsWidth) + paint(cardTitle, title) +
+		strings.Repeat(" ", width-start-titleWidth) + " " + frame("│") + "\n")
+	b.WriteString(frame("├"+strings.Repeat("─", width+2)+"┤") + "\n")
+
+	for n, line := range lines {
+		var row strings.Builder
+		lineWidth := gutter
+		if lineNumbers {
+			row.WriteString(paint(cardFrame, fmt.Sprintf("%*d  ", gutter-2, n+1)))
+		}
+		for _, token := range line {
+			row.WriteString(paint(tokenColors[token.kind], token.text))
+			lineWidth += asciiart.DisplayWidth(token.text)
+		}
+		b.WriteString(frame("│") + " " + row.String() + strings.Repeat(" ", width-lineWidth) + " " + frame("│") + "\n")
+	}
+	b.WriteString(frame("╰" + strings.Repeat("─", width+2) + "╯"))
+	return b.String()
+}
+
+// renderCode highlights src as lang and draws it in a card, with every
+// output variant, all of them without colors unless colors is set
+func renderCode(src string, lang *codeLanguage, title string, tabWidth int, lineNumbers, colors bool) RenderedArt {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.TrimRight(expandTabs(src, tabWidth), " \n")
+	var lines [][]codeToken
+	for _, line := range codeLines(lang.tokens(src)) {
+		// Drop trailing blanks, which would only widen the card
+		if n := len(line); n > 0 && line[n-1].kind == tokenPlain {
+			line[n-1].text = strings.TrimRight(line[n-1].text, " ")
+		}
+		lines = append(lines, line)
+	}
+	plain := codeCard(title, lines, lineNumbers, false)
+	if !colors {
+		return RenderedArt{Text: src, Plain: plain, ANSI: plain, HTML: asciiart.HTML(plain, nil), SVG: asciiart.SVG(plain, nil)}
+	}
+	ansi := codeCard(title, lines, lineNumbers, true)
+	canvas := asciiart.ParseANSI(ansi, 0)
+	return RenderedArt{
+		Text:  src,
+		Plain: plain,
+		ANSI:  ansi,
+		HTML:  asciiart.HTML(plain, canvas),
+		SVG:   asciiart.SVG(plain, canvas),
+	}
+}
+
+// runCode is the code command: a snippet from a file or stdin,
+// syntax-highlighted in a card titled with its file name
+func runCode(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("code", flag.ExitOnError)
+	langName := flags.String("lang", "", "Language of the snippet (default: from the file extension; list languages shows them)")
+	title := flags.String("title", "", "Title bar text (default: the file name, or the language)")
+	lineNumbers := flags.Bool("line-numbers", false, "Number the lines")
+	tabWidth := flags.Int("tab-width", 4, "Columns per tab stop")
+	format := flags.String("format", "", "Output format: text, ansi, ans, html or svg (default: colors on a terminal)")
+	output := flags.String("output", "", "Save to this file, or send to an output target, instead of printing")
+	showColors := flags.Bool("color", true, "Highlight in color")
+	paths := parseInterspersed(flags, args)
+	if len(paths) > 1 || len(paths) == 0 && isatty.IsTerminal(os.Stdin.Fd()) || *tabWidth < 1 {
+		fmt.Println("Usage: ascii-art code [-lang LANG] [-title TEXT] [-line-numbers] [-format FORMAT] [-output FILE] FILE|-")
+		os.Exit(1)
+	}
+
+	path := "-"
+	if len(paths) == 1 {
+		path = paths[0]
+	}
+	var src []byte
+	var err error
+	if path == "-" {
+		src, err = io.ReadAll(io.LimitReader(os.Stdin, maxCodeSize+1))
+	} else {
+		src, err = os.ReadFile(path)
+	}
+	if err != nil {
+		fmt.Printf("Error reading code: %v\n", err)
+		os.Exit(1)
+	}
+	if strings.TrimSpace(string(src)) == "" {
+		fmt.Println("Error: No code provided")
+		os.Exit(1)
+	}
+	if len(src) > maxCodeSize || strings.Count(string(src), "\n") >= maxCodeLines {
+		fmt.Printf("Error: a code card takes a snippet of up to %d lines and %d KB\n", maxCodeLines, maxCodeSize/1024)
+		os.Exit(1)
+	}
+
+	name := *langName
+	if name == "" && path != "-" {
+		name = filepath.Ext(path)
+	}
+	lang, ok := findLanguage(name)
+	if !ok && *langName != "" {
+		fmt.Printf("Error: unknown language %q (use %s)\n", *langName, strings.Join(languageNames(), ", "))
+		os.Exit(1)
+	}
+	if !ok {
+		lang, _ = findLanguage("text")
+	}
+	if *title == "" {
+		*title = lang.name
+		if path != "-" {
+			*title = filepath.Base(path)
+		}
+	}
+
+	art := renderCode(string(src), lang, *title, *tabWidth, *lineNumbers, *showColors)
+	card := art.Plain
+	if *format == "ansi" || *format == "" && isatty.IsTerminal(os.Stdout.Fd()) {
+		card = art.ANSI
+	}
+	switch *format {
+	case "", "text", "ansi":
+	case "ans":
+		card = ansArt(art.ANSI)
+	case "html":
+		card = art.HTML
+	case "svg":
+		card = art.SVG
+	default:
+		fmt.Printf("Error: unknown format %q (use text, ansi, ans, html or svg)\n", *format)
+		os.Exit(1)
+	}
+
+	if write, dest, ok := findOutputTarget(*output); ok {
+		if err := write(dest, art); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Code card sent to: %s\n", *output)
+		return
+	}
+	if *output != "" {
+		if err := saveToFile(*output, card+"\n"); err != nil {
+			fmt.Printf("Error saving to file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Code card saved to: %s\n", *output)
+		return
+	}
+	fmt.Println(card)
+}
+
+// A code card is for a snippet; longer files would not fit a screen
+const (
+	maxCodeLines = 200
+	maxCodeSize  = 64 * 1024
+)
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"ascii-art/asciiart"
+)
+
+// blockWidth returns the width of the widest line in block
+func blockWidth(block []string) int {
+	width := 0
+	for _, line := range block {
+		width = max(width, asciiart.DisplayWidth(line))
+	}
+	return width
+}
+
+// padRight pads line with spaces to width columns
+func padRight(line string, width int) string {
+	if n := asciiart.DisplayWidth(line); n < width {
+		return line + strings.Repeat(" ", width-n)
+	}
+	return line
+}
+
+// centerLine centers line within width columns
+func centerLine(line string, width int) string {
+	n := asciiart.DisplayWidth(line)
+	if n >= width {
+		return line
+	}
+	left := (width - n) / 2
+	return strings.Repeat(" ", left) + line + strings.Repeat(" ", width-n-left)
+}
+
+// joinHorizontal places blocks of lines side by side, top aligned, with
+// gap spaces between them.
+func joinHorizontal(blocks [][]string, gap int) []string {
+	height := 0
+	for _, block := range blocks {
+		height = max(height, len(block))
+	}
+
+	lines := make([]string, height)
+	for i, block := range blocks {
+		width := blockWidth(block)
+		for row := 0; row < height; row++ {
+			cell := ""
+			if row < len(block) {
+				cell = block[row]
+			}
+			if i > 0 {
+				lines[row] += strings.Repeat(" ", gap)
+			}
+			lines[row] += padRight(cell, width)
+		}
+	}
+
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	return lines
+}
+
+// -align values
+const (
+	alignLeft   = "left"
+	alignCenter = "center"
+	alignRight  = "right"
+)
+
+func checkAlign(align string) error {
+	switch align {
+	case alignLeft, alignCenter, alignRight:
+		return nil
+	}
+	return fmt.Errorf("unknown alignment %q (use left, center or right)", align)
+}
+
+// withAlign returns style with the finished art centered or right-aligned
+// as a whole within the width width returns, measured at each render
+func withAlign(style asciiart.Style, align string, width func() int) asciiart.Style {
+	previous := style.Decorator.Post
+	style.Decorator.Post = func(art string) string {
+		if previous != nil {
+			art = previous(art)
+		}
+		return alignBlock(art, align, width())
+	}
+	return style
+}
+
+// alignBlock indents every line of art by the same amount, so the lines
+// stay lined up with each other, to center or right-align the block
+// within width. Art as wide as that, or wider, is left as it is.
+func alignBlock(art, align string, width int) string {
+	lines := strings.Split(art, "\n")
+	room := width - blockWidth(lines)
+	if room <= 0 || align == alignLeft {
+		return art
+	}
+	if align == alignCenter {
+		room /= 2
+	}
+	indent := strings.Repeat(" ", room)
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
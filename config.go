@@ -0,0 +1,252 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+const configFileName = "config.yaml"
+
+// UserConfig is the per-user settings file, ~/.config/asciiart/config.yaml
+type UserConfig struct {
+	Category    int           `yaml:"category"`
+	Style       int           `yaml:"style"`
+	ColorScheme int           `yaml:"colorscheme"`
+	NoColor     bool          `yaml:"no_color,omitempty"`
+	Format      string        `yaml:"format,omitempty"`
+	Clocks      []ClockConfig `yaml:"clocks,omitempty"`
+	Filter      FilterConfig  `yaml:"filter,omitempty"`
+	Hooks       []HookConfig  `yaml:"hooks,omitempty"`
+	Signature   string        `yaml:"signature,omitempty"` // Default -signature
+
+	TemplateExec []string `yaml:"template_exec,omitempty"` // Commands {exec:...} may run
+
+	Decorators   []DecoratorConfig `yaml:"decorators,omitempty"`
+	ColorSchemes []ThemeFile       `yaml:"colorschemes,omitempty"`
+}
+
+// DecoratorConfig is a custom bordered style defined in the config file.
+// Bottom defaults to Top, Right to Left, and the corners to Top. Chars
+// sets all of them at once in the -border-chars format.
+type DecoratorConfig struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Font        string   `yaml:"font,omitempty"`
+	Chars       string   `yaml:"chars,omitempty"`
+	Top         string   `yaml:"top,omitempty"`
+	Bottom      string   `yaml:"bottom,omitempty"`
+	Left        string   `yaml:"left,omitempty"`
+	Right       string   `yaml:"right,omitempty"`
+	Corners     []string `yaml:"corners,omitempty"` // TL, TR, BL, BR, or one for all four
+	Fill        string   `yaml:"fill,omitempty"`
+	Pre         string   `yaml:"pre,omitempty"`  // Effect applied before the border
+	Post        string   `yaml:"post,omitempty"` // Effect applied after the border
+}
+
+// withBraille returns style with its art redrawn in Braille dots ahead of
+// its other effects and border, as for -braille
+func withBraille(style asciiart.Style) asciiart.Style {
+	previous := style.Decorator.Pre
+	style.Decorator.Pre = func(art string) string {
+		art = asciiart.Braille(art)
+		if previous != nil {
+			art = previous(art)
+		}
+		return art
+	}
+	return style
+}
+
+const customCategory = "Custom"
+
+func userConfigPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configFileName), nil
+}
+
+// loadUserConfig reads the system-wide config files followed by the
+// per-user one, so user settings override org defaults. It returns
+// fs.ErrNotExist when the user has no config yet.
+func loadUserConfig() (*UserConfig, error) {
+	path, err := userConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &UserConfig{}
+	dirs := systemAssetDirs()
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := cfg.merge(filepath.Join(dirs[i], configFileName)); err != nil && !isNotExist(err) {
+			return nil, err
+		}
+	}
+	if err := cfg.merge(path); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// merge overlays the settings present in the file at path onto cfg.
+// Custom decorators, color schemes, filter words, hooks and template_exec
+// commands add to those of earlier files.
+func (cfg *UserConfig) merge(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	decorators, schemes, words, hooks, commands := cfg.Decorators, cfg.ColorSchemes, cfg.Filter.Words, cfg.Hooks, cfg.TemplateExec
+	cfg.Decorators, cfg.ColorSchemes, cfg.Filter.Words, cfg.Hooks, cfg.TemplateExec = nil, nil, nil, nil, nil
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	cfg.Decorators = append(decorators, cfg.Decorators...)
+	cfg.ColorSchemes = append(schemes, cfg.ColorSchemes...)
+	cfg.Filter.Words = append(words, cfg.Filter.Words...)
+	cfg.Hooks = append(hooks, cfg.Hooks...)
+	cfg.TemplateExec = append(commands, cfg.TemplateExec...)
+	return nil
+}
+
+func (cfg *UserConfig) save() (string, error) {
+	path, err := userConfigPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, data, 0644)
+}
+
+// applyDefaults fills in flags the user did not set on the command line
+func (cfg *UserConfig) applyDefaults(set map[string]bool, showColors *bool, categoryFlag, styleFlag, colorFlag *int, formatFlag, signatureFlag *string) {
+	if !set["category"] && !set["style"] && cfg.Category > 0 {
+		*categoryFlag, *styleFlag = cfg.Category, cfg.Style
+	}
+	if !set["colorscheme"] && cfg.ColorScheme > 0 {
+		*colorFlag = cfg.ColorScheme
+	}
+	if !set["color"] && cfg.NoColor {
+		*showColors = false
+	}
+	if !set["format"] && cfg.Format != "" {
+		*formatFlag = cfg.Format
+	}
+	if !set["signature"] && cfg.Signature != "" {
+		*signatureFlag = cfg.Signature
+	}
+}
+
+// customize adds the config file's color schemes, filter words, hooks
+// and template_exec commands, and its decorators as styles of a Custom category after the
+// built-in ones. Invalid entries are skipped with a warning.
+func (cfg *UserConfig) customize(config *AppConfig) {
+	config.filterConfig = cfg.Filter
+	config.templateExec = cfg.TemplateExec
+
+	for _, h := range cfg.Hooks {
+		hook, err := h.hook()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, color.YellowString("Warning: hook %q: %v", cmp.Or(h.Name, h.Command), err))
+			continue
+		}
+		config.hooks = append(config.hooks, hook)
+	}
+
+	for _, theme := range cfg.ColorSchemes {
+		scheme, err := theme.colorScheme()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, color.YellowString("Warning: color scheme %q: %v", theme.Name, err))
+			continue
+		}
+		config.addColorScheme(scheme)
+	}
+
+	custom := asciiart.StyleCategory{Name: customCategory, Description: "Styles from your config file"}
+	config.customStyles = make(map[string]DecoratorConfig)
+	for _, d := range cfg.Decorators {
+		if d.Name == "" {
+			fmt.Fprintln(os.Stderr, color.YellowString("Warning: skipping a custom decorator without a name"))
+			continue
+		}
+		style, err := d.style()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, color.YellowString("Warning: decorator %q: %v", d.Name, err))
+			continue
+		}
+		custom.Styles = append(custom.Styles, style)
+		config.customStyles[d.Name] = d
+	}
+	if len(custom.Styles) > 0 {
+		config.categories = append(config.categories, custom)
+	}
+}
+
+func (d DecoratorConfig) style() (asciiart.Style, error) {
+	if d.Chars != "" {
+		pieces, err := parseBorderChars(d.Chars)
+		if err != nil {
+			return asciiart.Style{}, err
+		}
+		d.Top, d.Bottom, d.Left, d.Right = pieces[0], pieces[0], pieces[1], pieces[1]
+		d.Corners = pieces[2:]
+	}
+
+	decorator := asciiart.Decorator{Top: d.Top, Bottom: d.Bottom, Left: d.Left, Right: d.Right, Fill: d.Fill}
+	if decorator.Bottom == "" {
+		decorator.Bottom = decorator.Top
+	}
+	if decorator.Right == "" {
+		decorator.Right = decorator.Left
+	}
+	switch len(d.Corners) {
+	case 0:
+		decorator.Corners = [4]string{d.Top, d.Top, d.Top, d.Top}
+	case 1:
+		decorator.Corners = [4]string{d.Corners[0], d.Corners[0], d.Corners[0], d.Corners[0]}
+	default:
+		copy(decorator.Corners[:], d.Corners)
+	}
+
+	for _, effect := range []struct {
+		name   string
+		target *func(string) string
+	}{
+		{d.Pre, &decorator.Pre},
+		{d.Post, &decorator.Post},
+	} {
+		if effect.name == "" {
+			continue
+		}
+		fn, err := findEffect(effect.name)
+		if err != nil {
+			return asciiart.Style{}, err
+		}
+		*effect.target = effectStep(effect.name, fn)
+	}
+
+	description := d.Description
+	if description == "" {
+		description = "Custom decorator"
+	}
+	return asciiart.Style{Name: d.Name, Description: description, Font: d.Font, Decorator: decorator}, nil
+}
+
+func isNotExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"ascii-art/asciiart"
+)
+
+// -bubble kinds
+const (
+	bubbleSay   = "say"
+	bubbleThink = "think"
+)
+
+const defaultFigure = "cow"
+
+// builtinFigures are drawn under the bubble without any .cow files. As
+// in .cow files, $thoughts is the trail of the bubble and $eyes and
+// $tongue are filled in.
+var builtinFigures = map[string]string{
+	"cow": `        $thoughts   ^__^
+         $thoughts  ($eyes)\_______
+            (__)\       )\/\
+             $tongue ||----w |
+                ||     ||`,
+	"tux": `   $thoughts
+    $thoughts
+        .--.
+       |o_o |
+       |:_/ |
+      //   \ \
+     (|     | )
+    /'\_   _/` + "`" + `\
+    \___)=(___/`,
+}
+
+// cowDirs lists where .cow files are looked for: the cows directory of
+// every asset directory, then COWPATH and the usual cowsay install paths
+func cowDirs() []string {
+	var dirs []string
+	for _, dir := range assetDirs() {
+		dirs = append(dirs, filepath.Join(dir, assetCows))
+	}
+	dirs = append(dirs, filepath.SplitList(os.Getenv("COWPATH"))...)
+	return append(dirs, "/usr/share/cowsay/cows", "/usr/share/games/cowsay/cows", "/usr/local/share/cows")
+}
+
+// Bubble puts art in a speech or thought bubble spoken by a figure
+type Bubble struct {
+	think  bool
+	figure string // Template as in a .cow file, already unescaped
+}
+
+// newBubble prepares a bubble of kind say or think for figure: a built-in
+// figure, a .cow file name from cowDirs, or a path to a .cow file
+func newBubble(kind, figure string) (*Bubble, error) {
+	if kind != bubbleSay && kind != bubbleThink {
+		return nil, fmt.Errorf("unknown bubble %q (use say or think)", kind)
+	}
+	template, err := loadFigure(figure)
+	if err != nil {
+		return nil, err
+	}
+	return &Bubble{think: kind == bubbleThink, figure: template}, nil
+}
+
+func loadFigure(name string) (string, error) {
+	if template, ok := builtinFigures[name]; ok {
+		return template, nil
+	}
+	path := name
+	if !strings.HasSuffix(name, ".cow") {
+		path = ""
+		for _, dir := range cowDirs() {
+			if p := filepath.Join(dir, name+".cow"); fileExists(p) {
+				path = p
+				break
+			}
+		}
+	}
+	if path == "" {
+		return "", fmt.Errorf("unknown figure %q (built in: %s, or a .cow file)", name, strings.Join(figureNames(), ", "))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	template, err := parseCowFile(string(data))
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", path, err)
+	}
+	return template, nil
+}
+
+func figureNames() []string {
+	names := make([]string, 0, len(builtinFigures))
+	for name := range builtinFigures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cowHeredoc matches the start of the figure in a .cow file, such as
+// $the_cow = <<"EOC";
+var cowHeredoc = regexp.MustCompile(`\$the_cow\s*=\s*<<\s*(["']?)(\w+)["']?\s*;?`)
+
+// cowEscapes undoes the Perl escapes of an interpolated heredoc
+var cowEscapes = strings.NewReplacer(`\\`, `\`, `\@`, `@`, `\$`, `$`)
+
+// parseCowFile extracts the figure from a cowsay .cow file, a Perl
+// snippet assigning a heredoc to $the_cow
+func parseCowFile(data string) (string, error) {
+	loc := cowHeredoc.FindStringSubmatchIndex(data)
+	if loc == nil {
+		return "", errors.New("no $the_cow heredoc")
+	}
+	quote, marker := data[loc[2]:loc[3]], data[loc[4]:loc[5]]
+	_, body, found := strings.Cut(data[loc[1]:], "\n")
+	if !found {
+		return "", errors.New("empty figure")
+	}
+
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if strings.TrimRight(line, "\r") == marker {
+			template := strings.Join(lines, "\n")
+			if quote != "'" {
+				template = cowEscapes.Replace(template)
+			}
+			return template, nil
+		}
+		lines = append(lines, strings.TrimRight(line, "\r"))
+	}
+	return "", fmt.Errorf("heredoc not closed with %s", marker)
+}
+
+// figureArt fills in the figure template
+func (b *Bubble) figureArt() string {
+	thoughts := `\`
+	if b.think {
+		thoughts = "o"
+	}
+	return strings.NewReplacer(
+		"${thoughts}", thoughts, "$thoughts", thoughts,
+		"${eyes}", "oo", "$eyes", "oo",
+		"${tongue}", "  ", "$tongue", "  ",
+	).Replace(b.figure)
+}
+
+// draw puts art in the bubble, with the figure underneath
+func (b *Bubble) draw(art string) string {
+	lines := strings.Split(art, "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, asciiart.DisplayWidth(line))
+	}
+
+	var out []string
+	out = append(out, " "+strings.Repeat("_", width+2))
+	for i, line := range lines {
+		left, right := "|", "|"
+		switch {
+		case b.think:
+			left, right = "(", ")"
+		case len(lines) == 1:
+			left, right = "<", ">"
+		case i == 0:
+			left, right = "/", `\`
+		case i == len(lines)-1:
+			left, right = `\`, "/"
+		}
+		out = append(out, left+" "+asciiart.PadToWidth(line, width)+" "+right)
+	}
+	out = append(out, " "+strings.Repeat("-", width+2))
+	return strings.Join(out, "\n") + "\n" + b.figureArt()
+}
+
+// withBubble returns style with its finished art, border and all, put in
+// bubble, as for -bubble
+func withBubble(style asciiart.Style, bubble *Bubble) asciiart.Style {
+	previous := style.Decorator.Post
+	style.Decorator.Post = func(art string) string {
+		if previous != nil {
+			art = previous(art)
+		}
+		return bubble.draw(art)
+	}
+	return style
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// SoundCue is rung when a long-running mode finishes
+type SoundCue struct {
+	bell  bool
+	sound string // Path to a sound file, empty for none
+}
+
+// addCueFlags adds -bell and -sound to flags; when says when they ring,
+// as in "when stopped"
+func addCueFlags(flags *flag.FlagSet, when string) *SoundCue {
+	cue := &SoundCue{}
+	flags.BoolVar(&cue.bell, "bell", false, "Ring the terminal bell "+when)
+	flags.StringVar(&cue.sound, "sound", "", "Sound file to play "+when)
+	return cue
+}
+
+func (c SoundCue) play() {
+	if c.bell {
+		fmt.Print("\a")
+	}
+	if c.sound != "" {
+		if err := playSound(c.sound); err != nil {
+			fmt.Fprintf(os.Stderr, "Error playing sound: %v\n", err)
+		}
+	}
+}
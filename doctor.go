@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// runDoctor reports what the tool detects about the terminal and shows
+// test patterns, to help debug broken borders or missing colors.
+func runDoctor(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	patterns := flags.Bool("patterns", true, "Show test patterns")
+	flags.Parse(args)
+
+	ok := color.GreenString("ok")
+	warn := func(format string, a ...any) string {
+		return color.YellowString(format, a...)
+	}
+	report := func(name, value, verdict string) {
+		fmt.Printf("%s %-28s %s\n", color.HiBlackString("%-14s", name+":"), value, verdict)
+	}
+
+	fmt.Println(color.CyanString("\nTerminal"))
+	if width, height, found := terminalSize(); found {
+		verdict := ok
+		if width < 80 {
+			verdict = warn("narrow; wide fonts will wrap")
+		}
+		report("Size", fmt.Sprintf("%dx%d", width, height), verdict)
+	} else {
+		report("Size", "unknown", warn("stdout is not a terminal"))
+	}
+	report("Stdin", ttyLabel(os.Stdin.Fd()), ok)
+	report("Stdout", ttyLabel(os.Stdout.Fd()), ok)
+	report("TERM", orUnset(os.Getenv("TERM")), ok)
+
+	fmt.Println(color.CyanString("\nColor"))
+	depth := detectColorDepth()
+	verdict := ok
+	switch depth {
+	case colorDepthNone:
+		verdict = warn("colors disabled (NO_COLOR set or not a terminal)")
+	case colorDepth16:
+		verdict = warn("only basic colors; set COLORTERM=truecolor if supported")
+	}
+	report("Color depth", colorDepthName(depth), verdict)
+	report("COLORTERM", orUnset(os.Getenv("COLORTERM")), ok)
+	report("NO_COLOR", orUnset(os.Getenv("NO_COLOR")), ok)
+
+	fmt.Println(color.CyanString("\nLocale"))
+	locale := currentLocale()
+	verdict = ok
+	if !unicodeSupported() {
+		verdict = warn("not UTF-8; box borders may show as '?'")
+	}
+	report("Locale", orUnset(locale), verdict)
+
+	fmt.Println(color.CyanString("\nFonts"))
+	missing := 0
+	for _, category := range config.categories {
+		for _, style := range category.Styles {
+			if style.Font == "" {
+				continue
+			}
+			if !renderer.FontExists(style.Font) {
+				report(style.Name, style.Font, color.RedString("font not found"))
+				missing++
+			}
+		}
+	}
+	if missing == 0 {
+		report("Styles", "all fonts present", ok)
+	}
+
+	if *patterns {
+		printTestPatterns()
+	}
+}
+
+func printTestPatterns() {
+	fmt.Println(color.CyanString("\nTest patterns"))
+	fmt.Println("Box drawing:  ┌─┬─┐ ╔═╦═╗ ╭─╮ ┈┊·")
+	fmt.Println("              └─┴─┘ ╚═╩═╝ ╰─╯")
+	fmt.Println("Shades:       ░▒▓█ ▀▄▌▐")
+	fmt.Println("Width check:  |漢字|  |😀|  (bars should line up below)")
+	fmt.Println("              |....|  |..|")
+
+	fmt.Print("16 colors:    ")
+	for i := 0; i < 16; i++ {
+		fmt.Printf("\033[48;5;%dm  ", i)
+	}
+	fmt.Println("\033[0m")
+
+	fmt.Print("256 colors:   ")
+	for i := 16; i < 232; i += 6 {
+		fmt.Printf("\033[48;5;%dm ", i)
+	}
+	fmt.Println("\033[0m")
+
+	fmt.Print("Truecolor:    ")
+	for i := 0; i < 36; i++ {
+		fmt.Printf("\033[48;2;%d;%d;%dm ", 255-i*7, i*7, 128)
+	}
+	fmt.Println("\033[0m")
+	fmt.Println(strings.Repeat(" ", 14) + color.HiBlackString("(a smooth gradient means truecolor works)"))
+}
+
+func ttyLabel(fd uintptr) string {
+	if isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd) {
+		return "terminal"
+	}
+	return "redirected"
+}
+
+func colorDepthName(depth int) string {
+	switch depth {
+	case colorDepthNone:
+		return "none"
+	case colorDepth16:
+		return "16 colors"
+	case colorDepth256:
+		return "256 colors"
+	}
+	return "truecolor (24-bit)"
+}
+
+func orUnset(value string) string {
+	if value == "" {
+		return "(unset)"
+	}
+	return value
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Word filter modes
+const (
+	filterOff    = "off"
+	filterMask   = "mask"
+	filterReject = "reject"
+)
+
+// defaultFilterWords are masked or rejected by every filter; the config
+// file can add more
+var defaultFilterWords = []string{
+	"asshole", "bastard", "bitch", "bollocks", "bullshit", "cock", "cunt",
+	"dick", "fuck", "motherfucker", "piss", "shit", "slut", "twat",
+	"wanker", "whore",
+}
+
+// filterSuffixes are endings a flagged word may carry and still match,
+// so "fucking" is caught without flagging "cocktail" or "Dickens"
+var filterSuffixes = []string{"", "s", "es", "ed", "er", "ers", "in", "ing", "y", "ty", "head", "heads", "face"}
+
+// filterLookalikes undo common character swaps such as "sh1t" or "@ss"
+var filterLookalikes = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+// FilterConfig is the filter section of config.yaml
+type FilterConfig struct {
+	Words     []string `yaml:"words,omitempty"`      // Added to the built-in list
+	WordsFile string   `yaml:"words_file,omitempty"` // One word per line; # starts a comment
+}
+
+// WordFilter masks or rejects input containing flagged words, for
+// public-facing deployments
+type WordFilter struct {
+	mode  string
+	words map[string]bool
+}
+
+// newWordFilter builds a filter in mode from the built-in words and cfg.
+// It returns nil when mode is off.
+func newWordFilter(mode string, cfg FilterConfig) (*WordFilter, error) {
+	switch mode {
+	case filterOff:
+		return nil, nil
+	case filterMask, filterReject:
+	default:
+		return nil, fmt.Errorf("unknown filter mode %q (use mask, reject or off)", mode)
+	}
+
+	f := &WordFilter{mode: mode, words: make(map[string]bool)}
+	words := slices.Concat(defaultFilterWords, cfg.Words)
+	if cfg.WordsFile != "" {
+		file, err := os.Open(cfg.WordsFile)
+		if err != nil {
+			return nil, fmt.Errorf("filter words: %v", err)
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if word, _, _ := strings.Cut(scanner.Text(), "#"); strings.TrimSpace(word) != "" {
+				words = append(words, strings.TrimSpace(word))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("filter words: %v", err)
+		}
+	}
+	for _, word := range words {
+		f.words[strings.ToLower(word)] = true
+	}
+	return f, nil
+}
+
+// apply returns text with flagged words masked, or an error in reject
+// mode. A nil filter lets everything through.
+func (f *WordFilter) apply(text string) (string, error) {
+	if f == nil {
+		return text, nil
+	}
+
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		if f.flagged(string(runes[start:end])) {
+			if f.mode == filterReject {
+				return "", fmt.Errorf("text contains a filtered word")
+			}
+			for i := start + 1; i < end; i++ {
+				runes[i] = '*'
+			}
+		}
+		start = end
+	}
+	return string(runes), nil
+}
+
+func (f *WordFilter) flagged(word string) bool {
+	word = filterLookalikes.Replace(strings.ToLower(word))
+	for _, suffix := range filterSuffixes {
+		if stem, ok := strings.CutSuffix(word, suffix); ok && f.words[stem] {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '@' || r == '$'
+}
+
+// setFilter switches the word filter to mode, using the words from the
+// config file
+func (config *AppConfig) setFilter(mode string) {
+	filter, err := newWordFilter(mode, config.filterConfig)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	config.filter = filter
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWordFilterMask(t *testing.T) {
+	filter, err := newWordFilter(filterMask, FilterConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		text, want string
+	}{
+		{"Hello World", "Hello World"},
+		{"what the fuck", "what the f***"},
+		{"FUCK", "F***"},
+		{"fucking bitches", "f****** b******"},
+		{"shithead", "s*******"},
+		{"sh1t happens", "s*** happens"},
+		{"@55hole", "@******"},
+		{"bull$hit!", "b*******!"},
+		{"cocktail hour", "cocktail hour"},
+		{"Charles Dickens", "Charles Dickens"},
+		{"Scunthorpe", "Scunthorpe"},
+		{"class of 2024", "class of 2024"},
+	}
+	for _, test := range tests {
+		got, err := filter.apply(test.text)
+		if err != nil || got != test.want {
+			t.Errorf("mask %q = %q (%v), want %q", test.text, got, err, test.want)
+		}
+	}
+}
+
+func TestWordFilterReject(t *testing.T) {
+	filter, err := newWordFilter(filterReject, FilterConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"shit", "Sh1tty", "you wankers"} {
+		if got, err := filter.apply(text); err == nil {
+			t.Errorf("reject %q = %q, want an error", text, got)
+		}
+	}
+	for _, text := range []string{"cocktail", "Dickens", "pissarro"} {
+		if got, err := filter.apply(text); err != nil || got != text {
+			t.Errorf("reject %q = %q (%v), want it unchanged", text, got, err)
+		}
+	}
+}
+
+func TestWordFilterConfigWords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("# team words\nfrak  # from the show\n\nSmeg\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	filter, err := newWordFilter(filterMask, FilterConfig{Words: []string{"Gorram"}, WordsFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := filter.apply("gorram fraking smeg, team")
+	if want := "g***** f****** s***, team"; err != nil || got != want {
+		t.Errorf("mask = %q (%v), want %q", got, err, want)
+	}
+
+	if _, err := newWordFilter(filterMask, FilterConfig{WordsFile: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Errorf("newWordFilter accepted a missing words file")
+	}
+}
+
+func TestWordFilterOff(t *testing.T) {
+	filter, err := newWordFilter(filterOff, FilterConfig{})
+	if filter != nil || err != nil {
+		t.Fatalf("newWordFilter(off) = %v, %v; want nil, nil", filter, err)
+	}
+	if got, err := filter.apply("shit"); err != nil || got != "shit" {
+		t.Errorf("nil filter changed %q to %q (%v)", "shit", got, err)
+	}
+	if _, err := newWordFilter("strict", FilterConfig{}); err == nil {
+		t.Errorf("newWordFilter accepted an unknown mode")
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ascii-art/asciiart"
+	"github.com/mattn/go-isatty"
+)
+
+// The randomart field is OpenSSH's: 17 by 9 cells, walked from the
+// middle
+const (
+	randomartWidth  = 17
+	randomartHeight = 9
+)
+
+// randomartSymbols are drawn for cells visited 0 to 14 times; the last
+// two mark where the walk starts and ends
+const randomartSymbols = " .o+=*BOX@%&#/^SE"
+
+// sshFrame is the plain ASCII frame ssh-keygen draws
+var sshFrame = asciiart.Decorator{
+	Top: "-", Bottom: "-", Left: "|", Right: "|",
+	Corners: [4]string{"+", "+", "+", "+"},
+}
+
+// randomart is the "drunken bishop" walk ssh-keygen -lv draws: each byte,
+// two bits at a time from the lowest, moves diagonally one cell, and the
+// cells are drawn by how often they were visited. Similar keys look alike
+// only when they are the same, so two digests can be compared at a glance.
+func randomart(digest []byte) string {
+	var field [randomartWidth][randomartHeight]int
+	x, y := randomartWidth/2, randomartHeight/2
+	top := len(randomartSymbols) - 1
+	for _, b := range digest {
+		for range 4 {
+			if b&1 != 0 {
+				x++
+			} else {
+				x--
+			}
+			if b&2 != 0 {
+				y++
+			} else {
+				y--
+			}
+			x, y = max(0, min(x, randomartWidth-1)), max(0, min(y, randomartHeight-1))
+			if field[x][y] < top-2 {
+				field[x][y]++
+			}
+			b >>= 2
+		}
+	}
+	field[randomartWidth/2][randomartHeight/2] = top - 1
+	field[x][y] = top
+
+	var rows []string
+	for row := range randomartHeight {
+		var line strings.Builder
+		for col := range randomartWidth {
+			line.WriteByte(randomartSymbols[field[col][row]])
+		}
+		rows = append(rows, line.String())
+	}
+	return strings.Join(rows, "\n")
+}
+
+// randomartFrame draws a decorator's pieces tight around the field, with
+// labels such as [ED25519 256] and [SHA256] let into the top and bottom
+// edges the way ssh-keygen does
+type randomartFrame struct {
+	d             asciiart.Decorator
+	title, footer string
+}
+
+func (f randomartFrame) Measure(width, height int) asciiart.BorderMetrics {
+	d := f.d
+	m := asciiart.BorderMetrics{
+		Left:  max(asciiart.DisplayWidth(d.Left), asciiart.DisplayWidth(d.Corners[asciiart.TopLeft]), asciiart.DisplayWidth(d.Corners[asciiart.BottomLeft])),
+		Right: max(asciiart.DisplayWidth(d.Right), asciiart.DisplayWidth(d.Corners[asciiart.TopRight]), asciiart.DisplayWidth(d.Corners[asciiart.BottomRight])),
+		Inner: width,
+	}
+	for m.Inner%max(1, asciiart.DisplayWidth(d.Top)) != 0 || m.Inner%max(1, asciiart.DisplayWidth(d.Bottom)) != 0 {
+		m.Inner++
+	}
+	return m
+}
+
+func (f randomartFrame) Top(width int) string       { return labeledEdge(f.d.Top, f.title, width) }
+func (f randomartFrame) Bottom(width int) string    { return labeledEdge(f.d.Bottom, f.footer, width) }
+func (f randomartFrame) Left(int) string            { return f.d.Left }
+func (f randomartFrame) Right(int) string           { return f.d.Right }
+func (f randomartFrame) Corner(position int) string { return f.d.Corners[position] }
+
+// labeledEdge is an edge of width cells with "[label]" centered in it,
+// the label cut short if it does not fit
+func labeledEdge(piece, label string, width int) string {
+	if label == "" {
+		return asciiart.FillWidth(piece, width)
+	}
+	label = "[" + label + "]"
+	if runes := []rune(label); asciiart.DisplayWidth(label) > width {
+		label = string(runes[:max(1, width-1)]) + "]"
+	}
+	lw := asciiart.DisplayWidth(label)
+	before := (width - lw) / 2
+	return asciiart.FillWidth(piece, before) + label + asciiart.FillWidth(piece, width-before-lw)
+}
+
+// digestNames are the usual hashes by digest length, for the footer
+var digestNames = map[int]string{16: "MD5", 20: "SHA1", 32: "SHA256", 48: "SHA384", 64: "SHA512"}
+
+// parseFingerprint reads a digest in hex, with or without colons as in
+// MD5:aa:bb:..., or in OpenSSH's SHA256:base64 form, and returns it with
+// the hash's name. A line from ssh-keygen -l such as
+// "256 SHA256:... user@host (ED25519)" also gives the key's type and size.
+func parseFingerprint(input string) (digest []byte, hash, title string, err error) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return nil, "", "", errors.New("no fingerprint given")
+	}
+	value := fields[0]
+	if len(fields) >= 2 && strings.Contains(fields[1], ":") {
+		value = fields[1]
+		if last := fields[len(fields)-1]; len(fields) >= 3 && strings.HasPrefix(last, "(") && strings.HasSuffix(last, ")") {
+			title = strings.Trim(last, "()") + " " + fields[0]
+		}
+	}
+
+	if name, rest, ok := strings.Cut(value, ":"); ok && !isHex(name) {
+		hash, value = strings.ToUpper(name), rest
+	}
+	clean := strings.ReplaceAll(value, ":", "")
+	if isHex(clean) && len(clean)%2 == 0 {
+		digest, _ = hex.DecodeString(clean)
+	} else if digest, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(value, "=")); err != nil {
+		return nil, "", "", fmt.Errorf("%q is neither hex nor base64", value)
+	}
+	if len(digest) == 0 {
+		return nil, "", "", errors.New("empty fingerprint")
+	}
+	if hash == "" {
+		hash = digestNames[len(digest)]
+	}
+	return digest, hash, title, nil
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// renderFingerprint draws the randomart for digest in frame, colored with
+// scheme if it is set: the walk in its primary color, the frame in its
+// secondary one
+func renderFingerprint(digest []byte, frame randomartFrame, scheme *asciiart.ColorScheme) RenderedArt {
+	field := randomart(digest)
+	plain := asciiart.DrawBorder(field, frame, "")
+	text := hex.EncodeToString(digest)
+	if scheme == nil {
+		return RenderedArt{Text: text, Plain: plain, ANSI: plain, HTML: asciiart.HTML(plain, nil), SVG: asciiart.SVG(plain, nil)}
+	}
+	// Rows are the left piece, the field row, then any filler and the
+	// right piece, as DrawBorder lays them out
+	forced := scheme.Forced()
+	rows := strings.Split(field, "\n")
+	left := asciiart.PadToWidth(frame.d.Left, frame.Measure(randomartWidth, randomartHeight).Left)
+	lines := strings.Split(plain, "\n")
+	for i, line := range lines {
+		if i == 0 || i > len(rows) {
+			lines[i] = forced.Secondary.Sprint(line)
+			continue
+		}
+		body := len(left) + len(rows[i-1])
+		lines[i] = forced.Secondary.Sprint(left) + forced.Primary.Sprint(line[len(left):body]) + forced.Secondary.Sprint(line[body:])
+	}
+	ansi := strings.Join(lines, "\n")
+	canvas := asciiart.ParseANSI(ansi, 0)
+	return RenderedArt{Text: text, Plain: plain, ANSI: ansi, HTML: asciiart.HTML(plain, canvas), SVG: asciiart.SVG(plain, canvas)}
+}
+
+// runFingerprint is the fingerprint command: the randomart of a key
+// fingerprint or digest, given or read from stdin, such as the output of
+// ssh-keygen -l or sha256sum
+func runFingerprint(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("fingerprint", flag.ExitOnError)
+	title := flags.String("title", "", "Label on the top edge, such as \"ED25519 256\" (default: from ssh-keygen -l output)")
+	hashName := flags.String("hash", "", "Label on the bottom edge (default: the hash, such as SHA256)")
+	styleName := flags.String("style", "", "Take the frame from this bordered style, by name or category.style number (default: ssh-keygen's)")
+	borderChars := flags.String("border-chars", "", "Frame pieces as \"horizontal,vertical,TL,TR,BL,BR\"")
+	colorName := flags.String("colorscheme", "", "Color scheme, by number or name")
+	format := flags.String("format", "", "Output format: text, ansi, ans, html or svg (default: colors on a terminal)")
+	output := flags.String("output", "", "Save to this file, or send to an output target, instead of printing")
+	showColors := flags.Bool("color", true, "Enable colored output")
+	rest := parseInterspersed(flags, args)
+	if len(rest) == 0 && isatty.IsTerminal(os.Stdin.Fd()) {
+		fmt.Println("Usage: ascii-art fingerprint [-title TEXT] [-hash NAME] [-style NAME] [-colorscheme NAME] [-format FORMAT] [-output FILE] FINGERPRINT|-")
+		os.Exit(1)
+	}
+
+	input := strings.Join(rest, " ")
+	if input == "" || input == "-" {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			fmt.Printf("Error reading fingerprint: %v\n", err)
+			os.Exit(1)
+		}
+		input = line
+	}
+	digest, hash, keyTitle, err := parseFingerprint(input)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	frame := randomartFrame{d: sshFrame, title: keyTitle, footer: hash}
+	if *title != "" {
+		frame.title = *title
+	}
+	if *hashName != "" {
+		frame.footer = *hashName
+	}
+	if *styleName != "" {
+		_, style, err := config.findStyle(*styleName)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		d := style.Decorator
+		if d.Top == "" || d.Left == "" || d.Border != nil {
+			fmt.Printf("Error: style %q has no frame to use (pick a bordered style, or -border-chars)\n", *styleName)
+			os.Exit(1)
+		}
+		frame.d = asciiart.Decorator{Top: d.Top, Bottom: d.Bottom, Left: d.Left, Right: d.Right, Corners: d.Corners}
+	}
+	if *borderChars != "" {
+		p, err := parseBorderChars(*borderChars)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		frame.d = asciiart.Decorator{Top: p[0], Bottom: p[0], Left: p[1], Right: p[1], Corners: [4]string{p[2], p[3], p[4], p[5]}}
+	}
+
+	var scheme *asciiart.ColorScheme
+	if *showColors && *colorName != "" {
+		scheme, err = config.findColorScheme(*colorName)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	art := renderFingerprint(digest, frame, scheme)
+	out := art.Plain
+	if *format == "ansi" || *format == "" && isatty.IsTerminal(os.Stdout.Fd()) {
+		out = art.ANSI
+	}
+	switch *format {
+	case "", "text", "ansi":
+	case "ans":
+		out = ansArt(art.ANSI)
+	case "html":
+		out = art.HTML
+	case "svg":
+		out = art.SVG
+	default:
+		fmt.Printf("Error: unknown format %q (use text, ansi, ans, html or svg)\n", *format)
+		os.Exit(1)
+	}
+
+	if write, dest, ok := findOutputTarget(*output); ok {
+		if err := write(dest, art); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Randomart sent to: %s\n", *output)
+		return
+	}
+	if *output != "" {
+		if err := saveToFile(*output, out+"\n"); err != nil {
+			fmt.Printf("Error saving to file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Randomart saved to: %s\n", *output)
+		return
+	}
+	fmt.Println(out)
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+const (
+	fontSnapshotDir = "font-snapshots"
+	fontSample      = "Hello World 123"
+	fontListSample  = "Abc"
+)
+
+// FontSnapshot records how every available font rendered at one version
+type FontSnapshot struct {
+	Version string                       `json:"version"`
+	Created time.Time                    `json:"created"`
+	Sample  string                       `json:"sample"`
+	Samples map[string]string            `json:"samples"`
+	Glyphs  map[string]map[string]string `json:"glyphs"`
+}
+
+// installedFonts returns the names of the font assets, sorted
+func installedFonts() []string {
+	var installed []string
+	for _, ext := range []string{".flf", ".json"} {
+		for name := range listAssets(assetFonts, ext) {
+			installed = append(installed, name)
+		}
+	}
+	sort.Strings(installed)
+	return installed
+}
+
+// listFonts prints every font -font accepts, the installed ones (font
+// assets) after the built-in ones, each with sample rendered in it
+func listFonts(sample string) {
+	installed := installedFonts()
+	for _, group := range []struct {
+		title string
+		fonts []string
+	}{
+		{"Built-in fonts", asciiart.BuiltinFonts()},
+		{"Installed fonts", installed},
+	} {
+		if len(group.fonts) == 0 {
+			continue
+		}
+		fmt.Println(color.BlueString("\n%s:", group.title))
+		for _, font := range group.fonts {
+			fmt.Printf("\n%s\n%s\n", color.CyanString(font), renderer.Figure(sample, font))
+		}
+	}
+}
+
+// checkFont reports an error for a font that cannot be found
+func checkFont(name string) error {
+	if !renderer.FontExists(name) {
+		return fmt.Errorf("unknown font %q (-list-fonts shows them)", name)
+	}
+	return nil
+}
+
+// snapshotFonts lists the fonts used by styles plus any asset fonts
+func (config *AppConfig) snapshotFonts() []string {
+	seen := make(map[string]bool)
+	for _, category := range config.categories {
+		for _, style := range category.Styles {
+			if style.Font != "" {
+				seen[style.Font] = true
+			}
+		}
+	}
+	for _, ext := range []string{".flf", ".json"} {
+		for name := range listAssets(assetFonts, ext) {
+			seen[name] = true
+		}
+	}
+
+	var fonts []string
+	for name := range seen {
+		if renderer.FontExists(name) {
+			fonts = append(fonts, name)
+		}
+	}
+	sort.Strings(fonts)
+	return fonts
+}
+
+func (config *AppConfig) takeFontSnapshot(sample string) FontSnapshot {
+	snapshot := FontSnapshot{
+		Version: buildInfo().Version,
+		Created: time.Now(),
+		Sample:  sample,
+		Samples: make(map[string]string),
+		Glyphs:  make(map[string]map[string]string),
+	}
+	for _, font := range config.snapshotFonts() {
+		snapshot.Samples[font] = renderer.Figure(sample, font)
+		glyphs := make(map[string]string)
+		for r := ' ' + 1; r <= '~'; r++ {
+			glyphs[string(r)] = renderer.Figure(string(r), font)
+		}
+		snapshot.Glyphs[font] = glyphs
+	}
+	return snapshot
+}
+
+func fontSnapshotPath(name string) (string, error) {
+	// Anything that looks like a path is used as is
+	if strings.ContainsRune(name, os.PathSeparator) || strings.HasSuffix(name, ".json") {
+		return name, nil
+	}
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fontSnapshotDir, name+".json"), nil
+}
+
+func (snapshot FontSnapshot) save() (string, error) {
+	path, err := fontSnapshotPath(snapshot.Version)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, data, 0644)
+}
+
+func loadFontSnapshot(name string) (FontSnapshot, error) {
+	var snapshot FontSnapshot
+	path, err := fontSnapshotPath(name)
+	if err != nil {
+		return snapshot, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("%s: %v", path, err)
+	}
+	return snapshot, nil
+}
+
+// runFonts snapshots the current font set or compares it with an
+// earlier snapshot
+func runFonts(config *AppConfig, args []string) {
+	if len(args) == 0 || args[0] == "list" {
+		// Like -list-fonts, with any text after list as the sample
+		sample := fontListSample
+		if len(args) > 1 {
+			sample = strings.Join(args[1:], " ")
+		}
+		listFonts(sample)
+		return
+	}
+
+	flags := flag.NewFlagSet("fonts "+args[0], flag.ExitOnError)
+	sample := flags.String("sample", fontSample, "Sample text rendered in every font")
+	against := flags.String("against", "", "Snapshot version or file to compare with")
+	flags.Parse(args[1:])
+
+	switch args[0] {
+	case "snapshot":
+		path, err := config.takeFontSnapshot(*sample).save()
+		if err != nil {
+			fmt.Printf("Error saving snapshot: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Font snapshot saved to: %s\n", path)
+	case "diff":
+		if *against == "" {
+			fmt.Println("Error: fonts diff needs -against <version|file.json>")
+			os.Exit(1)
+		}
+		old, err := loadFontSnapshot(*against)
+		if err != nil {
+			fmt.Printf("Error reading snapshot: %v\n", err)
+			os.Exit(1)
+		}
+		current := config.takeFontSnapshot(old.Sample)
+		if !diffFontSnapshots(old, current) {
+			fmt.Println(color.GreenString("No glyph changes since %s.", old.Version))
+		}
+	default:
+		fmt.Printf("Error: unknown fonts command %q (use list, snapshot or diff)\n", args[0])
+		os.Exit(1)
+	}
+}
+
+// diffFontSnapshots prints every font whose glyphs changed and reports
+// whether there were any differences
+func diffFontSnapshots(old, current FontSnapshot) bool {
+	fonts := make(map[string]bool)
+	for font := range old.Glyphs {
+		fonts[font] = true
+	}
+	for font := range current.Glyphs {
+		fonts[font] = true
+	}
+	var names []string
+	for font := range fonts {
+		names = append(names, font)
+	}
+	sort.Strings(names)
+
+	changed := false
+	for _, font := range names {
+		before, hadBefore := old.Glyphs[font]
+		after, hasAfter := current.Glyphs[font]
+		switch {
+		case !hadBefore:
+			fmt.Println(color.GreenString("\n+ %s (new in %s)", font, current.Version))
+			changed = true
+			continue
+		case !hasAfter:
+			fmt.Println(color.RedString("\n- %s (removed since %s)", font, old.Version))
+			changed = true
+			continue
+		}
+
+		var glyphs []string
+		for r := ' ' + 1; r <= '~'; r++ {
+			if before[string(r)] != after[string(r)] {
+				glyphs = append(glyphs, string(r))
+			}
+		}
+		if len(glyphs) == 0 {
+			continue
+		}
+		changed = true
+
+		fmt.Printf("\n%s %s: %s\n", color.YellowString("~"), color.CyanString(font), strings.Join(glyphs, " "))
+		fmt.Println(color.HiBlackString("%s:", old.Version))
+		fmt.Println(old.Samples[font])
+		fmt.Println(color.HiBlackString("%s:", current.Version))
+		fmt.Println(highlightChanges(old.Samples[font], current.Samples[font]))
+	}
+	return changed
+}
+
+// highlightChanges colors every cell of after that differs from before
+func highlightChanges(before, after string) string {
+	oldLines := strings.Split(before, "\n")
+	var result strings.Builder
+	for i, line := range strings.Split(after, "\n") {
+		if i > 0 {
+			result.WriteString("\n")
+		}
+		var oldLine []rune
+		if i < len(oldLines) {
+			oldLine = []rune(oldLines[i])
+		}
+		for j, r := range []rune(line) {
+			if j < len(oldLine) && oldLine[j] == r {
+				result.WriteRune(r)
+			} else {
+				result.WriteString(color.New(color.FgRed, color.Bold).Sprint(string(r)))
+			}
+		}
+	}
+	return result.String()
+}
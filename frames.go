@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// frameLimiter paces live and animated output for low-power displays: it
+// caps the frame rate, and keeps the time spent drawing under a share of
+// one CPU core by resting in proportion to how long each frame took.
+type frameLimiter struct {
+	interval time.Duration // Shortest time between frames; 0 for no cap
+	budget   float64       // Share of a core frames may use; 0 for no cap
+	start    time.Time
+}
+
+// addFrameFlags registers -fps and -cpu-limit on flags
+func addFrameFlags(flags *flag.FlagSet) (fps, cpuLimit *float64) {
+	fps = flags.Float64("fps", 0, "Maximum frames per second (0 for no cap)")
+	cpuLimit = flags.Float64("cpu-limit", 0, "Percent of one CPU core drawing may use (0 for no cap)")
+	return fps, cpuLimit
+}
+
+func newFrameLimiter(fps, cpuLimit float64) (*frameLimiter, error) {
+	if fps < 0 {
+		return nil, fmt.Errorf("-fps must not be negative, got %g", fps)
+	}
+	if cpuLimit < 0 || cpuLimit > 100 {
+		return nil, fmt.Errorf("-cpu-limit must be a percentage from 0 to 100, got %g", cpuLimit)
+	}
+	l := &frameLimiter{budget: cpuLimit / 100}
+	if fps > 0 {
+		l.interval = time.Duration(float64(time.Second) / fps)
+	}
+	return l, nil
+}
+
+// mustFrameLimiter is newFrameLimiter for flag values, exiting on errors
+func mustFrameLimiter(fps, cpuLimit float64) *frameLimiter {
+	l, err := newFrameLimiter(fps, cpuLimit)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return l
+}
+
+// begin marks the start of drawing a frame
+func (l *frameLimiter) begin() {
+	l.start = time.Now()
+}
+
+// next returns how long to wait after a frame before drawing the next
+// one, given the period the mode itself wants
+func (l *frameLimiter) next(period time.Duration) time.Duration {
+	work := time.Since(l.start)
+	wait := max(period, l.interval) - work
+	if l.budget > 0 {
+		wait = max(wait, time.Duration(float64(work)*(1/l.budget-1)))
+	}
+	return max(wait, 0)
+}
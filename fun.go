@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+// FunPreset is a ready-made look for classrooms and demos: a font, an
+// emoji border, a mascot line under the banner and a bright gradient
+type FunPreset struct {
+	Name        string
+	Description string
+	Font        string
+	Border      string
+	Corner      string
+	Mascot      string
+	Gradient    string // Preset name or hex stops, as for -gradient
+}
+
+var funPresets = []FunPreset{
+	{"party", "Balloons and confetti", "big", "🎈", "🎉", "🥳  🎊  🪅  🎊  🥳", "pride"},
+	{"birthday", "Cake and presents", "big", "🎁", "🎂", "🍰  🎈  🧁  🎈  🍰", "#ff69b4:#ffd700:#00bfff"},
+	{"space", "Rockets and planets", "slant", "⭐", "🪐", "🚀  🌙  👽  🌙  🚀", "#8a2be2:#00bfff:#e0ffff"},
+	{"dino", "Dinosaurs and volcanoes", "big", "🌿", "🌋", "🦕  🦖  🥚  🦖  🦕", "#32cd32:#adff2f:#ffa500"},
+}
+
+// funCategory groups the presets in usage stats
+var funCategory = asciiart.StyleCategory{Name: "Fun", Description: "Playful presets for kids"}
+
+func findFunPreset(name string) (*FunPreset, error) {
+	for i := range funPresets {
+		if strings.EqualFold(funPresets[i].Name, name) {
+			return &funPresets[i], nil
+		}
+	}
+	var names []string
+	for _, p := range funPresets {
+		names = append(names, p.Name)
+	}
+	return nil, fmt.Errorf("unknown fun preset %q (try %s)", name, strings.Join(names, ", "))
+}
+
+// style draws the preset's border, with the mascot centered underneath
+func (p *FunPreset) style() asciiart.Style {
+	mascot := p.Mascot
+	return asciiart.Style{
+		Name:        p.Name,
+		Description: p.Description,
+		Font:        p.Font,
+		Decorator: asciiart.Decorator{
+			Top:     p.Border,
+			Bottom:  p.Border,
+			Left:    p.Border,
+			Right:   p.Border,
+			Corners: [4]string{p.Corner, p.Corner, p.Corner, p.Corner},
+			Post: func(art string) string {
+				width := 0
+				for _, line := range strings.Split(art, "\n") {
+					width = max(width, asciiart.DisplayWidth(line))
+				}
+				indent := max(0, (width-asciiart.DisplayWidth(mascot))/2)
+				return art + "\n" + strings.Repeat(" ", indent) + mascot
+			},
+		},
+	}
+}
+
+// gradient builds the preset's gradient for this terminal
+func (p *FunPreset) gradient() (*asciiart.Gradient, error) {
+	stops, err := asciiart.ParseGradient(p.Gradient)
+	if err != nil {
+		return nil, err
+	}
+	return asciiart.NewGradient(stops, asciiart.GradientDiagonal, detectColorDepth())
+}
+
+func listFunPresets() {
+	fmt.Println("\nFun presets:")
+	for _, p := range funPresets {
+		fmt.Printf("  %s %s - %s\n", p.Corner, color.CyanString(p.Name), p.Description)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"ascii-art/asciiart"
+)
+
+const (
+	gallerySample = "Hello"
+	// Thumbnail cells are drawn as blocks this many pixels wide and tall
+	thumbCellWidth  = 4
+	thumbCellHeight = 8
+)
+
+var thumbBackground = color.RGBA{0x1e, 0x1e, 0x1e, 0xff}
+
+// runGallery writes a markdown catalog of every built-in style, and
+// optionally PNG thumbnails of each style in every color scheme. Only
+// built-in fonts, styles and schemes are used, so the output depends on
+// nothing but the code.
+func runGallery(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("gallery", flag.ExitOnError)
+	out := flags.String("out", filepath.Join("docs", "gallery.md"), "Markdown file to write")
+	sample := flags.String("sample", gallerySample, "Text rendered in every style")
+	thumbnails := flags.Bool("png", false, "Also write a PNG thumbnail per style and color scheme")
+	flags.Parse(args)
+
+	r := asciiart.NewRenderer()
+	r.Warnings = nil
+	categories, schemes := asciiart.DefaultCategories(), asciiart.DefaultColorSchemes()
+
+	thumbDir := strings.TrimSuffix(*out, filepath.Ext(*out))
+	if err := os.MkdirAll(filepath.Dir(*out), 0755); err != nil {
+		fmt.Printf("Error creating gallery directory: %v\n", err)
+		os.Exit(1)
+	}
+	if *thumbnails {
+		if err := os.MkdirAll(thumbDir, 0755); err != nil {
+			fmt.Printf("Error creating thumbnail directory: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var doc strings.Builder
+	doc.WriteString("# Style Gallery\n\n")
+	doc.WriteString("<!-- Generated by `ascii-art gallery`. Do not edit by hand. -->\n\n")
+	fmt.Fprintf(&doc, "Every built-in style rendered with the sample text %q.\n", *sample)
+
+	thumbs := 0
+	for i, category := range categories {
+		fmt.Fprintf(&doc, "\n## %d. %s\n\n%s\n", i+1, category.Name, category.Description)
+		for j, style := range category.Styles {
+			art := r.Render(*sample, style, nil)
+			fmt.Fprintf(&doc, "\n### %d.%d %s\n\n%s\n\n```text\n%s\n```\n", i+1, j+1, style.Name, style.Description, art)
+			if !*thumbnails {
+				continue
+			}
+
+			var links []string
+			for _, scheme := range schemes {
+				name := fmt.Sprintf("%d-%d-%s.png", i+1, j+1, strings.ToLower(scheme.Name))
+				if err := writeThumbnail(filepath.Join(thumbDir, name), art, &scheme); err != nil {
+					fmt.Printf("Error writing thumbnail: %v\n", err)
+					os.Exit(1)
+				}
+				links = append(links, fmt.Sprintf("![%s in %s](%s/%s)", style.Name, scheme.Name, filepath.Base(thumbDir), name))
+				thumbs++
+			}
+			doc.WriteString("\n" + strings.Join(links, " ") + "\n")
+		}
+	}
+
+	if err := os.WriteFile(*out, []byte(doc.String()), 0644); err != nil {
+		fmt.Printf("Error writing gallery: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Gallery written to: %s\n", *out)
+	if thumbs > 0 {
+		fmt.Printf("%d thumbnails written to: %s\n", thumbs, thumbDir)
+	}
+}
+
+// writeThumbnail draws every non-blank cell of art as a solid block in
+// the color the scheme gives it, or light gray without one
+func writeThumbnail(path, art string, scheme asciiart.Colorizer) error {
+	lines := strings.Split(art, "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, asciiart.DisplayWidth(line))
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, max(1, width)*thumbCellWidth, len(lines)*thumbCellHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{thumbBackground}, image.Point{}, draw.Src)
+
+	for row, line := range lines {
+		col := 0
+		for rest := line; rest != ""; {
+			cluster, cells := asciiart.NextGrapheme(rest)
+			rest = rest[len(cluster):]
+			r, _ := utf8.DecodeRuneInString(cluster)
+			if r != ' ' {
+				ink := color.RGBA{0xe5, 0xe5, 0xe5, 0xff}
+				if scheme != nil {
+					if c := scheme.ColorAt(asciiart.Cell{Rune: r, Row: row, Col: col, Width: width, Height: len(lines)}); c != nil {
+						if rgb, ok := asciiart.ColorRGB(c); ok {
+							ink = color.RGBA{uint8(rgb.R), uint8(rgb.G), uint8(rgb.B), 0xff}
+						}
+					}
+				}
+				cell := image.Rect(col*thumbCellWidth, row*thumbCellHeight, (col+cells)*thumbCellWidth, (row+1)*thumbCellHeight)
+				draw.Draw(img, cell, &image.Uniform{ink}, image.Point{}, draw.Src)
+			}
+			col += cells
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
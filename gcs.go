@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	gcsEndpoint = "https://storage.googleapis.com"
+	gcsScope    = "https://www.googleapis.com/auth/devstorage.read_write"
+)
+
+// putGCS uploads obj to Google Cloud Storage, or to the emulator at
+// STORAGE_EMULATOR_HOST without credentials
+func putGCS(obj upload) error {
+	endpoint, token := os.Getenv("STORAGE_EMULATOR_HOST"), ""
+	if endpoint == "" {
+		endpoint = gcsEndpoint
+		var err error
+		if token, err = gcsCredentialChain(); err != nil {
+			return err
+		}
+	} else if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	query := url.Values{"uploadType": {"media"}, "name": {obj.key}}
+	address := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", strings.TrimSuffix(endpoint, "/"), url.PathEscape(obj.bucket), query.Encode())
+	req, err := http.NewRequest(http.MethodPost, address, bytes.NewReader(obj.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", obj.contentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := uploadClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse("Cloud Storage", resp)
+}
+
+// gcsCredentialChain finds an access token the way Google's client
+// libraries do: GOOGLE_OAUTH_ACCESS_TOKEN, the application default
+// credentials file (GOOGLE_APPLICATION_CREDENTIALS, or the one
+// gcloud auth application-default login writes), then the metadata
+// server on Compute Engine, Cloud Run and GKE
+func gcsCredentialChain() (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	token, err := gcsFileCredentials()
+	if !errors.Is(err, errNoCredentials) {
+		return token, err
+	}
+	token, err = gcsMetadataCredentials()
+	if !errors.Is(err, errNoCredentials) {
+		return token, err
+	}
+	return "", errors.New("no Google Cloud credentials found (set GOOGLE_APPLICATION_CREDENTIALS, or run gcloud auth application-default login)")
+}
+
+// gcsCredentialsFile is the path of the application default credentials
+func gcsCredentialsFile() string {
+	if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		return path
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "gcloud", "application_default_credentials.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cmp.Or(os.Getenv("CLOUDSDK_CONFIG"), filepath.Join(home, ".config", "gcloud")), "application_default_credentials.json")
+}
+
+// gcsFileCredentials trades a service account key or a gcloud user login
+// for an access token
+func gcsFileCredentials() (string, error) {
+	path := gcsCredentialsFile()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && os.Getenv("GOOGLE_APPLICATION_CREDENTIALS") == "" {
+		return "", errNoCredentials
+	}
+	if err != nil {
+		return "", err
+	}
+	var creds struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		TokenURI     string `json:"token_uri"`
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return "", fmt.Errorf("%s: %v", path, err)
+	}
+
+	tokenURI := cmp.Or(creds.TokenURI, "https://oauth2.googleapis.com/token")
+	switch creds.Type {
+	case "service_account":
+		assertion, err := gcsAssertion(creds.ClientEmail, creds.PrivateKey, tokenURI)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", path, err)
+		}
+		return gcsToken(tokenURI, url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		})
+	case "authorized_user":
+		return gcsToken(tokenURI, url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {creds.ClientID},
+			"client_secret": {creds.ClientSecret},
+			"refresh_token": {creds.RefreshToken},
+		})
+	}
+	return "", fmt.Errorf("%s: %q credentials are not supported (set GOOGLE_OAUTH_ACCESS_TOKEN instead)", path, creds.Type)
+}
+
+// gcsAssertion is the signed JWT a service account presents for a token
+func gcsAssertion(email, privateKey, audience string) (string, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return "", errors.New("invalid private_key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return "", fmt.Errorf("invalid private_key: %v", err)
+		}
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("private_key is not an RSA key")
+	}
+
+	now := time.Now()
+	claims, err := json.Marshal(map[string]any{
+		"iss":   email,
+		"scope": gcsScope,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	encode := base64.RawURLEncoding.EncodeToString
+	unsigned := encode([]byte(`{"alg":"RS256","typ":"JWT"}`)) + "." + encode(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + encode(signature), nil
+}
+
+// gcsToken posts an OAuth token request and returns the access token
+func gcsToken(tokenURI string, form url.Values) (string, error) {
+	resp, err := uploadClient.PostForm(tokenURI, form)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse("Google OAuth", resp); err != nil {
+		return "", err
+	}
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("Google OAuth: %v", err)
+	}
+	return result.AccessToken, nil
+}
+
+// gcsMetadataCredentials asks the metadata server for the attached
+// service account's token. Off Google Cloud it finds none.
+func gcsMetadataCredentials() (string, error) {
+	host := cmp.Or(os.Getenv("GCE_METADATA_HOST"), "169.254.169.254")
+	ctx, cancel := context.WithTimeout(context.Background(), credentialTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", errNoCredentials
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errNoCredentials
+	}
+	if err := checkResponse("Google metadata server", resp); err != nil {
+		return "", err
+	}
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("Google metadata server: %v", err)
+	}
+	return result.AccessToken, nil
+}
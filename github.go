@@ -0,0 +1,303 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+const (
+	githubAPI     = "https://api.github.com"
+	githubTimeout = 10 * time.Second
+
+	// githubMaxPages bounds how many pages of check runs are read, at
+	// githubPerPage, the most GitHub sends at once
+	githubMaxPages = 10
+	githubPerPage  = 100
+)
+
+// GitHubStatus is what the banner shows for an issue or pull request. It
+// is also the cache format used when the API cannot be reached.
+type GitHubStatus struct {
+	Kind      string    `json:"kind"` // "pr" or "issue"
+	Repo      string    `json:"repo"`
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	State     string    `json:"state"` // open, closed, merged, draft
+	Checks    string    `json:"checks,omitempty"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Check summaries
+const (
+	checksPassing = "passing"
+	checksFailing = "failing"
+	checksPending = "pending"
+)
+
+// runGitHub renders a status banner for a pull request or issue:
+// ascii-art gh pr 1234 --repo owner/name
+func runGitHub(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("gh", flag.ExitOnError)
+	repo := flags.String("repo", "", "Repository as owner/name (default: the origin remote)")
+	font := flags.String("font", "small", "Figure font for the banner")
+	offline := flags.Bool("offline", false, "Use cached data only")
+	positional := parseInterspersed(flags, args)
+
+	if len(positional) != 2 || (positional[0] != "pr" && positional[0] != "issue") {
+		fmt.Println("Usage: ascii-art gh pr|issue NUMBER [--repo owner/name] [--offline]")
+		os.Exit(1)
+	}
+	kind := positional[0]
+	number, err := strconv.Atoi(strings.TrimPrefix(positional[1], "#"))
+	if err != nil {
+		fmt.Printf("Error: %q is not a number\n", positional[1])
+		os.Exit(1)
+	}
+	if *repo == "" {
+		if *repo = originRepo(); *repo == "" {
+			fmt.Println("Error: no --repo given and no GitHub origin remote found")
+			os.Exit(1)
+		}
+	}
+
+	var status *GitHubStatus
+	if !*offline {
+		status, err = fetchGitHubStatus(kind, *repo, number)
+		if err == nil {
+			saveGitHubCache(status)
+		} else {
+			fmt.Fprintln(os.Stderr, color.YellowString("Warning: %v; using cached data", err))
+		}
+	}
+	stale := status == nil
+	if status == nil {
+		if status, err = loadGitHubCache(kind, *repo, number); err != nil {
+			fmt.Printf("Error: no cached data for %s %s#%d\n", kind, *repo, number)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Println(renderGitHubStatus(status, *font, stale))
+}
+
+func fetchGitHubStatus(kind, repo string, number int) (*GitHubStatus, error) {
+	status := &GitHubStatus{Kind: kind, Repo: repo, Number: number, FetchedAt: time.Now()}
+
+	endpoint := "issues"
+	if kind == "pr" {
+		endpoint = "pulls"
+	}
+	var item struct {
+		Title  string `json:"title"`
+		State  string `json:"state"`
+		Merged bool   `json:"merged"`
+		Draft  bool   `json:"draft"`
+		Head   struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := githubGet(fmt.Sprintf("/repos/%s/%s/%d", repo, endpoint, number), &item); err != nil {
+		return nil, err
+	}
+
+	status.Title, status.State = item.Title, item.State
+	switch {
+	case item.Merged:
+		status.State = "merged"
+	case item.Draft && item.State == "open":
+		status.State = "draft"
+	}
+	if kind != "pr" || item.Head.SHA == "" {
+		return status, nil
+	}
+
+	type checkRun struct {
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+	}
+	var checkRuns []checkRun
+	next := fmt.Sprintf("%s/repos/%s/commits/%s/check-runs?per_page=%d", githubAPI, repo, item.Head.SHA, githubPerPage)
+	for page := 0; next != "" && page < githubMaxPages; page++ {
+		var runs struct {
+			CheckRuns []checkRun `json:"check_runs"`
+		}
+		var err error
+		if next, err = githubGetPage(next, &runs); err != nil {
+			return nil, err
+		}
+		checkRuns = append(checkRuns, runs.CheckRuns...)
+	}
+	for _, run := range checkRuns {
+		switch {
+		case run.Status != "completed":
+			if status.Checks != checksFailing {
+				status.Checks = checksPending
+			}
+		case run.Conclusion == "failure" || run.Conclusion == "timed_out" || run.Conclusion == "cancelled":
+			status.Checks = checksFailing
+		case status.Checks == "":
+			status.Checks = checksPassing
+		}
+	}
+	return status, nil
+}
+
+// githubGet decodes a GitHub API response into v. GITHUB_TOKEN or GH_TOKEN
+// is sent when set, which raises rate limits and allows private repos.
+func githubGet(path string, v any) error {
+	_, err := githubGetPage(githubAPI+path, v)
+	return err
+}
+
+// githubGetPage is githubGet for a full API URL. It returns the URL of
+// the next page from the Link header, or "" on the last page.
+func githubGetPage(url string, v any) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(name); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+			break
+		}
+	}
+
+	client := &http.Client{Timeout: githubTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub API %s: %s", strings.TrimPrefix(url, githubAPI), resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", err
+	}
+	return nextPageLink(resp.Header.Get("Link")), nil
+}
+
+// nextPageLink finds the rel="next" URL in a Link header. Only API URLs
+// are followed, so the token is never sent anywhere else.
+func nextPageLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+		if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				if url := target[1 : len(target)-1]; strings.HasPrefix(url, githubAPI+"/") {
+					return url
+				}
+			}
+		}
+	}
+	return ""
+}
+
+var githubRemote = regexp.MustCompile(`github\.com[:/]([^/]+/[^/]+?)(?:\.git)?/?$`)
+
+// originRepo guesses owner/name from the current git checkout
+func originRepo() string {
+	out, err := exec.Command("git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		return ""
+	}
+	if m := githubRemote.FindStringSubmatch(strings.TrimSpace(string(out))); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func githubCachePath(kind, repo string, number int) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s_%s_%d.json", strings.ReplaceAll(repo, "/", "_"), kind, number)
+	return filepath.Join(dir, "asciiart", "github", name), nil
+}
+
+func saveGitHubCache(status *GitHubStatus) {
+	path, err := githubCachePath(status.Kind, status.Repo, status.Number)
+	if err != nil {
+		return
+	}
+	data, _ := json.Marshal(status)
+	if os.MkdirAll(filepath.Dir(path), 0755) == nil {
+		os.WriteFile(path, data, 0644)
+	}
+}
+
+func loadGitHubCache(kind, repo string, number int) (*GitHubStatus, error) {
+	path, err := githubCachePath(kind, repo, number)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	status := &GitHubStatus{}
+	if err := json.Unmarshal(data, status); err != nil {
+		return nil, errors.New("corrupt cache")
+	}
+	return status, nil
+}
+
+// statusColor picks the banner color: green when merged or passing, red
+// when checks fail, yellow while pending.
+func (s *GitHubStatus) statusColor() *color.Color {
+	switch {
+	case s.State == "merged":
+		return color.New(color.FgGreen, color.Bold)
+	case s.Checks == checksFailing:
+		return color.New(color.FgRed, color.Bold)
+	case s.Checks == checksPending || s.State == "draft":
+		return color.New(color.FgYellow)
+	case s.State == "closed":
+		return color.New(color.FgHiBlack)
+	}
+	return color.New(color.FgCyan)
+}
+
+func renderGitHubStatus(s *GitHubStatus, font string, stale bool) string {
+	label := "PR"
+	if s.Kind == "issue" {
+		label = "Issue"
+	}
+	c := s.statusColor()
+
+	banner := strings.TrimRight(renderer.Figure(fmt.Sprintf("%s #%d", label, s.Number), font), "\n")
+	state := strings.ToUpper(s.State)
+	if s.Checks != "" {
+		state += " · checks " + s.Checks
+	}
+	details := s.Repo + "\n" + s.Title + "\n" + state
+	if stale {
+		details += fmt.Sprintf("\n(cached %s)", s.FetchedAt.Format("2006-01-02 15:04"))
+	}
+
+	var lines []string
+	for _, line := range strings.Split(banner, "\n") {
+		lines = append(lines, c.Sprint(line))
+	}
+	lines = append(lines, asciiart.ApplyDecorator(details, asciiart.RoundBoxDecorator))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestNextPageLink(t *testing.T) {
+	page2 := githubAPI + "/repositories/1/commits/abc/check-runs?per_page=100&page=2"
+	tests := []struct {
+		header, want string
+	}{
+		{"", ""},
+		{`<` + page2 + `>; rel="next", <` + githubAPI + `/x?page=5>; rel="last"`, page2},
+		{`<` + githubAPI + `/x?page=1>; rel="prev", <` + page2 + `>; rel="next"`, page2},
+		{`<` + githubAPI + `/x?page=1>; rel="first", <` + githubAPI + `/x?page=4>; rel="prev"`, ""},
+		{`<https://evil.example/steal>; rel="next"`, ""},
+		{`<https://api.github.com.evil.example/x>; rel="next"`, ""},
+		{`not a link`, ""},
+	}
+	for _, test := range tests {
+		if got := nextPageLink(test.header); got != test.want {
+			t.Errorf("nextPageLink(%q) = %q, want %q", test.header, got, test.want)
+		}
+	}
+}
@@ -3,11 +3,16 @@ module ascii-art
 go 1.22.4
 
 require (
-	github.com/common-nighthawk/go-figure v0.0.0-20210622060536-734e95fb86be // indirect
-	github.com/fatih/color v1.18.0 // indirect
+	github.com/common-nighthawk/go-figure v0.0.0-20210622060536-734e95fb86be
+	github.com/fatih/color v1.18.0
+	github.com/mattn/go-isatty v0.0.20
+	golang.org/x/sys v0.25.0
+	golang.org/x/term v0.24.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mbndr/figlet4go v0.0.0-20190224160619-d6cef5b186ea // indirect
 	github.com/probandula/figlet4go v0.0.0-20190224160619-d6cef5b186ea // indirect
-	golang.org/x/sys v0.25.0 // indirect
 )
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"ascii-art/asciiart"
+)
+
+// healthcheckText is small enough to render in any font
+const healthcheckText = "OK"
+
+// runHealthcheck renders a tiny banner through the same path as the
+// server and exits 0 if it worked, 1 otherwise, for a Docker HEALTHCHECK.
+// It needs no network: a font must load, along with the fallback for
+// missing ones, and colored output must carry escape codes.
+func runHealthcheck(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("healthcheck", flag.ExitOnError)
+	quiet := flags.Bool("quiet", false, "Print nothing, only set the exit status")
+	flags.Parse(args)
+
+	if err := config.healthcheck(); err != nil {
+		if !*quiet {
+			fmt.Printf("unhealthy: %v\n", err)
+		}
+		os.Exit(1)
+	}
+	if !*quiet {
+		fmt.Println("healthy")
+	}
+}
+
+func (config *AppConfig) healthcheck() error {
+	if len(config.categories) == 0 || len(config.colors) == 0 {
+		return errors.New("no styles or color schemes loaded")
+	}
+	styled := ""
+	for i, category := range config.categories {
+		for j, style := range category.Styles {
+			if style.Font != "" && renderer.FontExists(style.Font) && styled == "" {
+				styled = fmt.Sprintf("%d.%d", i+1, j+1)
+			}
+		}
+	}
+	if styled == "" {
+		return errors.New("no style has a font that loads")
+	}
+	if !renderer.FontExists(asciiart.FallbackFont) {
+		return fmt.Errorf("fallback font %q not found", asciiart.FallbackFont)
+	}
+
+	response := config.answer(RenderRequest{Text: healthcheckText, Style: styled, ColorScheme: "1", Format: "json"})
+	if response.Error != "" {
+		return errors.New(response.Error)
+	}
+	art := response.Art
+	if strings.Count(art.Plain, "\n") == 0 {
+		return fmt.Errorf("style %s rendered a single line", styled)
+	}
+	if !strings.Contains(art.ANSI, "\x1b[") {
+		return errors.New("colored output has no escape codes")
+	}
+	return nil
+}
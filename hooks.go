@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+const defaultHookTimeout = 30 * time.Second
+
+// When hooks run, around each render
+const (
+	hookPre  = "pre"
+	hookPost = "post"
+)
+
+// What a failing hook does
+const (
+	hookWarn   = "warn"   // Print a warning and go on
+	hookIgnore = "ignore" // Go on quietly
+	hookFail   = "fail"   // Stop with an error
+)
+
+// HookConfig is one entry of the hooks list in config.yaml: a shell
+// command run before or after every render
+type HookConfig struct {
+	Name      string `yaml:"name,omitempty"`
+	Command   string `yaml:"command"`
+	When      string `yaml:"when,omitempty"`       // pre or post (default)
+	Timeout   string `yaml:"timeout,omitempty"`    // Such as 10s (default 30s)
+	OnFailure string `yaml:"on_failure,omitempty"` // warn (default), ignore or fail
+}
+
+// Hook is a checked HookConfig
+type Hook struct {
+	name, command, when, onFailure string
+	timeout                        time.Duration
+}
+
+func (h HookConfig) hook() (Hook, error) {
+	hook := Hook{h.Name, h.Command, h.When, h.OnFailure, defaultHookTimeout}
+	if hook.command == "" {
+		return hook, errors.New("missing command")
+	}
+	if hook.name == "" {
+		hook.name, _, _ = strings.Cut(hook.command, " ")
+	}
+	switch hook.when {
+	case "":
+		hook.when = hookPost
+	case hookPre, hookPost:
+	default:
+		return hook, fmt.Errorf("unknown when %q (use pre or post)", hook.when)
+	}
+	switch hook.onFailure {
+	case "":
+		hook.onFailure = hookWarn
+	case hookWarn, hookIgnore, hookFail:
+	default:
+		return hook, fmt.Errorf("unknown on_failure %q (use warn, ignore or fail)", hook.onFailure)
+	}
+	if h.Timeout != "" {
+		timeout, err := time.ParseDuration(h.Timeout)
+		if err != nil || timeout <= 0 {
+			return hook, fmt.Errorf("invalid timeout %q", h.Timeout)
+		}
+		hook.timeout = timeout
+	}
+	return hook, nil
+}
+
+// HookRender is what hooks are told about a render, as ASCIIART_
+// environment variables. Post hooks also read the art on stdin.
+type HookRender struct {
+	text     string
+	output   string // The file or output target; empty when printed
+	format   string
+	category asciiart.StyleCategory
+	style    asciiart.Style
+	scheme   *asciiart.ColorScheme
+	art      string // Post hooks only
+}
+
+func (r HookRender) environ(when string) []string {
+	scheme := ""
+	if r.scheme != nil {
+		scheme = r.scheme.Name
+	}
+	return append(os.Environ(),
+		"ASCIIART_HOOK="+when,
+		"ASCIIART_TEXT="+r.text,
+		"ASCIIART_OUTPUT="+r.output,
+		"ASCIIART_FORMAT="+r.format,
+		"ASCIIART_CATEGORY="+r.category.Name,
+		"ASCIIART_STYLE="+r.style.Name,
+		"ASCIIART_FONT="+r.style.Font,
+		"ASCIIART_COLORSCHEME="+scheme,
+	)
+}
+
+// run runs the hook through the shell, with its output on stderr so it
+// never mixes with the art
+func (h Hook) run(render HookRender) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", h.command)
+	}
+	cmd.Env = render.environ(h.when)
+	if h.when == hookPost {
+		cmd.Stdin = strings.NewReader(render.art + "\n")
+	}
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", h.timeout)
+	}
+	return err
+}
+
+// runHooks runs the config's hooks for when, in order. A hook that fails
+// warns, is ignored, or with on_failure: fail stops ascii-art, as a
+// failed write would.
+func (config *AppConfig) runHooks(when string, render HookRender) {
+	for _, hook := range config.hooks {
+		if hook.when != when {
+			continue
+		}
+		err := hook.run(render)
+		switch {
+		case err == nil, hook.onFailure == hookIgnore:
+		case hook.onFailure == hookFail:
+			fmt.Printf("Error: %s hook %s: %v\n", when, hook.name, err)
+			os.Exit(1)
+		default:
+			fmt.Fprintln(os.Stderr, color.YellowString("Warning: %s hook %s: %v", when, hook.name, err))
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+
+	"ascii-art/asciiart"
+)
+
+// loadImage decodes a PNG or JPEG file
+func loadImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return img, nil
+}
+
+// renderImage converts the image at path to characters, or to colored
+// half blocks with blocks or teletext mosaics with teletext, and prints
+// it or saves it to the -output file. Without a size it fits the
+// terminal, or a teletext page.
+func renderImage(path string, opts asciiart.ImageOptions, blocks, teletext bool, outputFile string) {
+	img, err := loadImage(path)
+	if err != nil {
+		fmt.Printf("Error reading image: %v\n", err)
+		os.Exit(1)
+	}
+
+	if opts.Width == 0 && opts.Height == 0 && teletext {
+		opts.Width = asciiart.TeletextWidth
+	}
+	if opts.Width == 0 && opts.Height == 0 {
+		if width, _, ok := terminalSize(); ok {
+			opts.Width = width - 1
+		}
+	}
+	art := asciiart.ConvertImage(img, opts)
+	if blocks {
+		art = asciiart.RasterizeImage(img, opts).HalfBlocks(detectColorDepth())
+	}
+	if teletext {
+		art = asciiart.RasterizeMosaic(img, opts).Teletext(detectColorDepth())
+	}
+
+	if outputFile == "" {
+		fmt.Println(art)
+		return
+	}
+	if err := saveToFile(outputFile, art+"\n"); err != nil {
+		fmt.Printf("Error saving to file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("ASCII art saved to: %s\n", outputFile)
+}
+
+// imageFlags are the flags tuning how an image is converted, for -image
+// and the image command
+type imageFlags struct {
+	height        *int
+	ramp          *string
+	gamma, aspect *float64
+	invert        *bool
+}
+
+func addImageFlags(flags *flag.FlagSet) imageFlags {
+	return imageFlags{
+		height: flags.Int("height", 0, "Height in lines for the image (default: keep aspect ratio)"),
+		ramp:   flags.String("ramp", asciiart.DefaultRamp, "Characters for the image, from least to most ink"),
+		gamma:  flags.Float64("gamma", 1, "Brightness curve for the image (<1 brightens, >1 darkens)"),
+		invert: flags.Bool("invert", false, "Draw dark pixels densest, for light terminal backgrounds"),
+		aspect: flags.Float64("aspect", asciiart.DefaultCellAspect, "Character cell width/height ratio for the image"),
+	}
+}
+
+func (f imageFlags) options(width int) asciiart.ImageOptions {
+	return asciiart.ImageOptions{
+		Width:      width,
+		Height:     *f.height,
+		Ramp:       *f.ramp,
+		Gamma:      *f.gamma,
+		Invert:     *f.invert,
+		CellAspect: *f.aspect,
+	}
+}
+
+// runImage is the image command, -image with flags of its own
+func runImage(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("image", flag.ExitOnError)
+	width := flags.Int("width", 0, "Width in characters (default: terminal width)")
+	blocks := flags.Bool("blocks", false, "Draw with colored ▀/▄ half blocks, two pixels per character")
+	teletext := flags.Bool("teletext", false, "Draw with 2x3 teletext mosaics in the 8 teletext colors")
+	output := flags.String("output", "", "Save to this file instead of printing")
+	tuning := addImageFlags(flags)
+	paths := parseInterspersed(flags, args)
+	if len(paths) != 1 {
+		fmt.Println("Usage: ascii-art image [-width N] [-blocks | -teletext] [-output FILE] IMAGE")
+		os.Exit(1)
+	}
+	if *blocks && *teletext {
+		fmt.Println("Error: choose one of -blocks and -teletext")
+		os.Exit(1)
+	}
+	renderImage(paths[0], tuning.options(*width), *blocks, *teletext, *output)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"ascii-art/asciiart"
+)
+
+// Caps on what one served request, -batch line or piped input may
+// render, so huge or pathological input fails with an error instead of
+// exhausting memory. Colored variants take many bytes per cell, so cells
+// are the limit that matters; animations are capped by frame count,
+// which bounds shuffle and -animate as well.
+const (
+	maxRenderRunes  = 2000
+	maxRenderLines  = 100
+	maxRenderCells  = 250_000
+	maxRenderFrames = 10_000
+)
+
+// checkRenderText rejects text over the input limits
+func checkRenderText(text string) error {
+	if n := utf8.RuneCountInString(text); n > maxRenderRunes {
+		return fmt.Errorf("text is %d characters, more than the limit of %d", n, maxRenderRunes)
+	}
+	if n := strings.Count(text, "\n") + 1; n > maxRenderLines {
+		return fmt.Errorf("text is %d lines, more than the limit of %d", n, maxRenderLines)
+	}
+	return nil
+}
+
+// checkRenderSize rejects plain art covering more than maxRenderCells
+// terminal cells, before the colored variants are built
+func checkRenderSize(plain string) error {
+	lines := strings.Split(plain, "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, asciiart.DisplayWidth(line))
+	}
+	if cells := width * len(lines); cells > maxRenderCells {
+		return fmt.Errorf("art would be %dx%d characters, more than the limit of %d cells; use shorter text or a smaller font", width, len(lines), maxRenderCells)
+	}
+	return nil
+}
+
+// checkRenderFrames rejects animations of more than maxRenderFrames
+// frames
+func checkRenderFrames(frames int) error {
+	if frames > maxRenderFrames {
+		return fmt.Errorf("animation would be %d frames, more than the limit of %d; use shorter text, -animate lines or a lower -fps", frames, maxRenderFrames)
+	}
+	return nil
+}
+
+// checkRenderLimits applies every limit to text and the plain art it
+// renders to, returning that art
+func (config *AppConfig) checkRenderLimits(text string, style asciiart.Style) (string, error) {
+	if err := checkRenderText(text); err != nil {
+		return "", err
+	}
+	plain := config.generateArt(text, style, nil)
+	return plain, checkRenderSize(plain)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"ascii-art/asciiart"
+)
+
+func TestRenderLimits(t *testing.T) {
+	lines := func(n int) string {
+		return strings.TrimSuffix(strings.Repeat("a\n", n), "\n")
+	}
+	block := func(width, height int, cell string) string {
+		return strings.TrimSuffix(strings.Repeat(strings.Repeat(cell, width)+"\n", height), "\n")
+	}
+	tests := []struct {
+		name  string
+		check func() error
+		fails bool
+	}{
+		{"most characters", func() error { return checkRenderText(strings.Repeat("é", maxRenderRunes)) }, false},
+		{"too many characters", func() error { return checkRenderText(strings.Repeat("é", maxRenderRunes+1)) }, true},
+		{"most lines", func() error { return checkRenderText(lines(maxRenderLines)) }, false},
+		{"too many lines", func() error { return checkRenderText(lines(maxRenderLines + 1)) }, true},
+		{"most cells", func() error { return checkRenderSize(block(500, maxRenderCells/500, "#")) }, false},
+		{"too many cells", func() error { return checkRenderSize(block(501, maxRenderCells/500, "#")) }, true},
+		{"most wide cells", func() error { return checkRenderSize(block(250, maxRenderCells/500, "世")) }, false},
+		{"too many wide cells", func() error { return checkRenderSize(block(251, maxRenderCells/500, "世")) }, true},
+		{"most frames", func() error { return checkRenderFrames(maxRenderFrames) }, false},
+		{"too many frames", func() error { return checkRenderFrames(maxRenderFrames + 1) }, true},
+	}
+	for _, test := range tests {
+		if err := test.check(); (err != nil) != test.fails {
+			t.Errorf("%s: error %v, want failure %v", test.name, err, test.fails)
+		}
+	}
+}
+
+func TestAnimationFrames(t *testing.T) {
+	fast, err := newFrameLimiter(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		art, mode string
+		speed     int
+		frames    *frameLimiter
+		want      int
+	}{
+		{"ab c", animateTypewriter, 40, nil, 3},
+		{"\x1b[31mab\x1b[0m\n c", animateTypewriter, 40, nil, 3},
+		{"one\ntwo\nthree", animateLines, 40, nil, 3},
+		// At 10 frames a second, each of 40 characters a second adds 4
+		{strings.Repeat("x", 9), animateTypewriter, 40, fast, 3},
+	}
+	for _, test := range tests {
+		if got := animationFrames(test.art, test.mode, test.speed, test.frames); got != test.want {
+			t.Errorf("animationFrames(%q, %s) = %d, want %d", test.art, test.mode, got, test.want)
+		}
+	}
+}
+
+// TestRequestLimits checks that served, stdin-json and -batch renders
+// refuse text over the limits
+func TestRequestLimits(t *testing.T) {
+	config := newAppConfig()
+	long := strings.Repeat("a", maxRenderRunes+1)
+	if response := config.answer(RenderRequest{Text: long}); response.Error == "" {
+		t.Errorf("answer rendered %d characters", len(long))
+	}
+	if response := config.answer(RenderRequest{Text: long[1:]}); response.Error != "" {
+		t.Errorf("answer refused %d characters: %s", len(long)-1, response.Error)
+	}
+	if _, err := config.checkRenderLimits(long, asciiart.Style{}); err == nil {
+		t.Errorf("checkRenderLimits accepted %d characters", len(long))
+	}
+	big := asciiart.Style{Font: "banner"}
+	if _, err := config.checkRenderLimits(strings.Repeat("W", 60)+strings.Repeat("\nW", maxRenderLines-1), big); err == nil {
+		t.Errorf("checkRenderLimits accepted art over %d cells", maxRenderCells)
+	}
+}
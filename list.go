@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// previewSample is the text -preview and the preview command render in
+// every style
+const previewSample = "Hello!"
+
+// listings are what the list command can show, by name
+var listings = map[string]func(config *AppConfig){
+	"styles":      (*AppConfig).listAvailableStyles,
+	"fonts":       func(*AppConfig) { listFonts(fontListSample) },
+	"effects":     func(*AppConfig) { listEffects() },
+	"art":         func(*AppConfig) { listClipart() },
+	"targets":     func(*AppConfig) { printTargetMatrix() },
+	"fun":         func(*AppConfig) { listFunPresets() },
+	"languages":   func(*AppConfig) { listLanguages() },
+	"presets":     func(*AppConfig) { listPresets() },
+	"backgrounds": func(*AppConfig) { listBackgrounds() },
+}
+
+// runList is the list command: the styles and color schemes, like -list,
+// or one of the other listings by name
+func runList(config *AppConfig, args []string) {
+	kind := "styles"
+	if len(args) > 0 {
+		kind = args[0]
+	}
+	list, ok := listings[kind]
+	if !ok || len(args) > 1 {
+		fmt.Println("Usage: ascii-art list [styles|fonts|effects|art|targets|fun|languages|presets|backgrounds]")
+		os.Exit(1)
+	}
+	list(config)
+}
+
+// runPreview is the preview command: every style, like -preview, with
+// the sample text given
+func runPreview(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("preview", flag.ExitOnError)
+	sample := flags.String("sample", previewSample, "Text rendered in every style")
+	if text := parseInterspersed(flags, args); len(text) > 0 {
+		*sample = strings.Join(text, " ")
+	}
+	config.previewStyles(*sample)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Locale holds what {date}, {time} and {number} placeholders need to
+// write dates and numbers the local way
+type Locale struct {
+	name        string
+	days        [7]string // Sunday first, as time.Weekday counts
+	shortDays   [7]string
+	months      [12]string
+	shortMonths [12]string
+	am, pm      string
+	date        string // strftime formats for %x, %X and %c
+	time        string
+	dateTime    string
+	decimal     string // Decimal point and thousands separator of {number}
+	group       string
+}
+
+var englishLocale = Locale{
+	name:        "en",
+	days:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	shortDays:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	months:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	shortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	am:          "AM",
+	pm:          "PM",
+	date:        "%m/%d/%Y",
+	time:        "%I:%M:%S %p",
+	dateTime:    "%a %b %e %H:%M:%S %Y",
+	decimal:     ".",
+	group:       ",",
+}
+
+// locales are the built-in locales, by language and by language and
+// territory where the territory changes something
+var locales = map[string]Locale{
+	"en":    englishLocale,
+	"en_GB": withFormats(englishLocale, "en_GB", "%d/%m/%Y", "%H:%M:%S", "%a %e %b %Y %H:%M:%S"),
+	"de": {
+		name:        "de",
+		days:        [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		shortDays:   [7]string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+		months:      [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		shortMonths: [12]string{"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Okt", "Nov", "Dez"},
+		date:        "%d.%m.%Y",
+		time:        "%H:%M:%S",
+		dateTime:    "%a %d %b %Y %T",
+		decimal:     ",",
+		group:       ".",
+	},
+	"fr": {
+		name:        "fr",
+		days:        [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		shortDays:   [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		months:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		shortMonths: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		date:        "%d/%m/%Y",
+		time:        "%H:%M:%S",
+		dateTime:    "%a %d %b %Y %T",
+		decimal:     ",",
+		group:       " ",
+	},
+	"es": {
+		name:        "es",
+		days:        [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		shortDays:   [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		months:      [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		shortMonths: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sep", "oct", "nov", "dic"},
+		date:        "%d/%m/%Y",
+		time:        "%H:%M:%S",
+		dateTime:    "%a %d %b %Y %T",
+		decimal:     ",",
+		group:       ".",
+	},
+	"it": {
+		name:        "it",
+		days:        [7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+		shortDays:   [7]string{"dom", "lun", "mar", "mer", "gio", "ven", "sab"},
+		months:      [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		shortMonths: [12]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		date:        "%d/%m/%Y",
+		time:        "%H:%M:%S",
+		dateTime:    "%a %d %b %Y %T",
+		decimal:     ",",
+		group:       ".",
+	},
+	"pt": {
+		name:        "pt",
+		days:        [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+		shortDays:   [7]string{"dom", "seg", "ter", "qua", "qui", "sex", "sáb"},
+		months:      [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		shortMonths: [12]string{"jan", "fev", "mar", "abr", "mai", "jun", "jul", "ago", "set", "out", "nov", "dez"},
+		date:        "%d/%m/%Y",
+		time:        "%H:%M:%S",
+		dateTime:    "%a %d %b %Y %T",
+		decimal:     ",",
+		group:       ".",
+	},
+	"nl": {
+		name:        "nl",
+		days:        [7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+		shortDays:   [7]string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+		months:      [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		shortMonths: [12]string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+		date:        "%d-%m-%Y",
+		time:        "%H:%M:%S",
+		dateTime:    "%a %d %b %Y %T",
+		decimal:     ",",
+		group:       ".",
+	},
+	"sv": {
+		name:        "sv",
+		days:        [7]string{"söndag", "måndag", "tisdag", "onsdag", "torsdag", "fredag", "lördag"},
+		shortDays:   [7]string{"sön", "mån", "tis", "ons", "tors", "fre", "lör"},
+		months:      [12]string{"januari", "februari", "mars", "april", "maj", "juni", "juli", "augusti", "september", "oktober", "november", "december"},
+		shortMonths: [12]string{"jan", "feb", "mar", "apr", "maj", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+		date:        "%Y-%m-%d",
+		time:        "%H:%M:%S",
+		dateTime:    "%a %e %b %Y %T",
+		decimal:     ",",
+		group:       " ",
+	},
+}
+
+// withFormats is l for another territory that writes dates differently
+func withFormats(l Locale, name, date, clock, dateTime string) Locale {
+	l.name, l.date, l.time, l.dateTime = name, date, clock, dateTime
+	return l
+}
+
+// findLocale looks up a POSIX locale name such as de_DE.UTF-8 or
+// fr_CA@euro, by language and territory, then by language alone. C,
+// POSIX and an empty name are English.
+func findLocale(name string) (Locale, bool) {
+	name, _, _ = strings.Cut(name, ".")
+	name, _, _ = strings.Cut(name, "@")
+	name = strings.ReplaceAll(name, "-", "_")
+	if name == "" || name == "C" || name == "POSIX" {
+		return englishLocale, true
+	}
+	if l, ok := locales[name]; ok {
+		return l, true
+	}
+	language, _, _ := strings.Cut(name, "_")
+	l, ok := locales[strings.ToLower(language)]
+	return l, ok
+}
+
+// resolveLocale returns the locale given with -locale or, if there is
+// none, the one LC_ALL, LC_TIME or LANG name. Locales that are not built
+// in are an error when asked for and English when set in the
+// environment.
+func resolveLocale(name string) (Locale, error) {
+	if name != "" {
+		if l, ok := findLocale(name); ok {
+			return l, nil
+		}
+		return Locale{}, fmt.Errorf("unknown locale %q (use one of %s)", name, strings.Join(localeNames(), ", "))
+	}
+	for _, env := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if value := os.Getenv(env); value != "" {
+			if l, ok := findLocale(value); ok {
+				return l, nil
+			}
+			break
+		}
+	}
+	return englishLocale, nil
+}
+
+func localeNames() []string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setLocale switches placeholders to the locale given with -locale
+func (config *AppConfig) setLocale(name string) {
+	locale, err := resolveLocale(name)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	config.locale = locale
+}
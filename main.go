@@ -4,53 +4,31 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
-	"unicode/utf8"
 
+	"ascii-art/asciiart"
 	"github.com/common-nighthawk/go-figure"
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 )
 
-// StyleCategory represents a category of text styles
-type StyleCategory struct {
-	name        string
-	description string
-	styles      []Style
-}
-
-// Style represents an ASCII art style configuration
-type Style struct {
-	name        string
-	description string
-	font        string    // Maps to go-figure font name
-	decorator   Decorator // Optional decorator for additional styling
-}
-
-// Decorator provides additional styling to the ASCII art
-type Decorator struct {
-	top       string
-	bottom    string
-	left      string
-	right     string
-	corners   [4]string // TL, TR, BL, BR
-	fill      string
-	pre       func(string) string // Pre-processing function
-	post      func(string) string // Post-processing function
-}
-
-// ColorScheme represents a color configuration
-type ColorScheme struct {
-	name       string
-	primary    *color.Color
-	secondary  *color.Color
-	background *color.Color
-}
-
 // AppConfig holds the application configuration
+
 type AppConfig struct {
-	categories []StyleCategory
-	colors     []ColorScheme
+	categories   []asciiart.StyleCategory
+	colors       []asciiart.ColorScheme
+	filter       *WordFilter                // nil renders input unfiltered
+	filterConfig FilterConfig               // Extra filter words from the config file
+	audit        *AuditLog                  // nil keeps no audit log
+	renders      *RenderCache               // Served renders, or nil to render every request
+	hooks        []Hook                     // Run around each render, from the config file
+	templateExec []string                   // Commands {exec:...} may run, from the config file
+	customStyles map[string]DecoratorConfig // Definitions of the Custom styles, by name
+	renderer     *asciiart.Renderer         // Draws the art; nil for the shared one, which warns on stderr
+	locale       Locale                     // For {date}, {time} and {number}, from -locale or the environment
 }
 
 // Constants for frame patterns
@@ -59,167 +37,464 @@ const (
 	framePatternBottom = "═════════════"
 )
 
-// Predefined decorators
-var (
-	boxDecorator = Decorator{
-		top:     "─",
-		bottom:  "─",
-		left:    "│",
-		right:   "│",
-		corners: [4]string{"┌", "┐", "└", "┘"},
-	}
-
-	doubleBoxDecorator = Decorator{
-		top:     "═",
-		bottom:  "═",
-		left:    "║",
-		right:   "║",
-		corners: [4]string{"╔", "╗", "╚", "╝"},
-	}
-
-	roundBoxDecorator = Decorator{
-		top:     "─",
-		bottom:  "─",
-		left:    "│",
-		right:   "│",
-		corners: [4]string{"╭", "╮", "╰", "╯"},
-	}
-
-	dottedBoxDecorator = Decorator{
-		top:     "┈",
-		bottom:  "┈",
-		left:    "┊",
-		right:   "┊",
-		corners: [4]string{"·", "·", "·", "·"},
-	}
-
-	stars3DDecorator = Decorator{
-		top:     "★",
-		bottom:  "★",
-		left:    "★",
-		right:   "★",
-		corners: [4]string{"★", "★", "★", "★"},
-		pre: func(s string) string {
-			return addShadow(s)
-		},
-	}
-
-	wavyDecorator = Decorator{
-		top:     "～",
-		bottom:  "～",
-		left:    "※",
-		right:   "※",
-		corners: [4]string{"∿", "∿", "∿", "∿"},
-	}
+// Bracketed paste control sequences
+const (
+	bracketedPasteOn  = "\x1b[?2004h"
+	bracketedPasteOff = "\x1b[?2004l"
+	pasteStart        = "\x1b[200~"
+	pasteEnd          = "\x1b[201~"
+	pastePreviewLines = 5
 )
 
 func newAppConfig() *AppConfig {
 	return &AppConfig{
-		categories: []StyleCategory{
-			{
-				name:        "Classic",
-				description: "Traditional ASCII art styles",
-				styles: []Style{
-					{"Standard", "Classic ASCII art", "", Decorator{}},
-					{"Big", "Large block letters", "big", Decorator{}},
-					{"Slim", "Thin elegant letters", "slim", Decorator{}},
-					{"Small", "Compact letters", "small", Decorator{}},
-				},
-			},
-			{
-				name:        "Boxed",
-				description: "Styles with different types of borders",
-				styles: []Style{
-					{"Single Box", "Single-line border", "", boxDecorator},
-					{"Double Box", "Double-line border", "", doubleBoxDecorator},
-					{"Round Box", "Rounded corners", "", roundBoxDecorator},
-					{"Dotted Box", "Dotted border style", "", dottedBoxDecorator},
-				},
-			},
-			{
-				name:        "3D Effects",
-				description: "Three-dimensional looking styles",
-				styles: []Style{
-					{"Shadow", "Letters with shadow", "shadow", Decorator{}},
-					{"Deep 3D", "Enhanced 3D effect", "standard", stars3DDecorator},
-					{"Block 3D", "Solid 3D blocks", "block", Decorator{
-						pre: func(s string) string { return addShadow(s) },
-					}},
-				},
-			},
-			{
-				name:        "Decorative",
-				description: "Fancy and ornamental styles",
-				styles: []Style{
-					{"Wavy", "Wavy border style", "", wavyDecorator},
-					{"Stars", "Starred border", "", stars3DDecorator},
-					{"Script", "Cursive style", "script", Decorator{}},
-					{"Bubble", "Rounded bubble letters", "bubble", roundBoxDecorator},
-				},
-			},
-		},
-		colors: []ColorScheme{
-			{
-				"Ocean",
-				color.New(color.FgBlue),
-				color.New(color.FgCyan),
-				color.New(color.FgHiBlue),
-			},
-			{
-				"Forest",
-				color.New(color.FgGreen),
-				color.New(color.FgHiGreen),
-				color.New(color.FgWhite),
-			},
-			{
-				"Sunset",
-				color.New(color.FgRed),
-				color.New(color.FgYellow),
-				color.New(color.FgHiRed),
-			},
-			{
-				"Royal",
-				color.New(color.FgMagenta),
-				color.New(color.FgHiMagenta),
-				color.New(color.FgWhite),
-			},
-			{
-				"Monochrome",
-				color.New(color.FgWhite),
-				color.New(color.FgHiWhite),
-				color.New(color.FgBlack),
-			},
-			{
-				"Neon",
-				color.New(color.FgHiGreen),
-				color.New(color.FgHiYellow),
-				color.New(color.FgHiCyan),
-			},
-			{
-				"Rainbow",
-				color.New(color.FgRed),
-				color.New(color.FgGreen),
-				color.New(color.FgBlue),
-			},
-		},
+		categories: asciiart.DefaultCategories(),
+		colors:     asciiart.DefaultColorSchemes(),
 	}
 }
 
-func main() {
+// subcommands maps command names to their entry points. Each receives
+// the arguments following the command name.
+var subcommands = map[string]func(config *AppConfig, args []string){
+	"stopwatch":       runStopwatch,
+	"on-exit":         runOnExit,
+	"stats":           runStats,
+	"version":         runVersion,
+	"doctor":          runDoctor,
+	"setup":           runSetup,
+	"clocks":          runClocks,
+	"board":           runBoard,
+	"gh":              runGitHub,
+	"ci-stage":        runCIStage,
+	"release":         runRelease,
+	"fonts":           runFonts,
+	"build":           runBuild,
+	"serve":           runServe,
+	"gallery":         runGallery,
+	"healthcheck":     runHealthcheck,
+	"convert-charset": runConvertCharset,
+	"shuffle":         runShuffle,
+	"render":          runRender,
+	"list":            runList,
+	"preview":         runPreview,
+	"image":           runImage,
+	"code":            runCode,
+	"fingerprint":     runFingerprint,
+}
+
+// parseInterspersed parses flags that may appear before, between or
+// after positional arguments and returns the positional ones.
+func parseInterspersed(flags *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		flags.Parse(args)
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...)
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// loadAppConfig builds the configuration from the built-in styles, the
+// theme files and the config files. A user config that fails to load is
+// returned with its error.
+func loadAppConfig() (*AppConfig, *UserConfig, error) {
 	config := newAppConfig()
+	config.locale, _ = resolveLocale("")
+	config.loadThemes()
+	loadEffectPlugins()
+	userConfig, err := loadUserConfig()
+	if userConfig != nil {
+		userConfig.customize(config)
+	}
+	return config, userConfig, err
+}
+
+func main() {
+	config, userConfig, configErr := loadAppConfig()
+
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			run(config, os.Args[2:])
+			return
+		}
+	}
+
+	// Without a command the flags are render's plus the listing and
+	// -image ones, and what is not given is prompted for, as before there
+	// were commands
+	runFlags(config, userConfig, configErr, flag.CommandLine, os.Args[1:], true)
+}
 
+// runRender is the render command: the flags for rendering text, without
+// prompts unless -interactive is given, printing the art alone
+func runRender(config *AppConfig, args []string) {
+	userConfig, err := loadUserConfig()
+	runFlags(config, userConfig, err, flag.NewFlagSet("render", flag.ExitOnError), args, false)
+}
+
+// runFlags renders what the flags in args ask for. legacy is the command
+// line without a command, which also takes -list, -preview, -list-art,
+// -list-fonts, -list-effects and -image, and prompts by default.
+func runFlags(config *AppConfig, userConfig *UserConfig, configErr error, flags *flag.FlagSet, args []string, legacy bool) {
 	// Command line flags
-	outputFile := flag.String("output", "", "Output file path (optional)")
-	showColors := flag.Bool("color", true, "Enable colored output")
-	listStyles := flag.Bool("list", false, "List all available styles")
-	previewMode := flag.Bool("preview", false, "Preview all styles with sample text")
-	categoryFlag := flag.Int("category", 0, "Style category number")
-	styleFlag := flag.Int("style", 0, "Style number within category")
-	colorFlag := flag.Int("colorscheme", 0, "Color scheme number")
-	interactiveMode := flag.Bool("interactive", true, "Interactive mode")
-	flag.Parse()
-
-	printWelcomeBanner()
+	outputFile := flags.String("output", "", "Output file path or target such as webhook:https://... (optional)")
+	showColors := flags.Bool("color", true, "Enable colored output")
+	categoryFlag, categoryName := selectionFlag(flags, "category", "Style category, by `number` or name")
+	styleFlag, styleName := selectionFlag(flags, "style", "Style, by `number` within the category or by name")
+	colorFlag, colorName := selectionFlag(flags, "colorscheme", "Color scheme, by `number` or name")
+	randomFlag := flags.Bool("random", false, "Pick a random style and color scheme, except any given")
+	randomSeedFlag := flags.Uint64("random-seed", 0, "Seed for -random, to repeat a pick (implies -random)")
+	fgFlag := flags.String("fg", "", "Draw in one hex RGB color such as \"#ff6600\" instead of a color scheme")
+	gradientFlag := flags.String("gradient", "", "Color with a gradient: a preset (fire, ocean, pride, ...) or hex stops like \"#ff0000:#0000ff\"")
+	gradientDirFlag := flags.String("gradient-dir", asciiart.GradientHorizontal, "Gradient direction: horizontal, vertical or diagonal")
+	rainbowFlag := flags.Bool("rainbow", false, "Cycle through the hues character by character, like lolcat")
+	rainbowFreqFlag := flags.Float64("rainbow-freq", asciiart.DefaultRainbowFrequency, "How fast -rainbow changes hue")
+	rainbowPhaseFlag := flags.Float64("rainbow-phase", 0, "Where the -rainbow hue cycle starts")
+	interactiveMode := flags.Bool("interactive", legacy, "Interactive mode")
+	tuiFlag := flags.Bool("tui", true, "In interactive mode on a terminal, pick styles and colors with a live preview")
+	numberMode := flags.Bool("number", false, "Format input as a number before rendering")
+	thousandsFlag := flags.String("thousands", ",", "Thousands separator for -number (empty to disable; \".\" makes \",\" the decimal point)")
+	padFlag := flags.Int("pad", 0, "Minimum width of the number for -number")
+	zerosFlag := flags.Bool("zeros", false, "Pad -number output with leading zeros")
+	decimalsFlag := flags.Int("decimals", -1, "Decimal places to round -number to (-1 keeps input)")
+	localeFlag := flags.String("locale", "", "Locale for {date:%A}, {time:%X} and {number} placeholders, such as de_DE (default: $LC_ALL, $LC_TIME or $LANG)")
+	notifyFlag := flags.Bool("notify", false, "Also send a desktop notification with the text")
+	seasonalFlag := flags.Bool("seasonal", false, "Decorate automatically for the current season or holiday")
+	targetFlag := flags.String("target", "", "Check output against a destination profile: chat, ci, motd, printer, teletext (or list)")
+	borderFlag := flags.String("border-char", "", "Draw the border with this character or emoji")
+	borderCharsFlag := flags.String("border-chars", "", "Border pieces as \"horizontal,vertical,TL,TR,BL,BR\", e.g. \"─,│,┌,┐,└,┘\"")
+	fillFlag := flags.String("fill-char", "", "Pad lines inside the border with this character or emoji")
+	brailleFlag := flags.Bool("braille", false, "Redraw the art in Braille dots, 2x4 per character, for a finer look")
+	bubbleFlag := flags.String("bubble", "", "Put the art in a speech bubble spoken by -figure: say or think")
+	figureFlag := flags.String("figure", defaultFigure, "Figure under the -bubble: cow, tux, a .cow file name or path")
+	artFlag := flags.String("art", "", "Draw a figure from the clipart library next to the banner (-list-art shows them)")
+	artPositionFlag := flags.String("art-position", artLeft, "Where the -art figure goes: left, right, above or below")
+	backgroundFlag := flags.String("background", "", "Show a pattern through the blanks of the art: pattern:NAME (list backgrounds shows them) or file:PATH")
+	backgroundColorFlag := flags.String("background-color", defaultBackgroundColor, "Color of the -background: a name such as gray or blue, or hex RGB")
+	signatureFlag := flags.String("signature", "", "Add a small credit line, flush right, such as \"– generated by ascii-art\"")
+	signaturePositionFlag := flags.String("signature-position", signatureBelow, "Where the -signature goes: below the border, or inside it")
+	signatureColorFlag := flags.String("signature-color", defaultSignatureColor, "Color of the -signature: a name such as gray or cyan, or hex RGB")
+	fontFlag := flags.String("font", "", "Render in this font instead of the style's, by name (-list-fonts shows them)")
+	effectFlag := flags.String("effect", "", "Apply effects after the border, comma-separated, in order (-list-effects shows them)")
+	blocksFlag := flags.Bool("blocks", false, "Draw text or -image with colored ▀/▄ half blocks, two pixels per character")
+	teletextFlag := flags.Bool("teletext", false, "Draw text or -image with 2x3 teletext mosaics in the 8 teletext colors")
+	sauceFlag := flags.Bool("sauce", false, "Append a SAUCE record to -format ans or text output")
+	sauceTitleFlag := flags.String("sauce-title", "", "Title for the SAUCE record (implies -sauce)")
+	sauceAuthorFlag := flags.String("sauce-author", "", "Author for the SAUCE record (implies -sauce)")
+	sauceGroupFlag := flags.String("sauce-group", "", "Group for the SAUCE record (implies -sauce)")
+	outputHashFlag := flags.Bool("output-hash", false, "Name the -output file by a short hash of its content")
+	ansFlag := flags.String("ans", "", "Show a classic ANSI art file (.ans, code page 437, or .xb and .bin) instead of text")
+	alignFlag := flags.String("align", alignLeft, "Align the art within the terminal or -width: left, center or right")
+	widthFlag := flags.Int("width", 0, "Width in characters for -image, or to wrap text art at (default: terminal width)")
+	formatFlag := flags.String("format", "", "Output format: text, ansi, ans (CP437 ANSI art), html with inline CSS colors, or svg (default: colors on a terminal)")
+	filterFlag := flags.String("filter", "", "Word filter: mask, reject or off (default: mask for -stdin-json and -repl-plain, otherwise off)")
+	presetFlag := flags.String("preset", "", "Use a look saved with -save-preset; flags given still win")
+	savePresetFlag := flags.String("save-preset", "", "Save the style, colors, font, border, fill, width and effects as a preset with this name")
+	funFlag := flags.String("fun", "", "Kid-friendly preset: party, birthday, space or dino (\"list\" shows them)")
+	stdinJSONFlag := flags.Bool("stdin-json", false, "Answer JSON render requests, one per line on stdin")
+	replPlainFlag := flags.Bool("repl-plain", false, "Line protocol for shell co-processes with END markers")
+	animateFlag := flags.String("animate", "", "Print the art gradually: typewriter or lines")
+	speedFlag := flags.Int("speed", defaultAnimateSpeed, "Characters (or lines) per second for -animate")
+	cue := addCueFlags(flags, "when -animate finishes")
+	fpsFlag, cpuLimitFlag := addFrameFlags(flags)
+	batchFlag := flags.String("batch", "", "Render each line of this file as its own banner (- for stdin)")
+	outDirFlag := flags.String("out-dir", "", "Write each banner to its own file in this directory")
+	auditFlag := flags.String("audit-log", "", "Record each -stdin-json or -repl-plain render to this rotating log file")
+	auditSizeFlag := flags.Int("audit-max-size", auditMaxSizeMB, "Rotate the audit log once it reaches this many MB")
+	auditRedactFlag := flags.Bool("audit-redact", false, "Keep only a hash of the text in the audit log")
+	listStyles, previewMode := new(bool), new(bool)
+	listArtFlag, listFontsFlag, listEffectsFlag := new(bool), new(bool), new(bool)
+	imageFlag, imageOptions := new(string), imageFlags{}
+	if legacy {
+		listStyles = flags.Bool("list", false, "List all available styles")
+		previewMode = flags.Bool("preview", false, "Preview all styles with sample text")
+		listArtFlag = flags.Bool("list-art", false, "List the clipart library for -art")
+		listFontsFlag = flags.Bool("list-fonts", false, "List every font with a sample, the text given or \""+fontListSample+"\"")
+		listEffectsFlag = flags.Bool("list-effects", false, "List the built-in and plugin effects for -effect")
+		imageFlag = flags.String("image", "", "Convert a PNG or JPEG image to ASCII art instead of text")
+		imageOptions = addImageFlags(flags)
+	}
+	flags.Parse(args)
+
+	if isNotExist(configErr) && *interactiveMode && isatty.IsTerminal(os.Stdin.Fd()) {
+		userConfig, configErr = runSetupWizard(config), nil
+	}
+	if configErr != nil && !isNotExist(configErr) {
+		fmt.Printf("Error reading config: %v\n", configErr)
+	}
+	if userConfig != nil {
+		set := make(map[string]bool)
+		flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		userConfig.applyDefaults(set, showColors, categoryFlag, styleFlag, colorFlag, formatFlag, signatureFlag)
+	}
+	if err := config.resolveSelection(categoryFlag, styleFlag, colorFlag, *categoryName, *styleName, *colorName); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *randomFlag || *randomSeedFlag != 0 {
+		given := make(map[string]bool)
+		flags.Visit(func(f *flag.Flag) { given[f.Name] = true })
+		config.pickRandom(newRand(*randomSeedFlag), given, categoryFlag, styleFlag, colorFlag)
+	}
+
+	if *localeFlag != "" {
+		config.setLocale(*localeFlag)
+	}
+
+	numberFormat := NumberFormat{
+		separator: *thousandsFlag,
+		width:     *padFlag,
+		zeros:     *zerosFlag,
+		decimals:  *decimalsFlag,
+	}
+
+	options := RenderOptions{
+		outputFile:  *outputFile,
+		outputHash:  *outputHashFlag,
+		showColors:  *showColors,
+		notify:      *notifyFlag,
+		seasonal:    *seasonalFlag,
+		target:      *targetFlag,
+		borderChar:  *borderFlag,
+		fillChar:    *fillFlag,
+		braille:     *brailleFlag,
+		blocks:      *blocksFlag,
+		teletext:    *teletextFlag,
+		category:    *categoryFlag,
+		style:       *styleFlag,
+		colorScheme: *colorFlag,
+		format:      *formatFlag,
+	}
+	if err := checkAnimation(*animateFlag, *speedFlag); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	options.animate, options.speed, options.cue = *animateFlag, *speedFlag, *cue
+	options.frames = mustFrameLimiter(*fpsFlag, *cpuLimitFlag)
+	options.wrapWidth = *widthFlag
+	options.fitTerminal = *widthFlag == 0 && options.outputFile == ""
+	if err := checkAlign(*alignFlag); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	options.align = *alignFlag
+	switch options.format {
+	case "", "text", "ansi", "ans", "html", "svg":
+	default:
+		fmt.Printf("Error: unknown format %q (use text, ansi, ans, html or svg)\n", options.format)
+		os.Exit(1)
+	}
+	if options.blocks && options.teletext {
+		fmt.Println("Error: choose one of -blocks and -teletext")
+		os.Exit(1)
+	}
+	options.sauce = newSAUCE(*sauceFlag, *sauceTitleFlag, *sauceAuthorFlag, *sauceGroupFlag)
+	if options.sauce != nil && options.format != "ans" && options.format != "text" {
+		fmt.Println("Error: SAUCE records go with -format ans or text")
+		os.Exit(1)
+	}
+	if *bubbleFlag != "" {
+		bubble, err := newBubble(*bubbleFlag, *figureFlag)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		options.bubble = bubble
+	}
+	if *artFlag != "" {
+		clip, err := findClipart(*artFlag)
+		if err == nil {
+			err = checkArtPosition(*artPositionFlag)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		options.clipart, options.artPosition = clip, *artPositionFlag
+	}
+	if *backgroundFlag != "" {
+		background, err := newBackground(*backgroundFlag, *backgroundColorFlag)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		options.background = background
+	}
+	if *signatureFlag != "" {
+		signature, err := newSignature(*signatureFlag, *signaturePositionFlag, *signatureColorFlag)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		options.signature = signature
+	}
+	if *fontFlag != "" {
+		if err := checkFont(*fontFlag); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		options.font = *fontFlag
+	}
+	if *effectFlag != "" {
+		for _, name := range strings.Split(*effectFlag, ",") {
+			name = strings.TrimSpace(name)
+			if _, err := findEffect(name); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			options.effects = append(options.effects, name)
+		}
+	}
+	if *borderCharsFlag != "" {
+		pieces, err := parseBorderChars(*borderCharsFlag)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		options.borderChars = pieces
+	}
+	if *presetFlag != "" {
+		given := make(map[string]bool)
+		flags.Visit(func(f *flag.Flag) { given[f.Name] = true })
+		preset, err := findPreset(*presetFlag)
+		if err == nil {
+			err = preset.apply(config, &options, given)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *savePresetFlag != "" {
+		path, err := savePreset(*savePresetFlag, config.newPreset(options))
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Saved preset %q to %s\n", *savePresetFlag, path)
+		if flags.NArg() == 0 {
+			return
+		}
+	}
+	if *fgFlag != "" {
+		rgb, err := asciiart.ParseHex(*fgFlag)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		scheme := asciiart.SolidScheme(*fgFlag, rgb.Color(options.colorDepth()))
+		options.fg = &scheme
+	}
+	if *gradientFlag != "" {
+		stops, err := asciiart.ParseGradient(*gradientFlag)
+		if err == nil {
+			options.gradient, err = asciiart.NewGradient(stops, *gradientDirFlag, options.colorDepth())
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *rainbowFlag {
+		if options.gradient != nil {
+			fmt.Println("Error: use either -gradient or -rainbow")
+			os.Exit(1)
+		}
+		options.gradient = asciiart.NewRainbow(*rainbowFreqFlag, *rainbowPhaseFlag, options.colorDepth())
+	}
+
+	filterMode := *filterFlag
+	if filterMode == "" {
+		filterMode = filterOff
+		if *stdinJSONFlag || *replPlainFlag {
+			filterMode = filterMask
+		}
+	}
+	config.setFilter(filterMode)
+
+	if *funFlag == "list" {
+		listFunPresets()
+		return
+	}
+	if *listArtFlag {
+		listClipart()
+		return
+	}
+	if *listFontsFlag {
+		sample := fontListSample
+		if flags.NArg() > 0 {
+			sample = strings.Join(flags.Args(), " ")
+		}
+		listFonts(sample)
+		return
+	}
+	if *listEffectsFlag {
+		listEffects()
+		return
+	}
+	if *funFlag != "" {
+		preset, err := findFunPreset(*funFlag)
+		if err == nil && options.fg == nil && options.gradient == nil {
+			options.gradient, err = preset.gradient()
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		style := preset.style()
+		options.preset = &style
+	}
+
+	if *stdinJSONFlag || *replPlainFlag {
+		config.setAudit(*auditFlag, *auditSizeFlag, *auditRedactFlag)
+	}
+
+	if *stdinJSONFlag {
+		runStdinJSON(config)
+		return
+	}
+
+	if *replPlainFlag {
+		runReplPlain(config, options)
+		return
+	}
+
+	if *imageFlag != "" {
+		renderImage(*imageFlag, imageOptions.options(*widthFlag), *blocksFlag, *teletextFlag, *outputFile)
+		return
+	}
+
+	if *ansFlag != "" {
+		// Only styles and colors asked for here reframe or recolor the
+		// art, not the defaults from config.yaml
+		given := make(map[string]bool)
+		flags.Visit(func(f *flag.Flag) { given[f.Name] = true })
+		if !given["category"] {
+			options.category = 0
+		}
+		if !given["colorscheme"] {
+			options.colorScheme = 0
+		}
+		renderANSArt(*ansFlag, config, options)
+		return
+	}
+
+	// Text piped in with no arguments is rendered once, like
+	// -interactive=false, with defaults instead of prompts
+	piped := flags.NArg() == 0 && *batchFlag == "" && stdinPiped()
+	batch := *batchFlag != "" || *outDirFlag != ""
+	if !piped && !batch && (legacy || *interactiveMode) {
+		printWelcomeBanner()
+	}
+	if !legacy && !*interactiveMode {
+		options.unattended()
+	}
 
 	if *listStyles {
 		config.listAvailableStyles()
@@ -227,254 +502,672 @@ func main() {
 	}
 
 	if *previewMode {
-		config.previewStyles()
+		config.previewStyles(previewSample)
+		return
+	}
+
+	if *targetFlag == "list" {
+		printTargetMatrix()
 		return
 	}
+	if *targetFlag != "" {
+		if _, err := findTargetProfile(*targetFlag); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	args = flags.Args()
+	if piped {
+		input, err := io.ReadAll(io.LimitReader(os.Stdin, maxRequestSize))
+		if err != nil {
+			fmt.Printf("Error reading input: %v\n", err)
+			os.Exit(1)
+		}
+		args = []string{strings.TrimSpace(string(input))}
+		*interactiveMode = false
+		options.unattended()
+		options.limited = true
+	}
+
+	if batch {
+		texts := args
+		if *batchFlag != "" {
+			lines, err := readBatch(*batchFlag)
+			if err != nil {
+				fmt.Printf("Error reading batch file: %v\n", err)
+				os.Exit(1)
+			}
+			texts = append(lines, texts...)
+		}
+		var number *NumberFormat
+		if *numberMode {
+			number = &numberFormat
+		}
+		runBatch(config, options, texts, *outDirFlag, number)
+		return
+	}
+
+	// On a terminal, styles and colors are picked beside a live preview
+	// instead of from numbered menus
+	var tui *picker
+	if *interactiveMode && *tuiFlag && options.preset == nil && isTerminal(os.Stdout) && isatty.IsTerminal(os.Stdin.Fd()) {
+		tui = newPicker(config, options)
+	}
 
 	// Main program loop
-	
-for {
-    if !*interactiveMode {
-        text := strings.Join(flag.Args(), " ")
-        if text == "" {
-            fmt.Println("Error: No text provided in non-interactive mode")
-            os.Exit(1)
-        }
-        processText(text, config, outputFile, showColors, categoryFlag, styleFlag, colorFlag)
-        return
-    }
-
-    text := getUserInput()
-    if strings.ToLower(strings.TrimSpace(text)) == "q" {
-        fmt.Println("\nGoodbye! Thanks for using ASCII Art Generator! 😊✌️")
-        return
-    }
-
-    processText(text, config, outputFile, showColors, categoryFlag, styleFlag, colorFlag)
-}
-}
-
-func processText(text string, config *AppConfig, outputFile *string, showColors *bool, categoryFlag, styleFlag, colorFlag *int) {
-    _, style := config.getStyleSelection(*categoryFlag, *styleFlag)
-    colorScheme := config.getColorSelection(*colorFlag, *showColors)
-
-    asciiArt := config.generateArt(text, style, colorScheme)
-
-    if *outputFile != "" {
-        if err := saveToFile(*outputFile, asciiArt); err != nil {
-            fmt.Printf("Error saving to file: %v\n", err)
-            os.Exit(1)
-        }
-        fmt.Printf("ASCII art saved to: %s\n", *outputFile)
-    } else {
-        fmt.Println("\nYour ASCII Art:")
-        fmt.Println(asciiArt)
-        
-        // Add pause and prompt
-        fmt.Print("\nPress Enter to continue or type 'q' to quit: ")
-        reader := bufio.NewReader(os.Stdin)
-        input, _ := reader.ReadString('\n')
-        if strings.ToLower(strings.TrimSpace(input)) == "q" {
-            fmt.Println("\nGoodbye! Thanks for using ASCII Art Generator!")
-            os.Exit(0)
-        }
-    }
+	options.menuChoices = &menuChoices{}
+	previous := ""
+	for {
+		if !*interactiveMode {
+			text := strings.Join(args, " ")
+			if text == "" {
+				fmt.Println("Error: No text provided in non-interactive mode")
+				os.Exit(1)
+			}
+			if *numberMode {
+				formatted, err := formatNumber(text, numberFormat)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+				text = formatted
+			}
+			processText(text, config, options)
+			return
+		}
+
+		prompt := "Enter your text: "
+		if options.preset != nil {
+			prompt = "What should your banner say? "
+		}
+		var text string
+		if tui != nil {
+			var ok bool
+			if text, ok = tui.run(&options); !ok {
+				fmt.Println("\nGoodbye! Thanks for using ASCII Art Generator! 😊✌️")
+				return
+			}
+			// Only a paste puts line breaks in the picker's text
+			if strings.Contains(text, "\n") && !confirmPaste(bufio.NewReader(os.Stdin), text) {
+				continue
+			}
+		} else {
+			text = getUserInput(prompt)
+		}
+		if strings.ToLower(strings.TrimSpace(text)) == "q" {
+			fmt.Println("\nGoodbye! Thanks for using ASCII Art Generator! 😊✌️")
+			return
+		}
+		if strings.TrimSpace(text) == "!!" {
+			if previous == "" {
+				fmt.Println(color.YellowString("Nothing to repeat yet"))
+				continue
+			}
+			text = previous
+		}
+		if name, ok := strings.CutPrefix(strings.TrimSpace(text), ":preset"); ok && (name == "" || name[0] == ' ') {
+			preset, ok := Preset{}, false
+			if name = strings.TrimSpace(name); name == "" {
+				preset, ok = browsePresets()
+			} else if p, err := findPreset(name); err != nil {
+				fmt.Println(color.RedString("Error: %v", err))
+			} else {
+				preset, ok = p, true
+			}
+			if ok {
+				if err := preset.apply(config, &options, nil); err != nil {
+					fmt.Println(color.RedString("Error: %v", err))
+				} else if tui != nil {
+					tui = newPicker(config, options)
+				}
+			}
+			continue
+		}
+		if strings.TrimSpace(text) == exportAllCommand {
+			if tui == nil {
+				fmt.Println(color.YellowString("Only the full-screen picker has tabs to export"))
+			} else {
+				tui.exportAll(options)
+			}
+			continue
+		}
+		if strings.TrimSpace(text) == ":art" {
+			options.clipart = browseClipart(bufio.NewReader(os.Stdin))
+			options.artPosition = *artPositionFlag
+			continue
+		}
+		if *numberMode {
+			formatted, err := formatNumber(text, numberFormat)
+			if err != nil {
+				fmt.Println(color.RedString("Error: %v", err))
+				continue
+			}
+			text = formatted
+		}
+
+		previous = text
+		processText(text, config, options)
+	}
 }
 
-func printWelcomeBanner() {
-	banner := figure.NewFigure("ASCII Art", "big", true).String()
-	fmt.Println(color.CyanString(banner))
-	fmt.Println(color.HiYellowString(fmt.Sprintf(framePatternTop, "Generator")))
-	fmt.Println(color.HiYellowString(framePatternBottom))
-	fmt.Println("\nType 'q' to quit or Ctrl+C to exit")
-	fmt.Println()
+// RenderOptions holds the command line choices that apply to every
+// rendered text
+type RenderOptions struct {
+	outputFile  string
+	outputHash  bool
+	showColors  bool
+	notify      bool
+	seasonal    bool
+	target      string
+	borderChar  string
+	borderChars []string // From -border-chars: horizontal, vertical, TL, TR, BL, BR
+	fillChar    string
+	braille     bool
+	blocks      bool
+	teletext    bool
+	bubble      *Bubble     // From -bubble and -figure
+	clipart     *Clipart    // From -art, or picked with :art
+	artPosition string      // -art-position
+	background  *Background // From -background, under the art in its own color
+	signature   *Signature  // From -signature, added last in its own color
+	effects     []string    // From -effect, applied in order after the border
+	font        string      // From -font, replaces the style's font
+	category    int
+	style       int
+	colorScheme int
+	fg          *asciiart.ColorScheme // From -fg, replaces the color scheme
+	gradient    *asciiart.Gradient    // From -gradient, replaces the color scheme
+	format      string                // text, ansi, ans, html or svg; empty colors terminal output only
+	sauce       *asciiart.SAUCE       // From -sauce; appended to ans and text output
+	preset      *asciiart.Style       // From -fun, replaces the style selection
+	bare        bool                  // Print the art alone, without a heading or pause
+	menuChoices *menuChoices          // The last picks from the menus, offered again as defaults
+	animate     string                // -animate mode for terminal output
+	speed       int                   // -animate characters or lines per second
+	frames      *frameLimiter         // -fps and -cpu-limit for -animate
+	cue         SoundCue              // -bell and -sound, played once -animate finishes
+	limited     bool                  // Enforce the render limits, for -batch and piped input
+	wrapWidth   int                   // Wrap text so the art fits; 0 never wraps
+	fitTerminal bool                  // Wrap at the terminal's width instead
+	align       string                // -align: left, center or right within the wrap width
 }
 
-func getUserInput() string {
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print(color.GreenString("Enter your text: "))
-	text, err := reader.ReadString('\n')
-	if err != nil {
-		fmt.Printf("Error reading input: %v\n", err)
-		os.Exit(1)
+// parseBorderChars splits a -border-chars value into its six pieces
+func parseBorderChars(value string) ([]string, error) {
+	pieces := strings.Split(value, ",")
+	if len(pieces) != 6 {
+		return nil, fmt.Errorf("-border-chars needs 6 comma-separated pieces (horizontal,vertical,TL,TR,BL,BR), got %d", len(pieces))
 	}
-	return strings.TrimSpace(text)
+	return pieces, nil
 }
 
-func (config *AppConfig) generateArt(text string, style Style, colorScheme *ColorScheme) string {
-	var asciiArt string
-	if style.font != "" {
-		asciiArt = figure.NewFigure(text, style.font, true).String()
-	} else {
-		asciiArt = text
+// overrideDecorator applies -border-char, -border-chars and -fill-char to
+// a decorator
+func (o RenderOptions) overrideDecorator(d asciiart.Decorator) asciiart.Decorator {
+	if o.borderChar != "" {
+		d.Top, d.Bottom, d.Left, d.Right = o.borderChar, o.borderChar, o.borderChar, o.borderChar
+		d.Corners = [4]string{o.borderChar, o.borderChar, o.borderChar, o.borderChar}
+		d.Border = nil
+	}
+	if p := o.borderChars; p != nil {
+		d.Top, d.Bottom, d.Left, d.Right = p[0], p[0], p[1], p[1]
+		d.Corners = [4]string{p[2], p[3], p[4], p[5]}
+		d.Border = nil
+	}
+	if o.fillChar != "" {
+		d.Fill = o.fillChar
+	}
+	return d
+}
+
+// restyle returns style with the font, border and effects the options
+// ask for, in the order they apply
+func (o RenderOptions) restyle(style asciiart.Style) asciiart.Style {
+	if o.font != "" {
+		style.Font = o.font
+	}
+	style.Decorator = o.overrideDecorator(style.Decorator)
+	if o.braille {
+		style = withBraille(style)
+	}
+	if o.bubble != nil {
+		style = withBubble(style, o.bubble)
+	}
+	if o.clipart != nil {
+		style = withClipart(style, o.clipart, o.artPosition)
+	}
+	for _, name := range o.effects {
+		style = withEffect(style, name, asciiart.LookupEffect(name))
+	}
+	if o.background != nil {
+		style = withBackground(style, o.background)
 	}
+	if o.signature != nil {
+		style = withSignature(style, o.signature)
+	}
+	if o.align == alignCenter || o.align == alignRight {
+		style = withAlign(style, o.align, o.alignWidth)
+	}
+	return style
+}
 
-	if style.decorator.pre != nil {
-		asciiArt = style.decorator.pre(asciiArt)
+// unattended fills in the choices that would otherwise be prompted for,
+// for piped and batch input: the first category and style, and no colors
+// unless a scheme was given
+func (o *RenderOptions) unattended() {
+	o.bare = true
+	o.category, o.style = max(o.category, 1), max(o.style, 1)
+	if o.colorScheme == 0 && o.fg == nil && o.gradient == nil {
+		o.showColors = false
 	}
+}
 
-	if style.decorator.top != "" || style.decorator.bottom != "" || style.decorator.left != "" || style.decorator.right != "" {
-		asciiArt = applyDecorator(asciiArt, style.decorator)
+// print writes art to stdout, animated if -animate was given. An
+// animation lays the art out again with relayout if the terminal is
+// resized while it plays.
+func (o RenderOptions) print(art string, relayout func() string) {
+	if o.animate != "" {
+		animate(os.Stdout, relayout, o.animate, o.speed, o.frames)
+		o.cue.play()
+		return
 	}
+	if o.sauce != nil {
+		// The record must be the last thing written
+		fmt.Print(art)
+		return
+	}
+	fmt.Println(art)
+}
 
-	if style.decorator.post != nil {
-		asciiArt = style.decorator.post(asciiArt)
+// wrapAt returns the width to wrap text art at. The terminal is measured
+// afresh each time, so resizing it between renders is picked up.
+func (o RenderOptions) wrapAt() int {
+	if o.fitTerminal {
+		if width, _, ok := terminalSize(); ok {
+			return width
+		}
 	}
+	return o.wrapWidth
+}
 
-	if colorScheme != nil {
-		asciiArt = applyColorScheme(asciiArt, colorScheme)
+// colorDepth returns the colors custom colors and gradients are drawn
+// in: what the terminal can show, or fewer when the -target shows fewer
+func (o RenderOptions) colorDepth() int {
+	depth := detectColorDepth()
+	if profile, err := findTargetProfile(o.target); o.target != "" && err == nil {
+		depth = profile.limitDepth(depth)
 	}
+	return depth
+}
 
-	return asciiArt
+// alignWidth returns the width to -align the art within: the wrap width,
+// or the terminal's when the art is not wrapped, such as for files
+func (o RenderOptions) alignWidth() int {
+	if width := o.wrapAt(); width > 0 {
+		return width
+	}
+	if width, _, ok := terminalSize(); ok {
+		return width
+	}
+	return 0
 }
 
-func applyDecorator(text string, d Decorator) string {
-	lines := strings.Split(text, "\n")
-	maxWidth := 0
-	for _, line := range lines {
-		width := utf8.RuneCountInString(line)
-		if width > maxWidth {
-			maxWidth = width
+func processText(text string, config *AppConfig, options RenderOptions) {
+	text, err := config.expandTemplate(text)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	text, err = config.filter.apply(text)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	category, style := funCategory, asciiart.Style{}
+	if options.preset != nil {
+		style = *options.preset
+	} else {
+		category, style = config.getStyleSelection(options.category, options.style, options.menuChoices)
+	}
+	colorScheme, gradient := options.fg, options.gradient
+	if !options.showColors {
+		colorScheme, gradient = nil, nil
+	} else if colorScheme == nil && gradient == nil {
+		colorScheme = config.getColorSelection(options.colorScheme, options.showColors, options.menuChoices)
+	}
+
+	if options.seasonal {
+		style, colorScheme = config.seasonalStyle(style, colorScheme)
+	}
+	base := style
+	style = options.restyle(style)
+	unwrapped := text
+	text = renderer.Wrap(text, style, options.wrapAt())
+	var plain string
+	if options.limited {
+		if plain, err = config.checkRenderLimits(text, style); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	} else {
+		plain = config.generateArt(text, style, nil)
+	}
+	// That render reported any missing fonts and glyphs; the rest of the
+	// renders of the same text stay quiet
+	config = config.withWarnings(nil)
+
+	if options.target != "" {
+		profile, _ := findTargetProfile(options.target)
+		colorScheme = profile.constrainColors(colorScheme)
+		if profile.colorDepth == colorDepthNone {
+			gradient = nil
+		}
+		shown := plain
+		if options.teletext {
+			shown = asciiart.RasterizeArt(plain, nil).Teletext(0)
+		}
+		profile.warn(shown)
+	}
+
+	// The background pattern and the signature keep their own colors
+	// only where the art is colored cell by cell, not in half blocks or
+	// mosaics
+	layered := (options.background != nil || options.signature != nil) && (colorScheme != nil || gradient != nil)
+	// overlay colors the layers of plain, the art of text, over c
+	overlay := func(text, plain string, c asciiart.Colorizer, forced bool) asciiart.Colorizer {
+		if options.background != nil {
+			bare := options
+			bare.background = nil
+			c = options.background.colorizer(config.generateArt(text, bare.restyle(base), nil), c, forced)
+		}
+		if options.signature != nil {
+			c = options.signature.colorizer(plain, c, forced)
+		}
+		return c
+	}
+
+	// terminalArt draws text as it is shown on the terminal
+	terminalArt := func(text string) string {
+		if options.blocks {
+			return config.blockRaster(text, style, colorScheme, gradient).HalfBlocks(options.colorDepth())
+		}
+		if options.teletext {
+			return config.blockRaster(text, style, colorScheme, gradient).Teletext(options.colorDepth())
+		}
+		if layered {
+			plain := config.generateArt(text, style, nil)
+			return asciiart.Colorize(plain, overlay(text, plain, artColorizer(colorScheme, gradient), false))
+		}
+		if gradient != nil {
+			return config.artRenderer().RenderColorized(text, style, gradient)
+		}
+		return config.generateArt(text, style, colorScheme)
+	}
+	hookRender := HookRender{text: unwrapped, output: options.outputFile, format: options.format, category: category, style: style, scheme: colorScheme}
+	config.runHooks(hookPre, hookRender)
+	asciiArt := terminalArt(text)
+	recordRender(category, style, colorScheme)
+
+	variants := func() RenderedArt {
+		if options.blocks {
+			return config.blockVariants(text, style, colorScheme, gradient)
+		}
+		if options.teletext {
+			return config.teletextVariants(text, style, colorScheme, gradient)
+		}
+		if layered {
+			return config.layeredVariants(text, style, colorScheme, gradient, overlay)
+		}
+		if gradient != nil {
+			return config.gradientVariants(text, style, gradient)
+		}
+		return config.renderVariants(text, style, colorScheme)
+	}
+	if options.format != "" {
+		art := variants()
+		asciiArt = map[string]string{"text": art.Plain, "ansi": art.ANSI, "ans": ansArt(art.ANSI), "html": art.HTML, "svg": art.SVG}[options.format]
+	}
+	if options.sauce != nil {
+		asciiArt = withSAUCE(asciiArt, options.format, *options.sauce)
+	}
+	if options.animate != "" && options.outputFile == "" {
+		if err := checkRenderFrames(animationFrames(asciiArt, options.animate, options.speed, options.frames)); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
+
+	if options.notify {
+		thumbnail := notificationThumbnail(plain, artColorizer(colorScheme, gradient))
+		if err := sendNotification(notificationTitle, text, thumbnail); err != nil {
+			fmt.Printf("Error sending notification: %v\n", err)
+		}
+	}
+
+	// relayout wraps the text again for the terminal's current width
+	relayout := func() string {
+		if options.format != "" {
+			return asciiArt
 		}
+		return terminalArt(config.artRenderer().Wrap(unwrapped, style, options.wrapAt()))
 	}
 
-	result := d.corners[0] + strings.Repeat(d.top, maxWidth+2) + d.corners[1] + "\n"
+	if write, dest, ok := findOutputTarget(options.outputFile); ok {
+		if err := write(dest, variants()); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("ASCII art sent to: %s\n", options.outputFile)
+	} else if options.outputFile != "" {
+		path := options.outputFile
+		if options.outputHash {
+			path = hashedName(path, []byte(asciiArt))
+		}
+		if err := saveToFile(path, asciiArt); err != nil {
+			fmt.Printf("Error saving to file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("ASCII art saved to: %s\n", path)
+		hookRender.output = path
+	} else if !options.bare {
+		fmt.Println("\nYour ASCII Art:")
+	}
+	if options.outputFile == "" {
+		options.print(asciiArt, relayout)
+	}
+	hookRender.art = asciiArt
+	config.runHooks(hookPost, hookRender)
 
-	for _, line := range lines {
-		padding := strings.Repeat(" ", maxWidth-utf8.RuneCountInString(line))
-		result += d.left + " " + line + padding + " " + d.right + "\n"
+	if options.outputFile == "" && !options.bare {
+		// Add pause and prompt
+		fmt.Print("\nPress Enter to continue or type 'q' to quit: ")
+		reader := bufio.NewReader(os.Stdin)
+		input, _ := reader.ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(input)) == "q" {
+			fmt.Println("\nGoodbye! Thanks for using ASCII Art Generator!")
+			os.Exit(0)
+		}
 	}
+}
 
-	result += d.corners[2] + strings.Repeat(d.bottom, maxWidth+2) + d.corners[3]
+func printWelcomeBanner() {
+	banner := figure.NewFigure("ASCII Art", "big", true).String()
+	fmt.Println(color.CyanString(banner))
+	fmt.Println(color.HiYellowString(fmt.Sprintf(framePatternTop, "Generator")))
+	fmt.Println(color.HiYellowString(framePatternBottom))
+	fmt.Println("\nType 'q' to quit or Ctrl+C to exit")
+	fmt.Println()
+}
 
-	return result
+func getUserInput(prompt string) string {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print(color.GreenString(prompt))
+		text, pasted, err := readInputLine(reader)
+		if err != nil {
+			fmt.Printf("Error reading input: %v\n", err)
+			os.Exit(1)
+		}
+		if !pasted || !strings.Contains(text, "\n") {
+			return strings.TrimSpace(text)
+		}
+		if confirmPaste(reader, text) {
+			return strings.Trim(text, "\n")
+		}
+	}
 }
 
-func addShadow(text string) string {
-	lines := strings.Split(text, "\n")
-	result := make([]string, len(lines))
-	
-	for i, line := range lines {
-		if i < len(lines)-1 {
-			shadowLine := strings.Map(func(r rune) rune {
-				if r != ' ' {
-					return '░'
-				}
-				return ' '
-			}, line)
-			result[i] = line + "\n" + strings.Repeat(" ", 2) + shadowLine
-		} else {
-			result[i] = line
+// readInputLine reads one line of input. When the terminal supports
+// bracketed paste, a multi-line paste is collected up to the paste end
+// marker and returned as a single input.
+func readInputLine(reader *bufio.Reader) (string, bool, error) {
+	if isatty.IsTerminal(os.Stdin.Fd()) {
+		fmt.Print(bracketedPasteOn)
+		defer fmt.Print(bracketedPasteOff)
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", false, err
+	}
+	if !strings.Contains(line, pasteStart) {
+		return line, false, nil
+	}
+
+	buf := line
+	for !strings.Contains(buf, pasteEnd) {
+		next, err := reader.ReadString('\n')
+		if err != nil {
+			return "", false, err
 		}
+		buf += next
 	}
-	
-	return strings.Join(result, "\n")
+
+	buf = strings.NewReplacer(pasteStart, "", pasteEnd, "", "\r\n", "\n", "\r", "\n").Replace(buf)
+	return strings.TrimRight(buf, "\n"), true, nil
 }
 
-func applyColorScheme(text string, cs *ColorScheme) string {
+// confirmPaste shows a preview of pasted text and asks whether to render it.
+func confirmPaste(reader *bufio.Reader, text string) bool {
 	lines := strings.Split(text, "\n")
-	var result []string
-	
+	fmt.Println(color.CyanString("\nPasted %d lines:", len(lines)))
 	for i, line := range lines {
-		colorIndex := i % 3
-		var colored string
-		switch colorIndex {
-		case 0:
-			colored = cs.primary.Sprint(line)
-		case 1:
-			colored = cs.secondary.Sprint(line)
-		case 2:
-			colored = cs.background.Sprint(line)
+		if i == pastePreviewLines {
+			fmt.Println(color.HiBlackString("  ... (%d more)", len(lines)-i))
+			break
 		}
-		result = append(result, colored)
+		fmt.Println(color.HiBlackString("  │ ") + line)
 	}
-	
-	return strings.Join(result, "\n")
+
+	fmt.Print("Render this text? [Y/n]: ")
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
+func (config *AppConfig) generateArt(text string, style asciiart.Style, colorScheme *asciiart.ColorScheme) string {
+	return config.artRenderer().Render(text, style, colorScheme)
+}
+
+// artRenderer is the renderer config draws the art with
+func (config *AppConfig) artRenderer() *asciiart.Renderer {
+	if config.renderer != nil {
+		return config.renderer
+	}
+	return renderer
+}
+
+// withWarnings returns a copy of config that writes font and glyph
+// warnings to w instead, or drops them when w is nil
+func (config *AppConfig) withWarnings(w io.Writer) *AppConfig {
+	r := *config.artRenderer()
+	r.Warnings = w
+	copied := *config
+	copied.renderer = &r
+	return &copied
 }
 
 func (config *AppConfig) listAvailableStyles() {
 	fmt.Println(color.CyanString("\nAvailable Style Categories:"))
 	for i, category := range config.categories {
 		fmt.Printf("\n%d. %s - %s\n", i+1,
-			color.BlueString(category.name),
-			color.YellowString(category.description))
-		for j, style := range category.styles {
+			color.BlueString(category.Name),
+			color.YellowString(category.Description))
+		for j, style := range category.Styles {
 			fmt.Printf("   %d.%d %s - %s\n", i+1, j+1,
-				color.HiWhiteString(style.name),
-				color.HiBlackString(style.description))
+				color.HiWhiteString(style.Name),
+				color.HiBlackString(style.Description))
 		}
 	}
 
 	fmt.Println(color.CyanString("\nAvailable Color Schemes:"))
 	for i, scheme := range config.colors {
-		fmt.Printf("%d. %s\n", i+1, scheme.primary.Sprint(scheme.name))
+		fmt.Printf("%d. %s\n", i+1, scheme.Primary.Sprint(scheme.Name))
 	}
 }
 
-func (config *AppConfig) previewStyles() {
-	sampleText := "Hello!"
+func (config *AppConfig) previewStyles(sampleText string) {
 	fmt.Println(color.CyanString("\nStyle Previews:"))
-	
+
 	for _, category := range config.categories {
-		fmt.Printf("\n%s - %s\n", 
-			color.BlueString(category.name),
-			color.YellowString(category.description))
-		
-		for _, style := range category.styles {
+		fmt.Printf("\n%s - %s\n",
+			color.BlueString(category.Name),
+			color.YellowString(category.Description))
+
+		for _, style := range category.Styles {
 			fmt.Printf("\n%s (%s):\n",
-				color.HiWhiteString(style.name),
-				color.HiBlackString(style.description))
+				color.HiWhiteString(style.Name),
+				color.HiBlackString(style.Description))
 			fmt.Println(config.generateArt(sampleText, style, nil))
 		}
 	}
 }
 
-func (config *AppConfig) getStyleSelection(categoryFlag, styleFlag int) (StyleCategory, Style) {
+// menuChoices are the numbers last picked from the style and color
+// menus, counting from 1, or 0 before the first pick
+type menuChoices struct {
+	category, style, colorScheme int
+}
+
+// getStyleSelection returns the style the flags select, or else asks for
+// one from the menus, where Enter alone picks the last choice again
+func (config *AppConfig) getStyleSelection(categoryFlag, styleFlag int, last *menuChoices) (asciiart.StyleCategory, asciiart.Style) {
 	if categoryFlag > 0 && categoryFlag <= len(config.categories) {
 		category := config.categories[categoryFlag-1]
-		if styleFlag > 0 && styleFlag <= len(category.styles) {
-			return category, category.styles[styleFlag-1]
+		if styleFlag > 0 && styleFlag <= len(category.Styles) {
+			return category, category.Styles[styleFlag-1]
 		}
 	}
 
 	fmt.Println("\nAvailable style categories:")
 	for i, category := range config.categories {
 		fmt.Printf("\n%d. %s - %s\n", i+1,
-			color.BlueString(category.name),
-			color.YellowString(category.description))
-		for j, style := range category.styles {
+			color.BlueString(category.Name),
+			color.YellowString(category.Description))
+		for j, style := range category.Styles {
 			fmt.Printf("   %d.%d %s - %s\n", i+1, j+1,
-				color.CyanString(style.name),
-				color.HiWhiteString(style.description))
+				color.CyanString(style.Name),
+				color.HiWhiteString(style.Description))
 		}
-}
-
-	var categoryChoice, styleChoice int
-	for {
-		fmt.Printf("\nSelect category (1-%d): ", len(config.categories))
-		if _, err := fmt.Scanf("%d", &categoryChoice); err == nil && 
-			categoryChoice >= 1 && categoryChoice <= len(config.categories) {
-			break
-		}
-		fmt.Println(color.RedString("Invalid selection. Please try again."))
-		bufio.NewReader(os.Stdin).ReadString('\n')
 	}
 
+	if last == nil {
+		last = &menuChoices{}
+	}
+	categoryChoice := promptChoice("\nSelect category", len(config.categories), last.category)
 	category := config.categories[categoryChoice-1]
-	for {
-		fmt.Printf("Select style (1-%d): ", len(category.styles))
-		if _, err := fmt.Scanf("%d", &styleChoice); err == nil && 
-			styleChoice >= 1 && styleChoice <= len(category.styles) {
-			break
-		}
-		fmt.Println(color.RedString("Invalid selection. Please try again."))
-		bufio.NewReader(os.Stdin).ReadString('\n')
+	styleDefault := 0
+	if categoryChoice == last.category {
+		styleDefault = last.style
 	}
+	styleChoice := promptChoice("Select style", len(category.Styles), styleDefault)
+	last.category, last.style = categoryChoice, styleChoice
 
-	return category, category.styles[styleChoice-1]
+	return category, category.Styles[styleChoice-1]
 }
 
-func (config *AppConfig) getColorSelection(colorFlag int, showColors bool) *ColorScheme {
+func (config *AppConfig) getColorSelection(colorFlag int, showColors bool, last *menuChoices) *asciiart.ColorScheme {
 	if !showColors {
 		return nil
 	}
@@ -483,28 +1176,65 @@ func (config *AppConfig) getColorSelection(colorFlag int, showColors bool) *Colo
 		return &config.colors[colorFlag-1]
 	}
 
-	// Clear input buffer before color selection
-	bufio.NewReader(os.Stdin).ReadString('\n')
-
 	fmt.Println("\nAvailable color schemes:")
 	for i, scheme := range config.colors {
-		fmt.Printf("%d. %s\n", i+1, scheme.primary.Sprint(scheme.name))
+		fmt.Printf("%d. %s\n", i+1, scheme.Primary.Sprint(scheme.Name))
 	}
 
-	var choice int
+	if last == nil {
+		last = &menuChoices{}
+	}
+	choice := promptChoice("\nSelect color scheme", len(config.colors), last.colorScheme)
+	last.colorScheme = choice
+
+	return &config.colors[choice-1]
+}
+
+// promptChoice asks for a number from 1 to n until it gets one. Enter
+// alone takes def, unless it is 0.
+func promptChoice(prompt string, n, def int) int {
+	if def > 0 {
+		prompt += fmt.Sprintf(" (1-%d) [%d]: ", n, def)
+	} else {
+		prompt += fmt.Sprintf(" (1-%d): ", n)
+	}
 	for {
-		fmt.Printf("\nSelect color scheme (1-%d): ", len(config.colors))
-		if _, err := fmt.Scanf("%d", &choice); err == nil && 
-			choice >= 1 && choice <= len(config.colors) {
-			break
+		fmt.Print(prompt)
+		line, err := readMenuLine()
+		line = strings.TrimSpace(line)
+		if line == "" && def > 0 {
+			return def
+		}
+		if choice, convErr := strconv.Atoi(line); convErr == nil && choice >= 1 && choice <= n {
+			return choice
+		}
+		if err != nil {
+			fmt.Printf("\nError reading input: %v\n", err)
+			os.Exit(1)
 		}
 		fmt.Println(color.RedString("Invalid selection. Please try again."))
-		bufio.NewReader(os.Stdin).ReadString('\n')
 	}
+}
 
-	return &config.colors[choice-1]
+// readMenuLine reads a line from stdin a byte at a time, leaving what
+// follows for the next prompt
+func readMenuLine() (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(b)
+		if n == 1 && b[0] == '\n' {
+			return string(line), nil
+		}
+		if n == 1 {
+			line = append(line, b[0])
+		}
+		if err != nil {
+			return string(line), err
+		}
+	}
 }
 
 func saveToFile(filepath string, content string) error {
 	return os.WriteFile(filepath, []byte(content), 0644)
-}
\ No newline at end of file
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestProcessTextWarnsOnce checks that a missing glyph is reported once
+// however many times processText draws the art
+func TestProcessTextWarnsOnce(t *testing.T) {
+	t.Setenv("ASCIIART_NO_STATS", "1")
+	var warnings bytes.Buffer
+	config := newAppConfig().withWarnings(&warnings)
+	options := RenderOptions{
+		category:   1,
+		style:      2,
+		target:     "motd",
+		format:     "html",
+		outputFile: filepath.Join(t.TempDir(), "art.html"),
+		limited:    true,
+	}
+	processText("Hi ☃", config, options)
+	if n := strings.Count(warnings.String(), "no glyph for '☃'"); n != 1 {
+		t.Errorf("processText warned %d times, want once:\n%s", n, warnings.String())
+	}
+}
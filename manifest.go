@@ -0,0 +1,376 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"gopkg.in/yaml.v3"
+)
+
+// buildStateFile sits next to the manifest and remembers the input hash
+// of every output from the last build
+const buildStateFile = ".asciiart-build.json"
+
+// Manifest lists banners to render in one build:
+//
+//	banners:
+//	  - text: Welcome
+//	    style: Big              # style name or category.style, e.g. 2.1
+//	    colorscheme: Ocean      # optional name or number
+//	    format: ansi            # text, ansi, html or json
+//	    output: art/welcome.ans
+//	    output_hash: true       # name it art/welcome-3fa2c1.ans
+type Manifest struct {
+	Banners []ManifestEntry `yaml:"banners"`
+}
+
+// ManifestEntry is one banner in a manifest
+type ManifestEntry struct {
+	Text        string `yaml:"text"`
+	Style       string `yaml:"style"`
+	ColorScheme string `yaml:"colorscheme,omitempty"`
+	Format      string `yaml:"format,omitempty"`
+	Output      string `yaml:"output"`
+	OutputHash  bool   `yaml:"output_hash,omitempty"`
+}
+
+// BuildRecord is what the last build produced for one manifest output
+type BuildRecord struct {
+	Hash string `json:"hash"`
+	File string `json:"file"`
+}
+
+// manifestFormats maps output file extensions to their default format
+var manifestFormats = map[string]string{
+	".ans":  "ansi",
+	".html": "html",
+	".json": "json",
+	".svg":  "svg",
+}
+
+func loadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for i, entry := range manifest.Banners {
+		if entry.Text == "" || entry.Output == "" {
+			return nil, fmt.Errorf("%s: banner %d needs text and output", path, i+1)
+		}
+	}
+	return manifest, nil
+}
+
+// findStyle looks a style up by name or by its "category.style" number
+func (config *AppConfig) findStyle(spec string) (asciiart.StyleCategory, asciiart.Style, error) {
+	if c, s, ok := strings.Cut(spec, "."); ok {
+		ci, err1 := strconv.Atoi(c)
+		si, err2 := strconv.Atoi(s)
+		if err1 == nil && err2 == nil && ci >= 1 && ci <= len(config.categories) {
+			category := config.categories[ci-1]
+			if si >= 1 && si <= len(category.Styles) {
+				return category, category.Styles[si-1], nil
+			}
+		}
+	}
+	ci, si, err := config.styleIndex(0, spec)
+	if err != nil {
+		return asciiart.StyleCategory{}, asciiart.Style{}, err
+	}
+	return config.categories[ci], config.categories[ci].Styles[si], nil
+}
+
+// findColorScheme looks a color scheme up by name or number. An empty
+// spec means no colors.
+func (config *AppConfig) findColorScheme(spec string) (*asciiart.ColorScheme, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	if n, err := strconv.Atoi(spec); err == nil && n >= 1 && n <= len(config.colors) {
+		return &config.colors[n-1], nil
+	}
+	var names []string
+	for _, scheme := range config.colors {
+		names = append(names, scheme.Name)
+	}
+	i, err := matchName("color scheme", spec, names)
+	if err != nil {
+		return nil, err
+	}
+	return &config.colors[i], nil
+}
+
+// hash identifies everything that affects an entry's output: its text,
+// settings, the style and font file they resolve to and the version of
+// this tool
+func (entry ManifestEntry) hash(definition string) string {
+	info := buildInfo()
+	sum := sha256.New()
+	for _, field := range []string{info.Version, info.Commit, entry.Text, entry.Style, definition, entry.ColorScheme, entry.Format, entry.Output, strconv.FormatBool(entry.OutputHash)} {
+		fmt.Fprintf(sum, "%d:%s\n", len(field), field)
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// styleDefinition describes what entry's style resolves to, so that
+// editing a custom style in the config file or a font file in a fonts
+// directory rebuilds the banners using them. Built-in styles and fonts
+// change only with the tool's version, which the hash has already.
+func (config *AppConfig) styleDefinition(entry ManifestEntry) string {
+	category, style, err := config.findStyle(entry.Style)
+	if err != nil {
+		return ""
+	}
+	d := style.Decorator
+	definition := fmt.Sprintf("%s/%s font=%q top=%q bottom=%q left=%q right=%q corners=%q fill=%q",
+		category.Name, style.Name, style.Font, d.Top, d.Bottom, d.Left, d.Right, d.Corners, d.Fill)
+	if category.Name == customCategory {
+		if custom, err := json.Marshal(config.customStyles[style.Name]); err == nil {
+			definition += " custom=" + string(custom)
+		}
+	}
+	if style.Font != "" {
+		if path, ok := renderer.FontFile(entry.Text, style.Font); ok {
+			if data, err := os.ReadFile(path); err == nil {
+				sum := sha256.Sum256(data)
+				definition += " fontfile=" + hex.EncodeToString(sum[:])
+			}
+		}
+	}
+	return definition
+}
+
+func loadBuildState(path string) map[string]BuildRecord {
+	state := make(map[string]BuildRecord)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		fmt.Fprintln(os.Stderr, color.YellowString("Warning: ignoring %s: %v", path, err))
+	}
+	return state
+}
+
+func saveBuildState(path string, state map[string]BuildRecord) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// renderEntry renders one manifest entry in its output format
+func (config *AppConfig) renderEntry(entry ManifestEntry) ([]byte, error) {
+	_, style, err := config.findStyle(entry.Style)
+	if err != nil {
+		return nil, err
+	}
+	colorScheme, err := config.findColorScheme(entry.ColorScheme)
+	if err != nil {
+		return nil, err
+	}
+
+	format := entry.Format
+	if format == "" {
+		format = manifestFormats[strings.ToLower(filepath.Ext(entry.Output))]
+	}
+
+	art := config.renderVariants(entry.Text, style, colorScheme)
+	switch format {
+	case "", "text":
+		return []byte(art.Plain + "\n"), nil
+	case "ansi":
+		return []byte(art.ANSI + "\n"), nil
+	case "ans":
+		return []byte(ansArt(art.ANSI)), nil
+	case "html":
+		return []byte(art.HTML + "\n"), nil
+	case "svg":
+		return []byte(art.SVG + "\n"), nil
+	case "json":
+		return json.MarshalIndent(art, "", "  ")
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// runBuild renders every banner in a manifest. Relative output paths are
+// resolved against the manifest's directory.
+func runBuild(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("build", flag.ExitOnError)
+	force := flags.Bool("force", false, "Rebuild every banner even if it is up to date")
+	jobs := flags.Int("jobs", runtime.NumCPU(), "Number of banners to build in parallel")
+	outputHash := flags.Bool("output-hash", false, "Name every output by a short hash of its content")
+	positional := parseInterspersed(flags, args)
+	if len(positional) != 1 {
+		fmt.Println("Usage: ascii-art build [-force] [-jobs n] manifest.yaml")
+		os.Exit(1)
+	}
+
+	path := positional[0]
+	manifest, err := loadManifest(path)
+	if err != nil {
+		fmt.Printf("Error reading manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	base := filepath.Dir(path)
+	statePath := filepath.Join(base, buildStateFile)
+	state := loadBuildState(statePath)
+	previous := maps.Clone(state)
+
+	entries := make(chan int)
+	results := make(chan buildResult)
+	var workers sync.WaitGroup
+	for range max(1, *jobs) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range entries {
+				entry := manifest.Banners[i]
+				entry.OutputHash = entry.OutputHash || *outputHash
+				results <- config.buildManifestEntry(entry, base, previous[entry.Output], *force)
+			}
+		}()
+	}
+	go func() {
+		for i := range manifest.Banners {
+			entries <- i
+		}
+		close(entries)
+		workers.Wait()
+		close(results)
+	}()
+
+	progress := isatty.IsTerminal(os.Stderr.Fd())
+	var failures []buildResult
+	counts := make(map[string]int)
+	done := 0
+	for result := range results {
+		done++
+		counts[result.status]++
+		switch result.status {
+		case buildFailed:
+			failures = append(failures, result)
+			delete(state, result.entry.Output)
+		case buildOK:
+			state[result.entry.Output] = BuildRecord{result.hash, result.file}
+		}
+
+		if progress {
+			fmt.Fprint(os.Stderr, "\r\033[K")
+		}
+		fmt.Println(result)
+		if progress {
+			fmt.Fprint(os.Stderr, progressBar(done, len(manifest.Banners)))
+		}
+	}
+	if progress {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+
+	if err := saveBuildState(statePath, state); err != nil {
+		fmt.Fprintln(os.Stderr, color.YellowString("Warning: build state not saved: %v", err))
+	}
+	fmt.Printf("\n%d built, %d up to date, %d failed\n", counts[buildOK], counts[buildSkipped], counts[buildFailed])
+	if len(failures) > 0 {
+		fmt.Println(color.RedString("\nFailures:"))
+		for _, result := range failures {
+			fmt.Printf("  %s: %v\n", result.entry.Output, result.err)
+		}
+		os.Exit(1)
+	}
+}
+
+const (
+	buildOK      = "ok"
+	buildSkipped = "skipped"
+	buildFailed  = "failed"
+
+	progressWidth = 30
+)
+
+type buildResult struct {
+	entry  ManifestEntry
+	status string
+	hash   string
+	file   string
+	err    error
+}
+
+func (result buildResult) String() string {
+	switch result.status {
+	case buildFailed:
+		return color.RedString("✗ %s: %v", result.entry.Output, result.err)
+	case buildSkipped:
+		return color.HiBlackString("- %s (up to date)", result.file)
+	}
+	return color.GreenString("✓ ") + result.file
+}
+
+func progressBar(done, total int) string {
+	filled := done * progressWidth / max(1, total)
+	return fmt.Sprintf("[%s%s] %d/%d", strings.Repeat("█", filled), strings.Repeat("░", progressWidth-filled), done, total)
+}
+
+// buildManifestEntry builds one entry unless the previous build already
+// produced it from the same inputs
+func (config *AppConfig) buildManifestEntry(entry ManifestEntry, base string, previous BuildRecord, force bool) buildResult {
+	result := buildResult{entry: entry, status: buildOK, hash: entry.hash(config.styleDefinition(entry)), file: entry.Output}
+	if !force && previous.Hash == result.hash && fileExists(resolvePath(base, previous.File)) {
+		result.status, result.file = buildSkipped, previous.File
+		return result
+	}
+	if result.file, result.err = config.buildEntry(entry, base); result.err != nil {
+		result.status = buildFailed
+	}
+	return result
+}
+
+// buildEntry writes one entry and returns the output name it used
+func (config *AppConfig) buildEntry(entry ManifestEntry, base string) (string, error) {
+	data, err := config.renderEntry(entry)
+	if err != nil {
+		return entry.Output, err
+	}
+
+	file := entry.Output
+	if entry.OutputHash {
+		file = hashedName(file, data)
+	}
+	output := resolvePath(base, file)
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return file, err
+	}
+	return file, os.WriteFile(output, data, 0644)
+}
+
+func resolvePath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
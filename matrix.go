@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"ascii-art/asciiart"
+)
+
+func init() {
+	outputTargets["matrix"] = writeMatrixFrame
+}
+
+const (
+	defaultMatrixWidth  = 64
+	defaultMatrixHeight = 32
+)
+
+// MatrixFrame is how a render becomes a bitmap frame for an LED matrix:
+// the matrix size and how pixels are packed, mono, rgb565 or rgb
+type MatrixFrame struct {
+	width, height int
+	pixels        string
+}
+
+func isMatrixPixels(format string) bool {
+	switch format {
+	case "mono", "rgb565", "rgb":
+		return true
+	}
+	return false
+}
+
+// parseMatrixFrame reads a frame's size, such as "64x32" (the default
+// when empty), and pixel format
+func parseMatrixFrame(size, pixels string) (MatrixFrame, error) {
+	frame := MatrixFrame{defaultMatrixWidth, defaultMatrixHeight, pixels}
+	if frame.pixels == "" {
+		frame.pixels = "mono"
+	}
+	if !isMatrixPixels(frame.pixels) {
+		return frame, fmt.Errorf("unknown pixel format %q (use mono, rgb565 or rgb)", frame.pixels)
+	}
+	if size == "" {
+		return frame, nil
+	}
+	w, h, ok := strings.Cut(size, "x")
+	width, errW := strconv.Atoi(w)
+	height, errH := strconv.Atoi(h)
+	if !ok || errW != nil || errH != nil || width <= 0 || height <= 0 || width > 1024 || height > 1024 {
+		return frame, fmt.Errorf("invalid matrix size %q (use WIDTHxHEIGHT, such as 64x32)", size)
+	}
+	frame.width, frame.height = width, height
+	return frame, nil
+}
+
+// encode draws the art, in the colors of its ANSI variant, scaled to fit
+// the matrix and centered on it
+func (f MatrixFrame) encode(art RenderedArt) []byte {
+	raster := asciiart.ParseANSI(art.ANSI, 0).Raster().Fit(f.width, f.height)
+	switch f.pixels {
+	case "rgb565":
+		return raster.RGB565()
+	case "rgb":
+		return raster.RGB888()
+	}
+	return raster.Mono()
+}
+
+// writeMatrixFrame writes the art as one bitmap frame to the file before
+// the query, e.g. matrix:lobby.bin?size=64x32&pixels=rgb565, which may be
+// a named pipe a matrix driver reads
+func writeMatrixFrame(dest string, art RenderedArt) error {
+	path, rawQuery, _ := strings.Cut(dest, "?")
+	if path == "" {
+		return errors.New("missing file in matrix output, as in matrix:frame.bin?size=64x32")
+	}
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return err
+	}
+	frame, err := parseMatrixFrame(q.Get("size"), q.Get("pixels"))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, frame.encode(art), 0644)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"ascii-art/asciiart"
+)
+
+const notificationTitle = "ASCII Art Generator"
+
+// sendNotification shows a desktop notification using the platform's
+// native tool: notify-send on Linux/BSD, osascript on macOS and a
+// PowerShell toast on Windows. image, if not empty, is a PNG shown with
+// it; osascript notifications cannot show one, so macOS goes without.
+func sendNotification(title, message, image string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s",
+			appleScriptQuote(message), appleScriptQuote(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToastScript(title, message, image))
+	default:
+		args := []string{"--app-name", notificationTitle}
+		if image != "" {
+			args = append(args, "--icon", image)
+		}
+		// "--" keeps text starting with "-" from being read as an option
+		cmd = exec.Command("notify-send", append(args, "--", title, message)...)
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %v %s", cmd.Path, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func appleScriptQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func windowsToastScript(title, message, image string) string {
+	quote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	toastType, setImage := "ToastText02", ""
+	if image != "" {
+		toastType = "ToastImageAndText02"
+		setImage = "\n$template.GetElementsByTagName('image').Item(0).SetAttribute('src', " + quote("file:///"+filepath.ToSlash(image)) + ")"
+	}
+	return `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::` + toastType + `)` + setImage + `
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode(` + quote(title) + `)) > $null
+$text.Item(1).AppendChild($template.CreateTextNode(` + quote(message) + `)) > $null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($template)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier(` + quote(notificationTitle) + `).Show($toast)`
+}
+
+// notificationThumbnail draws art as a PNG for the notification and
+// returns its path, or "" if it could not be written. The one file in
+// the cache directory is overwritten each time rather than removed, as
+// the notification daemon reads it after notify-send has returned.
+func notificationThumbnail(art string, scheme asciiart.Colorizer) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(dir, "asciiart", "notification.png")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return ""
+	}
+	if err := writeThumbnail(path, art, scheme); err != nil {
+		return ""
+	}
+	return path
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NumberFormat controls how numeric input is formatted before rendering
+type NumberFormat struct {
+	separator string // Thousands separator, empty to disable; "." makes "," the decimal point
+	width     int    // Minimum width of the formatted number
+	zeros     bool   // Pad with leading zeros instead of spaces
+	decimals  int    // Fixed number of decimal places, -1 to keep as given
+}
+
+// formatNumber validates text as a decimal number and formats it
+// according to nf, rounding half away from zero to a fixed number of
+// decimals. Signs and decimal points are normalized to plain ASCII so
+// every figure font can draw them.
+func formatNumber(text string, nf NumberFormat) (string, error) {
+	point := nf.decimalPoint()
+	s := strings.TrimSpace(text)
+	s = strings.NewReplacer("−", "-", "_", "", " ", "").Replace(s)
+	if nf.separator != "" {
+		s = strings.ReplaceAll(s, nf.separator, "")
+	}
+
+	negative := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+
+	intPart, fracPart, hasPoint := strings.Cut(s, point)
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return "", fmt.Errorf("%q is not a number", text)
+	}
+
+	if nf.decimals >= 0 {
+		intPart, fracPart = roundDigits(intPart, fracPart, nf.decimals)
+		hasPoint = nf.decimals > 0
+	}
+
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+	if negative && strings.Trim(intPart+fracPart, "0") == "" {
+		negative = false
+	}
+
+	sign := ""
+	if negative {
+		sign = "-"
+	}
+	frac := ""
+	if hasPoint && fracPart != "" {
+		frac = point + fracPart
+	}
+
+	// Leading zeros are added before grouping so they get separators too
+	if nf.zeros {
+		for len(sign)+len(groupThousands(intPart, nf.separator))+len(frac) < nf.width {
+			intPart = "0" + intPart
+		}
+	}
+
+	result := sign + groupThousands(intPart, nf.separator) + frac
+	if len(result) < nf.width {
+		result = strings.Repeat(" ", nf.width-len(result)) + result
+	}
+	return result, nil
+}
+
+// decimalPoint is "," when "." groups thousands, as in 1.234,5, and "."
+// otherwise
+func (nf NumberFormat) decimalPoint() string {
+	if nf.separator == "." {
+		return ","
+	}
+	return "."
+}
+
+// roundDigits rounds the digits intPart.fracPart half away from zero to
+// decimals places, padding fracPart with zeros if it is shorter
+func roundDigits(intPart, fracPart string, decimals int) (string, string) {
+	if len(fracPart) <= decimals {
+		return intPart, fracPart + strings.Repeat("0", decimals-len(fracPart))
+	}
+	roundUp := fracPart[decimals] >= '5'
+	digits := []byte(intPart + fracPart[:decimals])
+	for i := len(digits) - 1; roundUp && i >= 0; i-- {
+		if digits[i] == '9' {
+			digits[i] = '0'
+			continue
+		}
+		digits[i]++
+		roundUp = false
+	}
+	if roundUp {
+		digits = append([]byte{'1'}, digits...)
+	}
+	split := len(digits) - decimals
+	return string(digits[:split]), string(digits[split:])
+}
+
+// groupThousands inserts sep between every group of three digits
+func groupThousands(digits, sep string) string {
+	if sep == "" || len(digits) <= 3 {
+		return digits
+	}
+
+	var b strings.Builder
+	lead := len(digits) % 3
+	if lead > 0 {
+		b.WriteString(digits[:lead])
+	}
+	for i := lead; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		text string
+		nf   NumberFormat
+		want string
+	}{
+		{"1234567", NumberFormat{separator: ",", decimals: -1}, "1,234,567"},
+		{"1234567.5", NumberFormat{separator: ",", decimals: -1}, "1,234,567.5"},
+		{"1,234.5", NumberFormat{separator: ",", decimals: -1}, "1,234.5"},
+		{"1234", NumberFormat{decimals: -1}, "1234"},
+		{"−42", NumberFormat{separator: ",", decimals: -1}, "-42"},
+		{"+007", NumberFormat{separator: ",", decimals: -1}, "7"},
+		{"-0.00", NumberFormat{separator: ",", decimals: 1}, "0.0"},
+
+		{"2.675", NumberFormat{separator: ",", decimals: 2}, "2.68"},
+		{"2.674", NumberFormat{separator: ",", decimals: 2}, "2.67"},
+		{"1.5", NumberFormat{separator: ",", decimals: 0}, "2"},
+		{"999.95", NumberFormat{separator: ",", decimals: 1}, "1,000.0"},
+		{"-0.5", NumberFormat{separator: ",", decimals: 0}, "-1"},
+		{"-0.04", NumberFormat{separator: ",", decimals: 1}, "0.0"},
+		{"12", NumberFormat{separator: ",", decimals: 2}, "12.00"},
+
+		{"1234567,5", NumberFormat{separator: ".", decimals: -1}, "1.234.567,5"},
+		{"1.234,567", NumberFormat{separator: ".", decimals: 2}, "1.234,57"},
+		{"12345", NumberFormat{separator: " ", decimals: -1}, "12 345"},
+
+		{"42", NumberFormat{separator: ",", width: 6, decimals: -1}, "    42"},
+		{"42", NumberFormat{separator: ",", width: 6, zeros: true, decimals: -1}, "00,042"},
+	}
+	for _, test := range tests {
+		got, err := formatNumber(test.text, test.nf)
+		if err != nil || got != test.want {
+			t.Errorf("formatNumber(%q, %+v) = %q (%v), want %q", test.text, test.nf, got, err, test.want)
+		}
+	}
+}
+
+func TestFormatNumberRejects(t *testing.T) {
+	for _, text := range []string{"", "-", ".", "1.2.3", "12a", "0x10", "1e5"} {
+		if got, err := formatNumber(text, NumberFormat{separator: ",", decimals: -1}); err == nil {
+			t.Errorf("formatNumber(%q) = %q, want an error", text, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// exitBanners are the on-exit banners: the text and color scheme for
+// success and failure, in one style
+type exitBanners struct {
+	message, failure             string
+	category, style              int
+	successColors, failureColors int
+}
+
+// runOnExit prints a POSIX shell snippet that installs an EXIT trap
+// rendering a banner when the calling script ends. Failing exits use
+// the failure message and color scheme.
+func runOnExit(config *AppConfig, args []string) {
+	var banners exitBanners
+	fs := flag.NewFlagSet("on-exit", flag.ExitOnError)
+	fs.StringVar(&banners.message, "message", "Done", "Banner text when the script succeeds")
+	fs.StringVar(&banners.failure, "failure", "", "Banner text when the script fails (default: message + \" FAILED\")")
+	fs.IntVar(&banners.category, "category", 1, "Style category number")
+	fs.IntVar(&banners.style, "style", 2, "Style number within category")
+	fs.IntVar(&banners.successColors, "colorscheme", 2, "Color scheme number on success")
+	fs.IntVar(&banners.failureColors, "failure-colorscheme", 3, "Color scheme number on failure")
+	fs.Parse(args)
+
+	if banners.failure == "" {
+		banners.failure = banners.message + " FAILED"
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		binary = os.Args[0]
+	}
+
+	if isatty.IsTerminal(os.Stdout.Fd()) {
+		fmt.Fprintln(os.Stderr, "# Install the trap from your script with:")
+		fmt.Fprintf(os.Stderr, "#   eval \"$(%s on-exit %s)\"\n\n", shellQuote(binary), strings.Join(quoteAll(args), " "))
+	}
+	fmt.Print(banners.trap(binary))
+}
+
+// trap is the shell code installing the EXIT trap, which runs binary's
+// render command so only the banner is printed
+func (b exitBanners) trap(binary string) string {
+	render := func(text string, scheme int) string {
+		return strings.Join([]string{
+			shellQuote(binary), "render",
+			"-category", strconv.Itoa(b.category),
+			"-style", strconv.Itoa(b.style),
+			"-colorscheme", strconv.Itoa(scheme),
+			"--", text, "</dev/null",
+		}, " ")
+	}
+	return fmt.Sprintf(`__ascii_art_on_exit() {
+	__ascii_art_status=$?
+	if [ "$__ascii_art_status" -eq 0 ]; then
+		%s
+	else
+		%s
+	fi
+	return "$__ascii_art_status"
+}
+trap __ascii_art_on_exit EXIT
+`, render(shellQuote(b.message), b.successColors), render(shellQuote(b.failure)+`" (exit $__ascii_art_status)"`, b.failureColors))
+}
+
+// shellQuote quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func quoteAll(args []string) []string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return quoted
+}
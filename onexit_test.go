@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the program itself when a test starts the test binary
+// as ascii-art, as the on-exit trap does
+func TestMain(m *testing.M) {
+	if os.Getenv("ASCII_ART_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runAsCommand runs the test binary as ascii-art with args, or a shell
+// script that calls it, in an empty config directory
+func runAsCommand(t *testing.T, name string, args ...string) (string, int) {
+	t.Helper()
+	home := t.TempDir()
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), "ASCII_ART_TEST_MAIN=1", "HOME="+home, "XDG_CONFIG_HOME="+home)
+	out, err := cmd.Output()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return string(out), exit.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), 0
+}
+
+func TestOnExitPrintsOnlyTheBanner(t *testing.T) {
+	banners := exitBanners{message: "Done", failure: "-Broke", category: 1, style: 2, successColors: 2, failureColors: 3}
+	trap := banners.trap(os.Args[0])
+	tests := []struct {
+		status int
+		text   string
+		scheme string
+	}{
+		{0, "Done", "2"},
+		{3, "-Broke (exit 3)", "3"},
+	}
+	for _, test := range tests {
+		script := trap + "echo before\nexit " + strconv.Itoa(test.status) + "\n"
+		got, status := runAsCommand(t, "sh", "-c", script)
+		if status != test.status {
+			t.Errorf("script exited with %d, want %d", status, test.status)
+		}
+		art, _ := runAsCommand(t, os.Args[0], "render", "-category", "1", "-style", "2", "-colorscheme", test.scheme, "--", test.text)
+		if strings.TrimSpace(art) == "" {
+			t.Fatalf("no art rendered for %q", test.text)
+		}
+		if want := "before\n" + art; got != want {
+			t.Errorf("exit %d printed\n%s\nwant\n%s", test.status, got, want)
+		}
+	}
+}
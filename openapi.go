@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// openAPIVersion is the OpenAPI release the spec is written against
+const openAPIVersion = "3.1.0"
+
+// openAPISpec describes the render API for client generators. The
+// schemas are derived from the request and response types the handlers
+// decode and encode, so a field added to RenderRequest shows up in the
+// spec and in generated clients without editing anything here.
+func openAPISpec() map[string]any {
+	schemas := map[string]any{
+		"RenderRequest": jsonSchema(reflect.TypeFor[RenderRequest]()),
+		"RenderedArt":   jsonSchema(reflect.TypeFor[RenderedArt]()),
+		"Catalog":       jsonSchema(reflect.TypeFor[Catalog]()),
+		"PreviewEntry":  jsonSchema(reflect.TypeFor[PreviewEntry]()),
+		"RequestTheme":  jsonSchema(reflect.TypeFor[RequestTheme]()),
+		"ErrorMessage":  map[string]any{"type": "string"},
+		"Format":        map[string]any{"type": "string", "enum": renderFormats},
+	}
+
+	art := func(description string) map[string]any {
+		return map[string]any{
+			"description": description,
+			"content": map[string]any{
+				"text/plain":       map[string]any{"schema": map[string]any{"type": "string"}},
+				"text/html":        map[string]any{"schema": map[string]any{"type": "string"}},
+				"image/svg+xml":    map[string]any{"schema": map[string]any{"type": "string"}},
+				"application/json": map[string]any{"schema": ref("RenderedArt")},
+			},
+		}
+	}
+	badRequest := map[string]any{
+		"description": "The request cannot be rendered; the body gives the reason",
+		"content":     map[string]any{"text/plain": map[string]any{"schema": ref("ErrorMessage")}},
+	}
+	themeHeaderParam := map[string]any{
+		"name":        themeHeader,
+		"in":          "header",
+		"description": "A RequestTheme as JSON",
+		"schema":      map[string]any{"type": "string", "maxLength": maxThemeSize},
+	}
+
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":   "ASCII Art render API",
+			"version": buildInfo().Version,
+		},
+		"paths": map[string]any{
+			"/render": map[string]any{
+				"get": map[string]any{
+					"operationId": "renderQuery",
+					"summary":     "Render text, with the request in the query string",
+					"parameters": append(renderQueryParams(), themeHeaderParam, map[string]any{
+						"name":        "If-None-Match",
+						"in":          "header",
+						"description": "The ETag of art the client has",
+						"schema":      map[string]any{"type": "string"},
+					}),
+					"responses": map[string]any{
+						"200": art("The art in the requested format, with its ETag"),
+						"304": map[string]any{"description": "The art has not changed since the ETag in If-None-Match"},
+						"400": badRequest,
+					},
+				},
+				"post": map[string]any{
+					"operationId": "render",
+					"summary":     "Render text",
+					"parameters":  []any{themeHeaderParam},
+					"requestBody": map[string]any{
+						"required": true,
+						"content":  map[string]any{"application/json": map[string]any{"schema": ref("RenderRequest")}},
+					},
+					"responses": map[string]any{"200": art("The art in the requested format"), "400": badRequest},
+				},
+			},
+			"/styles": map[string]any{
+				"get": map[string]any{
+					"operationId": "styles",
+					"summary":     "List the styles, color schemes, fonts and formats",
+					"responses": map[string]any{"200": map[string]any{
+						"description": "The catalog",
+						"content":     map[string]any{"application/json": map[string]any{"schema": ref("Catalog")}},
+					}},
+				},
+			},
+			"/preview": map[string]any{
+				"get": map[string]any{
+					"operationId": "preview",
+					"summary":     "Render sample text in every style",
+					"parameters": []any{
+						queryParam("text", "string", "Sample text, at most 64 characters"),
+						queryParam("colorscheme", "string", "Color scheme name or number"),
+						queryParam("category", "integer", "Only this category, by number"),
+						map[string]any{
+							"name":   "format",
+							"in":     "query",
+							"schema": map[string]any{"type": "string", "enum": []string{"json", "html"}},
+						},
+					},
+					"responses": map[string]any{
+						"200": map[string]any{
+							"description": "Every style rendered",
+							"content": map[string]any{
+								"application/json": map[string]any{"schema": map[string]any{"type": "array", "items": ref("PreviewEntry")}},
+								"text/html":        map[string]any{"schema": map[string]any{"type": "string"}},
+							},
+						},
+						"400": badRequest,
+					},
+				},
+			},
+			"/usage": map[string]any{
+				"get": map[string]any{
+					"operationId": "usage",
+					"summary":     "Count the requests made with each API key or basic-auth user, when the server requires them",
+					"responses": map[string]any{"200": map[string]any{
+						"description": "The counters, by key name",
+						"content": map[string]any{"application/json": map[string]any{"schema": map[string]any{
+							"type":                 "object",
+							"additionalProperties": jsonSchema(reflect.TypeFor[KeyUsage]()),
+						}}},
+					}},
+				},
+			},
+			"/openapi.json": map[string]any{
+				"get": map[string]any{
+					"operationId": "openapi",
+					"summary":     "This document",
+					"responses":   map[string]any{"200": map[string]any{"description": "The OpenAPI document"}},
+				},
+			},
+		},
+		// Credentials are needed only when serve runs with -api-keys or
+		// -basic-auth, hence the empty alternative
+		"security": []any{
+			map[string]any{},
+			map[string]any{"apiKey": []string{}},
+			map[string]any{"bearer": []string{}},
+			map[string]any{"basic": []string{}},
+		},
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"apiKey": map[string]any{"type": "apiKey", "in": "header", "name": "X-Api-Key"},
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+				"basic":  map[string]any{"type": "http", "scheme": "basic"},
+			},
+		},
+	}
+}
+
+// renderQueryParams are the query parameters GET /render reads: the
+// RenderRequest fields, with the theme as JSON and no id to echo
+func renderQueryParams() []any {
+	var params []any
+	t := reflect.TypeFor[RenderRequest]()
+	for i := range t.NumField() {
+		name, _ := jsonField(t.Field(i))
+		switch name {
+		case "", "id":
+			continue
+		case "theme":
+			params = append(params, queryParam(name, "string", "A RequestTheme as JSON"))
+		case "format":
+			params = append(params, map[string]any{"name": name, "in": "query", "schema": ref("Format")})
+		default:
+			param := map[string]any{"name": name, "in": "query", "schema": jsonSchema(t.Field(i).Type)}
+			if name == "text" {
+				param["required"] = true
+			}
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+func queryParam(name, kind, description string) map[string]any {
+	return map[string]any{"name": name, "in": "query", "description": description, "schema": map[string]any{"type": kind}}
+}
+
+func ref(schema string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + schema}
+}
+
+// jsonField returns the name encoding/json gives f, empty for fields it
+// skips, and whether it may be left out
+func jsonField(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, strings.Contains(options, "omitempty")
+}
+
+// jsonSchema describes how encoding/json encodes values of type t
+func jsonSchema(t reflect.Type) map[string]any {
+	switch t {
+	case reflect.TypeFor[json.RawMessage]():
+		return map[string]any{"description": "Any JSON value"}
+	case reflect.TypeFor[time.Time]():
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonSchema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": jsonSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchema(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]any{}
+		var required []string
+		for i := range t.NumField() {
+			name, optional := jsonField(t.Field(i))
+			if name == "" {
+				continue
+			}
+			properties[name] = jsonSchema(t.Field(i).Type)
+			if !optional {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+	return map[string]any{}
+}
+
+// handleOpenAPI serves the OpenAPI document
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", formatTypes["json"])
+	json.NewEncoder(w).Encode(openAPISpec())
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const testAPIKey = "secret"
+
+// testServer serves the default config behind testAPIKey, with the
+// render cache serve has by default
+func testServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	config := newAppConfig()
+	config.renders = newRenderCache(renderCacheSize)
+	var current atomic.Pointer[AppConfig]
+	current.Store(config)
+	auth, err := newServerAuth("test="+testAPIKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(serverMux(&current, auth))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// fetch sends a request and returns the status and body. A nil header
+// sends the test key.
+func fetch(t *testing.T, server *httptest.Server, method, path, body string, header http.Header) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header == nil {
+		header = http.Header{"X-Api-Key": {testAPIKey}}
+	}
+	req.Header = header
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(out)
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	server := testServer(t)
+	_, body := fetch(t, server, http.MethodGet, "/openapi.json", "", nil)
+	var spec struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(body), &spec); err != nil {
+		t.Fatalf("/openapi.json is not JSON: %v", err)
+	}
+	if spec.OpenAPI != openAPIVersion {
+		t.Errorf("openapi = %q, want %q", spec.OpenAPI, openAPIVersion)
+	}
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			status, _ := fetch(t, server, strings.ToUpper(method), path, `{"text":"Hi"}`, nil)
+			if status == http.StatusNotFound || status == http.StatusMethodNotAllowed {
+				t.Errorf("%s %s: %d, but the spec documents it", method, path, status)
+			}
+		}
+	}
+}
+
+// TestRenderQueryParams checks that GET /render reads every parameter
+// the spec lists, by comparing each with the same request as JSON
+func TestRenderQueryParams(t *testing.T) {
+	server := testServer(t)
+	values := map[string]string{
+		"text":        "Hi",
+		"style":       "2.1",
+		"font":        "banner",
+		"colorscheme": "2",
+		"format":      "ansi",
+		"theme":       `{"chars":"+-|"}`,
+		"braille":     "true",
+		"template":    "true",
+	}
+	for _, param := range renderQueryParams() {
+		name := param.(map[string]any)["name"].(string)
+		if _, ok := values[name]; !ok {
+			t.Errorf("no test value for the %q parameter", name)
+		}
+	}
+
+	base := url.Values{"text": {"{upper:hi}"}, "format": {"ansi"}}
+	_, plain := fetch(t, server, http.MethodGet, "/render?"+base.Encode(), "", nil)
+	for name, value := range values {
+		if name == "text" || name == "format" {
+			continue
+		}
+		query := url.Values{"text": {"{upper:hi}"}, "format": {"ansi"}, name: {value}}
+		request := map[string]any{"text": "{upper:hi}", "format": "ansi"}
+		switch name {
+		case "theme":
+			var theme any
+			json.Unmarshal([]byte(value), &theme)
+			request[name] = theme
+		case "braille", "template":
+			request[name] = true
+		default:
+			request[name] = value
+		}
+		body, _ := json.Marshal(request)
+		_, got := fetch(t, server, http.MethodGet, "/render?"+query.Encode(), "", nil)
+		_, want := fetch(t, server, http.MethodPost, "/render", string(body), nil)
+		if got != want {
+			t.Errorf("GET with %s=%s gave\n%s\nwant, as POSTed,\n%s", name, value, got, want)
+		}
+		if got == plain {
+			t.Errorf("GET with %s=%s rendered the same as without it", name, value)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+)
+
+// RenderedArt holds every variant of a render that output targets may need
+type RenderedArt struct {
+	Text  string `json:"text"`
+	Plain string `json:"plain"`
+	ANSI  string `json:"ansi"`
+	HTML  string `json:"html"`
+	SVG   string `json:"svg"`
+}
+
+// outputTargets maps -output scheme prefixes ("webhook:https://...") to
+// writers. Destinations without a known prefix are treated as file paths.
+var outputTargets = map[string]func(dest string, art RenderedArt) error{
+	"webhook": postWebhook,
+}
+
+const webhookTimeout = 10 * time.Second
+
+// findOutputTarget returns the writer registered for target's scheme
+func findOutputTarget(target string) (func(string, RenderedArt) error, string, bool) {
+	// Bare scheme names such as "syslog" select the target with defaults
+	scheme, dest, _ := strings.Cut(target, ":")
+	write, ok := outputTargets[scheme]
+	return write, dest, ok
+}
+
+// renderVariants renders text once per output variant. The ANSI variant
+// always carries escape codes, even when stdout is not a terminal, and
+// the HTML and SVG variants carry the colors as CSS and fills.
+func (config *AppConfig) renderVariants(text string, style asciiart.Style, colorScheme *asciiart.ColorScheme) RenderedArt {
+	plain := config.generateArt(text, style, nil)
+
+	ansi := plain
+	var colorizer asciiart.Colorizer
+	if colorScheme != nil {
+		ansi = config.generateArt(text, style, colorScheme.Forced())
+		colorizer = colorScheme
+	}
+
+	return RenderedArt{
+		Text:  text,
+		Plain: plain,
+		ANSI:  ansi,
+		HTML:  asciiart.HTML(plain, colorizer),
+		SVG:   asciiart.SVG(plain, colorizer),
+	}
+}
+
+// layeredVariants is renderVariants for art with layers in their own
+// colors, such as the -background pattern and the -signature: overlay
+// wraps the gradient's or color scheme's colorizer with theirs
+func (config *AppConfig) layeredVariants(text string, style asciiart.Style, colorScheme *asciiart.ColorScheme, gradient *asciiart.Gradient, overlay func(text, plain string, c asciiart.Colorizer, forced bool) asciiart.Colorizer) RenderedArt {
+	art := config.renderVariants(text, style, nil)
+	var ansi, html asciiart.Colorizer
+	switch {
+	case gradient != nil:
+		ansi, html = gradient.Forced(), gradient.WithDepth(asciiart.DepthTrue)
+	case colorScheme != nil:
+		ansi, html = colorScheme.Forced(), colorScheme
+	}
+	art.ANSI = asciiart.Colorize(art.Plain, overlay(text, art.Plain, ansi, true))
+	colors := overlay(text, art.Plain, html, false)
+	art.HTML = asciiart.HTML(art.Plain, colors)
+	art.SVG = asciiart.SVG(art.Plain, colors)
+	return art
+}
+
+// gradientVariants is renderVariants for art colored by a gradient
+func (config *AppConfig) gradientVariants(text string, style asciiart.Style, gradient *asciiart.Gradient) RenderedArt {
+	art := config.renderVariants(text, style, nil)
+	art.ANSI = config.artRenderer().RenderColorized(text, style, gradient.Forced())
+	trueColor := gradient.WithDepth(asciiart.DepthTrue)
+	art.HTML = asciiart.HTML(art.Plain, trueColor)
+	art.SVG = asciiart.SVG(art.Plain, trueColor)
+	return art
+}
+
+// blockRaster rasterizes text in style for -blocks, coloring the pixels
+// with the gradient if there is one, else with the color scheme
+func (config *AppConfig) blockRaster(text string, style asciiart.Style, colorScheme *asciiart.ColorScheme, gradient *asciiart.Gradient) asciiart.Raster {
+	return asciiart.RasterizeArt(config.generateArt(text, style, nil), artColorizer(colorScheme, gradient))
+}
+
+// artColorizer is the gradient if there is one, else the color scheme,
+// else nil for no colors
+func artColorizer(colorScheme *asciiart.ColorScheme, gradient *asciiart.Gradient) asciiart.Colorizer {
+	switch {
+	case gradient != nil:
+		return gradient
+	case colorScheme != nil:
+		return colorScheme
+	}
+	return nil
+}
+
+// blockVariants is renderVariants for -blocks, with the ANSI variant in
+// true color. Half blocks show two colors per character through the
+// background, which HTML and SVG text cannot, so those variants get the
+// shapes without colors.
+func (config *AppConfig) blockVariants(text string, style asciiart.Style, colorScheme *asciiart.ColorScheme, gradient *asciiart.Gradient) RenderedArt {
+	raster := config.blockRaster(text, style, colorScheme, gradient)
+	plain := raster.HalfBlocks(0)
+	return RenderedArt{
+		Text:  text,
+		Plain: plain,
+		ANSI:  raster.HalfBlocks(asciiart.DepthTrue),
+		HTML:  asciiart.HTML(plain, nil),
+		SVG:   asciiart.SVG(plain, nil),
+	}
+}
+
+// teletextVariants is renderVariants for -teletext, with the ANSI variant
+// in true color. Each mosaic has one color, so HTML and SVG keep them.
+func (config *AppConfig) teletextVariants(text string, style asciiart.Style, colorScheme *asciiart.ColorScheme, gradient *asciiart.Gradient) RenderedArt {
+	raster := config.blockRaster(text, style, colorScheme, gradient)
+	plain, ansi := raster.Teletext(0), raster.Teletext(asciiart.DepthTrue)
+	colors := asciiart.ParseANSI(ansi, 0)
+	return RenderedArt{
+		Text:  text,
+		Plain: plain,
+		ANSI:  ansi,
+		HTML:  asciiart.HTML(plain, colors),
+		SVG:   asciiart.SVG(plain, colors),
+	}
+}
+
+const outputHashLength = 6
+
+// hashedName inserts a short hash of content before the extension of
+// path, so banner.txt becomes banner-3fa2c1.txt
+func hashedName(path string, content []byte) string {
+	sum := sha256.Sum256(content)
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + hex.EncodeToString(sum[:])[:outputHashLength] + ext
+}
+
+// postWebhook POSTs the rendered art as JSON to url
+func postWebhook(url string, art RenderedArt) error {
+	payload, err := json.Marshal(art)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
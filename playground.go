@@ -0,0 +1,23 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// playgroundFiles is the single-page playground served at / by serve: a
+// text box, style, font and color pickers, a live preview and downloads,
+// all built on /styles and /render
+//
+//go:embed playground
+var playgroundFiles embed.FS
+
+// playgroundHandler serves the embedded playground files
+func playgroundHandler() http.Handler {
+	files, err := fs.Sub(playgroundFiles, "playground")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServerFS(files)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+// effectTimeout bounds how long an effect plugin may take with one art
+const effectTimeout = 10 * time.Second
+
+// builtinEffects are the effects shipped with ascii-art, the only ones
+// render requests to the server may name
+var builtinEffects = []string{"shadow", "indent", "braille"}
+
+func init() {
+	asciiart.RegisterEffect("indent", asciiart.EffectFunc(func(s string) string {
+		return "  " + strings.ReplaceAll(s, "\n", "\n  ")
+	}))
+}
+
+// execEffect is an effect plugin: an executable in an effects asset
+// directory that reads art on stdin and writes the new art to stdout.
+// ASCIIART_EFFECT holds the name it runs as, so one program can serve
+// several.
+type execEffect struct {
+	name, path string
+}
+
+func (e execEffect) Apply(art string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), effectTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, e.path)
+	cmd.Stdin = strings.NewReader(art + "\n")
+	cmd.Env = append(os.Environ(), "ASCIIART_EFFECT="+e.name)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// loadEffectPlugins registers the executables in the effects asset
+// directories as effects named after the file, without its extension.
+// They cannot replace the built-in ones.
+func loadEffectPlugins() {
+	for file, path := range listAssets(assetEffects, "") {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+			continue
+		}
+		name := strings.TrimSuffix(file, filepath.Ext(file))
+		if isBuiltinEffect(name) {
+			fmt.Fprintln(os.Stderr, color.YellowString("Warning: effect plugin %s: %q is a built-in effect", path, name))
+			continue
+		}
+		asciiart.RegisterEffect(name, execEffect{name, path})
+	}
+}
+
+func isBuiltinEffect(name string) bool {
+	for _, builtin := range builtinEffects {
+		if strings.EqualFold(builtin, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// listEffects prints the effect names for -effect and config decorators,
+// with the file each plugin runs
+func listEffects() {
+	fmt.Println(color.BlueString("Effects:"))
+	for _, name := range asciiart.EffectNames() {
+		if plugin, ok := asciiart.LookupEffect(name).(execEffect); ok {
+			fmt.Printf("  %s %s\n", color.CyanString(name), color.HiBlackString("(%s)", plugin.path))
+			continue
+		}
+		fmt.Printf("  %s\n", color.CyanString(name))
+	}
+}
+
+// findEffect looks up a registered effect by name
+func findEffect(name string) (asciiart.Effect, error) {
+	effect := asciiart.LookupEffect(name)
+	if effect == nil {
+		return nil, fmt.Errorf("unknown effect %q (use %s)", name, strings.Join(asciiart.EffectNames(), ", "))
+	}
+	return effect, nil
+}
+
+// effectStep turns effect into a decorator step. An effect that fails
+// leaves the art as it was, with a warning.
+func effectStep(name string, effect asciiart.Effect) func(string) string {
+	return func(art string) string {
+		result, err := effect.Apply(art)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, color.YellowString("Warning: effect %s: %v", name, err))
+			return art
+		}
+		return result
+	}
+}
+
+// withEffect returns style with effect applied after its border and
+// other effects, as for -effect
+func withEffect(style asciiart.Style, name string, effect asciiart.Effect) asciiart.Style {
+	step, previous := effectStep(name, effect), style.Decorator.Post
+	style.Decorator.Post = func(art string) string {
+		if previous != nil {
+			art = previous(art)
+		}
+		return step(art)
+	}
+	return style
+}
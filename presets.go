@@ -0,0 +1,285 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+const presetsFileName = "presets.yaml"
+
+// Preset is a saved look, from -save-preset, recalled with -preset or
+// :preset. Styles and color schemes are kept by name, so presets survive
+// new styles being added ahead of them.
+type Preset struct {
+	Category    string   `yaml:"category,omitempty"`
+	Style       string   `yaml:"style,omitempty"`
+	ColorScheme string   `yaml:"colorscheme,omitempty"`
+	NoColor     bool     `yaml:"no_color,omitempty"`
+	Font        string   `yaml:"font,omitempty"`
+	BorderChar  string   `yaml:"border_char,omitempty"`
+	BorderChars string   `yaml:"border_chars,omitempty"`
+	FillChar    string   `yaml:"fill_char,omitempty"`
+	Width       int      `yaml:"width,omitempty"`
+	Effects     []string `yaml:"effects,omitempty"`
+}
+
+func presetsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, presetsFileName), nil
+}
+
+// loadPresets reads the saved presets. Having none saved is not an
+// error.
+func loadPresets() (map[string]Preset, error) {
+	presets := make(map[string]Preset)
+	path, err := presetsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return presets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &presets); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return presets, nil
+}
+
+// savePreset stores p under name, replacing any preset of that name, and
+// returns the file it went to
+func savePreset(name string, p Preset) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", errors.New("a preset needs a name")
+	}
+	presets, err := loadPresets()
+	if err != nil {
+		return "", err
+	}
+	presets[name] = p
+	path, err := presetsPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	data, err := yaml.Marshal(presets)
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, data, 0644)
+}
+
+// presetNames returns the names of presets in order
+func presetNames(presets map[string]Preset) []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// findPreset looks up a saved preset by name, ignoring case, spaces,
+// dashes and underscores
+func findPreset(name string) (Preset, error) {
+	presets, err := loadPresets()
+	if err != nil {
+		return Preset{}, err
+	}
+	names := presetNames(presets)
+	for _, candidate := range names {
+		if nameKey(candidate) == nameKey(name) {
+			return presets[candidate], nil
+		}
+	}
+	if suggestion := closestName(name, names); suggestion != "" {
+		return Preset{}, fmt.Errorf("unknown preset %q (did you mean %q?)", name, suggestion)
+	}
+	return Preset{}, fmt.Errorf("unknown preset %q (ascii-art list presets shows them)", name)
+}
+
+// newPreset captures the look options give: the style, color scheme,
+// font, border, fill, width and effects
+func (config *AppConfig) newPreset(options RenderOptions) Preset {
+	p := Preset{
+		NoColor:     !options.showColors,
+		Font:        options.font,
+		BorderChar:  options.borderChar,
+		BorderChars: strings.Join(options.borderChars, ","),
+		FillChar:    options.fillChar,
+		Width:       options.wrapWidth,
+		Effects:     options.effects,
+	}
+	if options.category >= 1 && options.category <= len(config.categories) {
+		category := config.categories[options.category-1]
+		p.Category = category.Name
+		if options.style >= 1 && options.style <= len(category.Styles) {
+			p.Style = category.Styles[options.style-1].Name
+		}
+	}
+	if options.showColors && options.colorScheme >= 1 && options.colorScheme <= len(config.colors) {
+		p.ColorScheme = config.colors[options.colorScheme-1].Name
+	}
+	return p
+}
+
+// apply sets what p saved in options, except for the flags in given,
+// which the command line chose instead
+func (p Preset) apply(config *AppConfig, options *RenderOptions, given map[string]bool) error {
+	if p.Style != "" && !given["category"] && !given["style"] {
+		category := 0
+		if p.Category != "" {
+			if i := slices.IndexFunc(config.categories, func(c asciiart.StyleCategory) bool {
+				return nameKey(c.Name) == nameKey(p.Category)
+			}); i >= 0 {
+				category = i + 1
+			}
+		}
+		ci, si, err := config.styleIndex(category, p.Style)
+		if err != nil {
+			return err
+		}
+		options.category, options.style = ci+1, si+1
+	}
+	if p.ColorScheme != "" && !given["colorscheme"] {
+		var names []string
+		for _, scheme := range config.colors {
+			names = append(names, scheme.Name)
+		}
+		i, err := matchName("color scheme", p.ColorScheme, names)
+		if err != nil {
+			return err
+		}
+		options.colorScheme = i + 1
+	}
+	if p.NoColor && !given["color"] {
+		options.showColors = false
+	}
+	if p.Font != "" && !given["font"] {
+		if err := checkFont(p.Font); err != nil {
+			return err
+		}
+		options.font = p.Font
+	}
+	if p.BorderChar != "" && !given["border-char"] {
+		options.borderChar = p.BorderChar
+	}
+	if p.BorderChars != "" && !given["border-chars"] {
+		pieces, err := parseBorderChars(p.BorderChars)
+		if err != nil {
+			return err
+		}
+		options.borderChars = pieces
+	}
+	if p.FillChar != "" && !given["fill-char"] {
+		options.fillChar = p.FillChar
+	}
+	if p.Width > 0 && !given["width"] {
+		options.wrapWidth, options.fitTerminal = p.Width, false
+	}
+	if len(p.Effects) > 0 && !given["effect"] {
+		for _, name := range p.Effects {
+			if _, err := findEffect(name); err != nil {
+				return err
+			}
+		}
+		options.effects = p.Effects
+	}
+	return nil
+}
+
+// summary describes a preset in a few words, for listings
+func (p Preset) summary() string {
+	var parts []string
+	if p.Style != "" {
+		parts = append(parts, p.Style)
+	}
+	if p.ColorScheme != "" {
+		parts = append(parts, p.ColorScheme+" colors")
+	} else if p.NoColor {
+		parts = append(parts, "no colors")
+	}
+	if p.Font != "" {
+		parts = append(parts, "font "+p.Font)
+	}
+	if p.BorderChar != "" {
+		parts = append(parts, "border "+p.BorderChar)
+	} else if p.BorderChars != "" {
+		parts = append(parts, "border "+strings.ReplaceAll(p.BorderChars, ",", ""))
+	}
+	if p.FillChar != "" {
+		parts = append(parts, "fill "+p.FillChar)
+	}
+	if p.Width > 0 {
+		parts = append(parts, "width "+strconv.Itoa(p.Width))
+	}
+	if len(p.Effects) > 0 {
+		parts = append(parts, strings.Join(p.Effects, "+"))
+	}
+	if len(parts) == 0 {
+		return "defaults"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// listPresets prints the saved presets, numbered for :preset
+func listPresets() []string {
+	presets, err := loadPresets()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return nil
+	}
+	names := presetNames(presets)
+	if len(names) == 0 {
+		fmt.Println("\nNo presets yet. Save one with -save-preset NAME.")
+		return nil
+	}
+	fmt.Println(color.BlueString("\nPresets:"))
+	for i, name := range names {
+		fmt.Printf("%2d. %s - %s\n", i+1, color.CyanString(name), presets[name].summary())
+	}
+	return names
+}
+
+// browsePresets lists the presets and asks for one by number or name,
+// for ":preset" in interactive mode. An empty answer picks none.
+func browsePresets() (Preset, bool) {
+	names := listPresets()
+	if len(names) == 0 {
+		return Preset{}, false
+	}
+	for {
+		fmt.Print(color.GreenString("\nPick a preset by number or name (Enter for none): "))
+		answer, err := readMenuLine()
+		answer = strings.TrimSpace(answer)
+		if answer == "" || err != nil {
+			return Preset{}, false
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(names) {
+			answer = names[n-1]
+		}
+		p, err := findPreset(answer)
+		if err == nil {
+			return p, true
+		}
+		fmt.Println(color.RedString("%v", err))
+	}
+}
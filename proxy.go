@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// withForwardedFor replaces each request's RemoteAddr with the client
+// address a reverse proxy put last in X-Forwarded-For, so rate limits
+// and the audit log see clients rather than the proxy. Only use it
+// behind a proxy: anyone else can send the header.
+func withForwardedFor(trust bool, next http.Handler) http.Handler {
+	if !trust {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The proxy appends the address it saw; earlier entries came from
+		// the client and may be made up
+		hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+		if client := strings.TrimSpace(hops[len(hops)-1]); net.ParseIP(client) != nil {
+			r.RemoteAddr = client
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withBasePath serves next under prefix, such as /ascii-art, for
+// proxies that pass the whole path on. Everything else is not found.
+func withBasePath(prefix string, next http.Handler) http.Handler {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix == "/" {
+		return next
+	}
+	strip := http.StripPrefix(prefix, next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == prefix:
+			// The playground's links are relative, so it needs the slash
+			target := prefix + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+		case strings.HasPrefix(r.URL.Path, prefix+"/"):
+			strip.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithForwardedFor(t *testing.T) {
+	tests := []struct {
+		trust     bool
+		forwarded []string
+		want      string
+	}{
+		{false, []string{"1.2.3.4"}, "10.0.0.1:5000"},
+		{true, nil, "10.0.0.1:5000"},
+		{true, []string{"1.2.3.4"}, "1.2.3.4"},
+		{true, []string{"6.6.6.6, 1.2.3.4"}, "1.2.3.4"}, // The client made up the first hop
+		{true, []string{"6.6.6.6", "1.2.3.4"}, "1.2.3.4"},
+		{true, []string{"2001:db8::1"}, "2001:db8::1"},
+		{true, []string{"not an address"}, "10.0.0.1:5000"},
+	}
+	for _, test := range tests {
+		var got string
+		handler := withForwardedFor(test.trust, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = r.RemoteAddr }))
+		req := httptest.NewRequest(http.MethodGet, "/render", nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		for _, hop := range test.forwarded {
+			req.Header.Add("X-Forwarded-For", hop)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if got != test.want {
+			t.Errorf("trust %v, X-Forwarded-For %q: client %q, want %q", test.trust, test.forwarded, got, test.want)
+		}
+	}
+}
+
+func TestWithBasePath(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		path     string
+		status   int
+		wantPath string
+	}{
+		{"", "/render", http.StatusOK, "/render"},
+		{"/", "/render", http.StatusOK, "/render"},
+		{"/ascii-art", "/ascii-art/render", http.StatusOK, "/render"},
+		{"ascii-art/", "/ascii-art/render", http.StatusOK, "/render"},
+		{"/ascii-art", "/ascii-art", http.StatusMovedPermanently, ""},
+		{"/ascii-art", "/render", http.StatusNotFound, ""},
+		{"/ascii-art", "/ascii-artist/render", http.StatusNotFound, ""},
+	}
+	for _, test := range tests {
+		var got string
+		handler := withBasePath(test.prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = r.URL.Path }))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+		if recorder.Code != test.status || got != test.wantPath {
+			t.Errorf("prefix %q, %s: status %d, path %q; want %d, %q", test.prefix, test.path, recorder.Code, got, test.status, test.wantPath)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestMQTTReadPacket(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream []byte
+		want   []byte // nil when an error is expected
+	}{
+		{"connack", []byte{0x20, 0x02, 0x00, 0x00}, []byte{0x00, 0x00}},
+		{"skips other packets", []byte{0x30, 0x03, 'a', 'b', 'c', 0x20, 0x02, 0x00, 0x05}, []byte{0x00, 0x05}},
+		{"skips long packets", append([]byte{0x30, 0xFF, 0xFF, 0x03}, append(make([]byte, 65535), 0x20, 0x02, 0x00, 0x00)...), []byte{0x00, 0x00}},
+		{"fifth length byte", []byte{0x20, 0x80, 0x80, 0x80, 0x80, 0x01}, nil},
+		{"long reply", []byte{0x20, 0xFF, 0xFF, 0xFF, 0x7F}, nil},
+		{"truncated", []byte{0x20, 0x02, 0x00}, nil},
+	}
+	for _, test := range tests {
+		got, err := mqttReadPacket(bufio.NewReader(bytes.NewReader(test.stream)), 0x20)
+		switch {
+		case test.want == nil && err == nil:
+			t.Errorf("%s: mqttReadPacket = %v, want an error", test.name, got)
+		case test.want != nil && (err != nil || !bytes.Equal(got, test.want)):
+			t.Errorf("%s: mqttReadPacket = %v (%v), want %v", test.name, got, err, test.want)
+		}
+	}
+}
+
+// fakeNATS answers one client with info and records what it sends.
+func fakeNATS(t *testing.T, info string) (net.Conn, <-chan string) {
+	client, server := net.Pipe()
+	sent := make(chan string, 1)
+	go func() {
+		defer server.Close()
+		io.WriteString(server, "INFO "+info+"\r\n")
+		reader := bufio.NewReader(server)
+		var got strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			got.WriteString(line)
+			if err != nil || line == "PING\r\n" {
+				break
+			}
+		}
+		io.WriteString(server, "PONG\r\n")
+		sent <- got.String()
+	}()
+	t.Cleanup(func() { client.Close() })
+	return client, sent
+}
+
+func TestNATSPublish(t *testing.T) {
+	conn, sent := fakeNATS(t, `{"server_id":"test"}`)
+	u, opts, err := parsePublishTarget("nats", "//localhost/displays.lobby")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := natsPublish(conn, u, opts, []byte("Hi")); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-sent; !strings.Contains(got, "PUB displays.lobby 2\r\nHi\r\n") {
+		t.Errorf("server got %q, want a PUB of the payload", got)
+	}
+}
+
+func TestNATSPublishRefusesPlaintext(t *testing.T) {
+	tests := []struct {
+		dest, info string
+	}{
+		{"//user:secret@localhost/displays.lobby", `{}`},
+		{"//localhost/displays.lobby?tls=true", `{}`},
+	}
+	for _, test := range tests {
+		conn, _ := fakeNATS(t, test.info)
+		u, opts, err := parsePublishTarget("nats", test.dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := natsPublish(conn, u, opts, []byte("Hi")); err == nil {
+			t.Errorf("natsPublish(%q) with INFO %s succeeded, want an error", test.dest, test.info)
+		}
+	}
+}
+
+func TestPublishNATSRejectsSubjects(t *testing.T) {
+	for _, subject := range []string{"displays%20lobby", "displays%0D%0APUB%20x%201", "lobby%09"} {
+		dest := "//localhost:1/" + subject
+		if err := publishNATS(dest, RenderedArt{}); err == nil || !strings.Contains(err.Error(), "whitespace") {
+			t.Errorf("publishNATS(%q) = %v, want a whitespace error", dest, err)
+		}
+	}
+}
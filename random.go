@@ -0,0 +1,148 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+const defaultShuffleInterval = 2 * time.Second
+
+// newRand returns a random source for -random and shuffle: seeded by seed
+// so a pick can be repeated, or by the clock when seed is 0
+func newRand(seed uint64) *rand.Rand {
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+// pickRandom chooses the category, style and color scheme (counting from
+// 1) that were not given on the command line. A style is picked among all
+// of them, or within the category if only that was given.
+func (config *AppConfig) pickRandom(rng *rand.Rand, given map[string]bool, category, style, colorScheme *int) {
+	if !given["style"] {
+		if given["category"] && *category >= 1 && *category <= len(config.categories) {
+			*style = rng.IntN(len(config.categories[*category-1].Styles)) + 1
+		} else {
+			combos := config.styleCombos()
+			pick := combos[rng.IntN(len(combos))]
+			*category, *style = pick.category+1, pick.style+1
+		}
+	}
+	if !given["colorscheme"] && len(config.colors) > 0 {
+		*colorScheme = rng.IntN(len(config.colors)) + 1
+	}
+}
+
+// styleCombo is one style, or one style in one color scheme, by index
+type styleCombo struct {
+	category, style, scheme int
+}
+
+// styleCombos lists every style of every category
+func (config *AppConfig) styleCombos() []styleCombo {
+	var combos []styleCombo
+	for ci, c := range config.categories {
+		for si := range c.Styles {
+			combos = append(combos, styleCombo{ci, si, -1})
+		}
+	}
+	return combos
+}
+
+// runShuffle renders the text in every style and color scheme, in a
+// random order, one after another. On a terminal it cycles through them
+// in place, reshuffling after each round, until interrupted.
+func runShuffle(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("shuffle", flag.ExitOnError)
+	seed := flags.Uint64("seed", 0, "Seed for the order, to repeat a shuffle (default: random)")
+	interval := flags.Duration("interval", defaultShuffleInterval, "How long each combination is shown")
+	once := flags.Bool("once", false, "Print every combination once, one after another, and exit")
+	showColors := flags.Bool("color", true, "Also cycle through the color schemes")
+	text := strings.Join(parseInterspersed(flags, args), " ")
+	if text == "" {
+		fmt.Println("Usage: ascii-art shuffle [-seed N] [-interval 2s] [-once] [-color=false] TEXT")
+		os.Exit(1)
+	}
+
+	var combos []styleCombo
+	for _, combo := range config.styleCombos() {
+		if !*showColors {
+			combos = append(combos, combo)
+			continue
+		}
+		for scheme := range config.colors {
+			combo.scheme = scheme
+			combos = append(combos, combo)
+		}
+	}
+	if err := checkRenderText(text); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := checkRenderFrames(len(combos)); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	rng := newRand(*seed)
+	draw := func(combo styleCombo) string {
+		category := config.categories[combo.category]
+		style := category.Styles[combo.style]
+		label := fmt.Sprintf("%d.%d %s", combo.category+1, combo.style+1, style.Name)
+		var scheme *asciiart.ColorScheme
+		if combo.scheme >= 0 {
+			scheme = &config.colors[combo.scheme]
+			label += fmt.Sprintf(" · %d. %s", combo.scheme+1, scheme.Name)
+		}
+		if err := checkRenderSize(config.generateArt(text, style, nil)); err != nil {
+			return color.HiBlackString(label) + "\n" + color.RedString("Error: %v", err)
+		}
+		return color.HiBlackString(label) + "\n" + config.withWarnings(nil).generateArt(text, style, scheme)
+	}
+
+	if *once || *interval <= 0 || !isTerminal(os.Stdout) {
+		rng.Shuffle(len(combos), func(i, j int) { combos[i], combos[j] = combos[j], combos[i] })
+		for i, combo := range combos {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Println(draw(combo))
+		}
+		return
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	scr := newScreen(os.Stdout)
+	scr.open()
+	defer scr.close()
+	resized, stopResize := watchResize()
+	defer stopResize()
+	for {
+		rng.Shuffle(len(combos), func(i, j int) { combos[i], combos[j] = combos[j], combos[i] })
+		for _, combo := range combos {
+			scr.draw(draw(combo))
+			wait := time.After(*interval)
+		waiting:
+			for {
+				select {
+				case <-wait:
+					break waiting
+				case <-resized:
+					scr.invalidate()
+					scr.draw(draw(combo))
+				case <-interrupt:
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitIdle is how long a client may go quiet before its bucket is
+// forgotten; by then it has refilled anyway
+const rateLimitIdle = 10 * time.Minute
+
+// RateLimiter lets each client make perMinute requests a minute, in
+// bursts of up to perMinute, and answers the rest with 429 Too Many
+// Requests. Clients are told apart by their address.
+type RateLimiter struct {
+	perMinute int
+
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+	swept   time.Time
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns nil, for no limit, when perMinute is 0
+func newRateLimiter(perMinute int) *RateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &RateLimiter{perMinute: perMinute, buckets: make(map[string]*rateBucket)}
+}
+
+// allow takes a token from client's bucket, or reports how long until
+// the next one if it is empty
+func (l *RateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.swept) > rateLimitIdle {
+		for c, b := range l.buckets {
+			if now.Sub(b.last) > rateLimitIdle {
+				delete(l.buckets, c)
+			}
+		}
+		l.swept = now
+	}
+
+	rate := float64(l.perMinute) / time.Minute.Seconds()
+	b := l.buckets[client]
+	if b == nil {
+		b = &rateBucket{tokens: float64(l.perMinute), last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = min(float64(l.perMinute), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// wrap answers requests over the limit with 429. A nil RateLimiter lets
+// everything through.
+func (l *RateLimiter) wrap(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, wait := l.allow(clientHost(r.RemoteAddr), time.Now())
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientHost drops the port from a RemoteAddr, so every connection
+// from one machine shares a limit
+func clientHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
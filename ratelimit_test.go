@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(2)
+	start := time.Now()
+	steps := []struct {
+		client string
+		after  time.Duration
+		allow  bool
+	}{
+		{"a", 0, true},
+		{"a", 0, true},
+		{"a", 0, false}, // Burst used up
+		{"b", 0, true},  // Other clients have their own bucket
+		{"a", 29 * time.Second, false},
+		{"a", 30 * time.Second, true}, // One token back every 30s
+		{"a", 30 * time.Second, false},
+		{"a", time.Hour, true},
+		{"a", time.Hour, true},
+		{"a", time.Hour, false}, // Refills up to the burst only
+	}
+	for i, step := range steps {
+		allowed, wait := limiter.allow(step.client, start.Add(step.after))
+		if allowed != step.allow {
+			t.Errorf("step %d: allow(%q) after %s = %v, want %v", i, step.client, step.after, allowed, step.allow)
+		}
+		if !allowed && wait <= 0 {
+			t.Errorf("step %d: refused with no wait", i)
+		}
+	}
+	if newRateLimiter(0) != nil {
+		t.Errorf("newRateLimiter(0) should disable the limit")
+	}
+}
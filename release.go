@@ -0,0 +1,174 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+// Commit is one entry of a release summary
+type Commit struct {
+	hash    string
+	subject string
+}
+
+// releaseSections group commits by conventional-commit type, in display order
+var releaseSections = []struct {
+	title string
+	types []string
+}{
+	{"Features", []string{"feat"}},
+	{"Fixes", []string{"fix"}},
+	{"Performance", []string{"perf"}},
+	{"Documentation", []string{"docs"}},
+	{"Other Changes", nil},
+}
+
+var conventionalPrefix = regexp.MustCompile(`^(\w+)(\([^)]*\))?!?:\s*`)
+
+// runRelease renders a release banner and the commits since the previous
+// tag, read from the local git repository.
+func runRelease(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("release", flag.ExitOnError)
+	format := flags.String("format", "text", "Output format: text or markdown")
+	font := flags.String("font", "big", "Figure font for the banner")
+	colorFlag := flags.Int("colorscheme", 1, "Color scheme number (0 for none)")
+	since := flags.String("since", "", "Start of the range (default: the previous tag)")
+	positional := parseInterspersed(flags, args)
+
+	if len(positional) != 1 {
+		fmt.Println("Usage: ascii-art release [-format text|markdown] [-since TAG] vX.Y.Z")
+		os.Exit(1)
+	}
+	version := positional[0]
+
+	// An existing tag closes the range; otherwise the release is HEAD
+	end := "HEAD"
+	if _, err := git("rev-parse", "--verify", "--quiet", version+"^{commit}"); err == nil {
+		end = version
+	}
+	start := *since
+	if start == "" {
+		start, _ = git("describe", "--tags", "--abbrev=0", end+"^")
+	}
+
+	commits, err := releaseCommits(start, end)
+	if err != nil {
+		fmt.Printf("Error reading git history: %v\n", err)
+		os.Exit(1)
+	}
+	date := time.Now().Format("2006-01-02")
+	if end != "HEAD" {
+		if d, err := git("log", "-1", "--format=%cs", end); err == nil {
+			date = d
+		}
+	}
+
+	switch *format {
+	case "markdown", "md":
+		fmt.Print(releaseMarkdown(version, date, start, commits))
+	case "text":
+		art := strings.TrimRight(renderer.Figure(version, *font), "\n")
+		if *colorFlag > 0 && *colorFlag <= len(config.colors) {
+			art = asciiart.ApplyColorScheme(art, &config.colors[*colorFlag-1])
+		}
+		fmt.Println(art)
+		fmt.Println(color.HiBlackString(releaseRangeLabel(date, start, len(commits))))
+		for _, section := range groupCommits(commits) {
+			fmt.Println(color.CyanString("\n%s", section.title))
+			for _, c := range section.commits {
+				fmt.Printf("  • %s %s\n", c.subject, color.HiBlackString(c.hash))
+			}
+		}
+	default:
+		fmt.Printf("Error: unknown format %q\n", *format)
+		os.Exit(1)
+	}
+}
+
+func git(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func releaseCommits(start, end string) ([]Commit, error) {
+	rangeSpec := end
+	if start != "" {
+		rangeSpec = start + ".." + end
+	}
+	out, err := git("log", "--no-merges", "--format=%h%x09%s", rangeSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(out, "\n") {
+		if hash, subject, ok := strings.Cut(line, "\t"); ok {
+			commits = append(commits, Commit{hash, subject})
+		}
+	}
+	return commits, nil
+}
+
+type commitSection struct {
+	title   string
+	commits []Commit
+}
+
+// groupCommits sorts commits into releaseSections, stripping the
+// conventional-commit prefix from the subject.
+func groupCommits(commits []Commit) []commitSection {
+	grouped := make([][]Commit, len(releaseSections))
+	for _, c := range commits {
+		index := len(releaseSections) - 1
+		if m := conventionalPrefix.FindStringSubmatch(c.subject); m != nil {
+			for i, section := range releaseSections {
+				for _, t := range section.types {
+					if strings.EqualFold(m[1], t) {
+						index = i
+						c.subject = c.subject[len(m[0]):]
+					}
+				}
+			}
+		}
+		grouped[index] = append(grouped[index], c)
+	}
+
+	var sections []commitSection
+	for i, commits := range grouped {
+		if len(commits) > 0 {
+			sections = append(sections, commitSection{releaseSections[i].title, commits})
+		}
+	}
+	return sections
+}
+
+func releaseRangeLabel(date, start string, count int) string {
+	if start == "" {
+		return fmt.Sprintf("%s · %d commits", date, count)
+	}
+	return fmt.Sprintf("%s · %d commits since %s", date, count, start)
+}
+
+// releaseMarkdown formats the summary as a CHANGELOG entry
+func releaseMarkdown(version, date, start string, commits []Commit) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s (%s)\n", version, date)
+	for _, section := range groupCommits(commits) {
+		fmt.Fprintf(&b, "\n### %s\n\n", section.title)
+		for _, c := range section.commits {
+			fmt.Fprintf(&b, "- %s (%s)\n", c.subject, c.hash)
+		}
+	}
+	if len(commits) == 0 {
+		b.WriteString("\nNo changes.\n")
+	}
+	return b.String()
+}
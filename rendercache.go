@@ -0,0 +1,109 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+)
+
+// renderCacheSize is how many renders serve keeps by default
+const renderCacheSize = 256
+
+// RenderCache keeps the most recently used renders, so polling clients
+// asking for the same banner again get it without a render, along with
+// an ETag that says whether it changed
+type RenderCache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List // Most recently used first
+	entries map[string]*list.Element
+}
+
+// CachedRender is one successful render and the ETag of its body
+type CachedRender struct {
+	key      string
+	Response RenderResponse
+	Body     []byte
+	ETag     string
+}
+
+// newRenderCache returns nil, for no cache, when size is 0
+func newRenderCache(size int) *RenderCache {
+	if size <= 0 {
+		return nil
+	}
+	return &RenderCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// renderCacheKey identifies what request renders, leaving out its id.
+// Templates can fill in the time, so their renders are never cached.
+func renderCacheKey(request RenderRequest) (string, bool) {
+	if request.Template {
+		return "", false
+	}
+	request.ID = nil
+	key, err := json.Marshal(request)
+	return string(key), err == nil
+}
+
+func (c *RenderCache) get(key string) (*CachedRender, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*CachedRender), true
+}
+
+func (c *RenderCache) put(entry *CachedRender) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[entry.key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*CachedRender).key)
+	}
+}
+
+// render answers request from the cache if it can, else renders it and
+// keeps the result if it succeeded. Body is the response body for the
+// request's format. A nil RenderCache renders every request.
+func (c *RenderCache) render(config *AppConfig, request RenderRequest) *CachedRender {
+	key, cacheable := renderCacheKey(request)
+	if c != nil && cacheable {
+		if entry, ok := c.get(key); ok {
+			entry := *entry
+			entry.Response.ID = request.ID
+			return &entry
+		}
+	}
+
+	response := config.answer(request)
+	entry := &CachedRender{key: key, Response: response}
+	if response.Error != "" {
+		return entry
+	}
+	if response.Art != nil {
+		entry.Body, _ = json.Marshal(response.Art)
+		entry.Body = append(entry.Body, '\n')
+	} else {
+		entry.Body = []byte(response.Output + "\n")
+	}
+	sum := sha256.Sum256(entry.Body)
+	entry.ETag = `"` + hex.EncodeToString(sum[:16]) + `"`
+	if c != nil && cacheable {
+		c.put(entry)
+	}
+	return entry
+}
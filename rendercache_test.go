@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestRenderETag(t *testing.T) {
+	server := testServer(t)
+	get := func(path, etag string) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		req.Header.Set("X-Api-Key", testAPIKey)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp, resp.Header.Get("ETag")
+	}
+
+	first, etag := get("/render?text=Hi&format=html", "")
+	if first.StatusCode != http.StatusOK || etag == "" {
+		t.Fatalf("first request: %s, ETag %q", first.Status, etag)
+	}
+	if got := first.Header.Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Cache-Control = %q, want no-cache", got)
+	}
+	tests := []struct {
+		path, ifNoneMatch string
+		status            int
+	}{
+		{"/render?text=Hi&format=html", etag, http.StatusNotModified},
+		{"/render?text=Hi&format=html", `"other", W/` + etag, http.StatusNotModified},
+		{"/render?text=Hi&format=html", `"other"`, http.StatusOK},
+		{"/render?text=Ho&format=html", etag, http.StatusOK},
+		{"/render?text=Hi&format=svg", etag, http.StatusOK},
+		{"/render?text={upper:hi}&template=true", "", http.StatusOK},
+	}
+	for _, test := range tests {
+		resp, got := get(test.path, test.ifNoneMatch)
+		if resp.StatusCode != test.status {
+			t.Errorf("%s, If-None-Match %s: %s, want %d", test.path, test.ifNoneMatch, resp.Status, test.status)
+		}
+		if test.status == http.StatusNotModified && got != etag {
+			t.Errorf("%s: ETag %q on 304, want %q", test.path, got, etag)
+		}
+	}
+
+	// Served from the cache, the same request keeps its ETag
+	_, again := get("/render?text=Hi&format=html", "")
+	if again != etag {
+		t.Errorf("ETag changed from %s to %s for the same request", etag, again)
+	}
+}
+
+func TestRenderCacheEvicts(t *testing.T) {
+	config := newAppConfig()
+	cache := newRenderCache(2)
+	for i := range 3 {
+		cache.render(config, RenderRequest{Text: fmt.Sprint(i)})
+	}
+	for i, want := range []bool{false, true, true} {
+		key, _ := renderCacheKey(RenderRequest{Text: fmt.Sprint(i)})
+		if _, ok := cache.get(key); ok != want {
+			t.Errorf("text %d cached: %v, want %v", i, ok, want)
+		}
+	}
+
+	hit := cache.render(config, RenderRequest{ID: []byte("7"), Text: "2"})
+	if string(hit.Response.ID) != "7" {
+		t.Errorf("cached response has id %s, want the request's 7", hit.Response.ID)
+	}
+	if _, ok := renderCacheKey(RenderRequest{Text: "{time}", Template: true}); ok {
+		t.Errorf("template requests should not be cached")
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// End of every -repl-plain response
+const replEnd = "END"
+
+// runReplPlain is a strict line protocol for shell co-processes
+// (coproc ascii-art -repl-plain). Each input line is text to render, or
+// a command starting with ':':
+//
+//	:style Big | :font small | :colorscheme Ocean | :format ansi | :quit
+//
+// Every reply starts with "OK <lines>" followed by that many lines of
+// art, or "ERR <message>", and ends with a line holding only END.
+func runReplPlain(config *AppConfig, options RenderOptions) {
+	request := RenderRequest{Format: "text"}
+	if options.category > 0 && options.style > 0 {
+		request.Style = fmt.Sprintf("%d.%d", options.category, options.style)
+	}
+	if options.colorScheme > 0 {
+		request.ColorScheme = fmt.Sprint(options.colorScheme)
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRequestSize)
+	out := bufio.NewWriter(os.Stdout)
+	reply := func(lines ...string) {
+		for _, line := range lines {
+			fmt.Fprintln(out, line)
+		}
+		fmt.Fprintln(out, replEnd)
+		out.Flush()
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if command, ok := strings.CutPrefix(line, ":"); ok {
+			name, value, _ := strings.Cut(command, " ")
+			if name == "quit" {
+				reply("OK 0")
+				return
+			}
+			if err := config.setReplOption(&request, name, strings.TrimSpace(value)); err != nil {
+				reply("ERR " + err.Error())
+			} else {
+				reply("OK 0")
+			}
+			continue
+		}
+		if line == "" {
+			reply("ERR empty request")
+			continue
+		}
+
+		request.Text = line
+		response := config.answer(request)
+		config.audit.record("repl-plain", request, response)
+		if response.Error != "" {
+			reply("ERR " + response.Error)
+			continue
+		}
+		art := strings.Split(response.Output, "\n")
+		reply(append([]string{fmt.Sprintf("OK %d", len(art))}, art...)...)
+	}
+}
+
+// setReplOption applies one -repl-plain command, checking the value
+// with a trial render so mistakes are reported right away
+func (config *AppConfig) setReplOption(request *RenderRequest, name, value string) error {
+	trial := *request
+	switch name {
+	case "style":
+		trial.Style = value
+	case "font":
+		trial.Font = value
+	case "colorscheme":
+		trial.ColorScheme = value
+	case "format":
+		if value == "json" {
+			return fmt.Errorf("format json is not line oriented; use -stdin-json")
+		}
+		trial.Format = value
+	default:
+		return fmt.Errorf("unknown command :%s", name)
+	}
+
+	trial.Text = " "
+	if response := config.answer(trial); response.Error != "" {
+		return fmt.Errorf("%s", response.Error)
+	}
+	*request = trial
+	request.Text = ""
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"ascii-art/asciiart"
+)
+
+// Limits on themes sent with a render request
+const (
+	maxThemeSize    = 4096 // Bytes of theme JSON in a query param or header
+	maxThemePiece   = 8    // Characters in one border piece or the fill
+	maxThemeEffects = 4
+	maxThemeStops   = 16
+)
+
+// themeHeader carries a RequestTheme as JSON, as an alternative to the
+// theme query parameter
+const themeHeader = "X-Ascii-Art-Theme"
+
+// RequestTheme is a style defined in full by a render request instead of
+// named from the built-in list. Border pieces default as in the config
+// file's decorators; without any, the requested style's border is kept.
+type RequestTheme struct {
+	Chars       string   `json:"chars,omitempty"` // As for -border-chars
+	Top         string   `json:"top,omitempty"`
+	Bottom      string   `json:"bottom,omitempty"`
+	Left        string   `json:"left,omitempty"`
+	Right       string   `json:"right,omitempty"`
+	Corners     []string `json:"corners,omitempty"` // TL, TR, BL, BR, or one for all four
+	Fill        string   `json:"fill,omitempty"`
+	Effects     []string `json:"effects,omitempty"`  // Applied in order after the border
+	Gradient    string   `json:"gradient,omitempty"` // Preset name, hex stops or "rainbow"
+	GradientDir string   `json:"gradient_dir,omitempty"`
+}
+
+// parseTheme decodes a theme passed as a query parameter or header
+func parseTheme(data string) (*RequestTheme, error) {
+	if len(data) > maxThemeSize {
+		return nil, fmt.Errorf("theme is larger than %d bytes", maxThemeSize)
+	}
+	theme := &RequestTheme{}
+	if err := json.Unmarshal([]byte(data), theme); err != nil {
+		return nil, fmt.Errorf("invalid theme: %v", err)
+	}
+	return theme, nil
+}
+
+func (t *RequestTheme) validate() error {
+	if len(t.Corners) > 4 {
+		return fmt.Errorf("theme has %d corners, at most 4 allowed", len(t.Corners))
+	}
+	if len(t.Effects) > maxThemeEffects {
+		return fmt.Errorf("theme has %d effects, at most %d allowed", len(t.Effects), maxThemeEffects)
+	}
+	for _, effect := range t.Effects {
+		if !isBuiltinEffect(effect) {
+			return fmt.Errorf("unknown effect %q (use shadow, indent or braille)", effect)
+		}
+	}
+	return nil
+}
+
+// checkPieces rejects border pieces longer than maxThemePiece
+func checkPieces(d asciiart.Decorator) error {
+	for _, piece := range append([]string{d.Top, d.Bottom, d.Left, d.Right, d.Fill}, d.Corners[:]...) {
+		if utf8.RuneCountInString(piece) > maxThemePiece {
+			return fmt.Errorf("theme border piece %q is longer than %d characters", piece, maxThemePiece)
+		}
+	}
+	return nil
+}
+
+// apply returns style with the theme's border and effects, and the
+// theme's gradient, if any, in true color
+func (t *RequestTheme) apply(style asciiart.Style) (asciiart.Style, *asciiart.Gradient, error) {
+	if err := t.validate(); err != nil {
+		return style, nil, err
+	}
+
+	if t.Chars != "" || t.Top != "" || t.Bottom != "" || t.Left != "" || t.Right != "" || len(t.Corners) > 0 {
+		d := DecoratorConfig{Chars: t.Chars, Top: t.Top, Bottom: t.Bottom, Left: t.Left, Right: t.Right, Corners: t.Corners, Fill: t.Fill}
+		themed, err := d.style()
+		if err == nil {
+			err = checkPieces(themed.Decorator)
+		}
+		if err != nil {
+			return style, nil, err
+		}
+		style.Decorator = themed.Decorator
+	} else if t.Fill != "" {
+		if err := checkPieces(asciiart.Decorator{Fill: t.Fill}); err != nil {
+			return style, nil, err
+		}
+		style.Decorator.Fill = t.Fill
+	}
+
+	for _, name := range t.Effects {
+		style = withEffect(style, name, asciiart.LookupEffect(name))
+	}
+
+	if t.Gradient == "" {
+		return style, nil, nil
+	}
+	if t.Gradient == "rainbow" {
+		return style, asciiart.NewRainbow(asciiart.DefaultRainbowFrequency, 0, asciiart.DepthTrue), nil
+	}
+	stops, err := asciiart.ParseGradient(t.Gradient)
+	if err != nil {
+		return style, nil, err
+	}
+	if len(stops) > maxThemeStops {
+		return style, nil, fmt.Errorf("theme gradient has %d stops, at most %d allowed", len(stops), maxThemeStops)
+	}
+	direction := t.GradientDir
+	if direction == "" {
+		direction = asciiart.GradientHorizontal
+	}
+	gradient, err := asciiart.NewGradient(stops, direction, asciiart.DepthTrue)
+	return style, gradient, err
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"ascii-art/asciiart"
+)
+
+func TestRequestThemeLimits(t *testing.T) {
+	stops := func(n int) string {
+		return strings.TrimSuffix(strings.Repeat("#ff0000:", n), ":")
+	}
+	effects := func(names ...string) string {
+		list, _ := json.Marshal(names)
+		return string(list)
+	}
+	tests := []struct {
+		name, theme string
+		fails       bool
+	}{
+		{"empty", `{}`, false},
+		{"not JSON", `{"chars":`, true},
+		{"largest", "{" + strings.Repeat(" ", maxThemeSize-2) + "}", false},
+		{"too large", "{" + strings.Repeat(" ", maxThemeSize-1) + "}", true},
+
+		{"border chars", `{"chars":"─,│,┌,┐,└,┘"}`, false},
+		{"bad border chars", `{"chars":"-,|"}`, true},
+		{"longest piece", `{"top":"` + strings.Repeat("=", maxThemePiece) + `"}`, false},
+		{"long piece", `{"top":"` + strings.Repeat("=", maxThemePiece+1) + `"}`, true},
+		{"long corner", `{"top":"-","corners":["` + strings.Repeat("+", maxThemePiece+1) + `"]}`, true},
+		{"long fill alone", `{"fill":"` + strings.Repeat(".", maxThemePiece+1) + `"}`, true},
+		{"four corners", `{"top":"-","corners":["1","2","3","4"]}`, false},
+		{"five corners", `{"top":"-","corners":["1","2","3","4","5"]}`, true},
+
+		{"built-in effects", `{"effects":` + effects("shadow", "Indent", "braille") + `}`, false},
+		{"most effects", `{"effects":` + effects("indent", "indent", "indent", "indent") + `}`, false},
+		{"too many effects", `{"effects":` + effects("indent", "indent", "indent", "indent", "indent") + `}`, true},
+		{"plugin effect", `{"effects":` + effects("rot13") + `}`, true},
+		{"path as effect", `{"effects":` + effects("../../bin/sh") + `}`, true},
+
+		{"rainbow", `{"gradient":"rainbow"}`, false},
+		{"preset", `{"gradient":"` + asciiart.GradientNames()[0] + `"}`, false},
+		{"most stops", `{"gradient":"` + stops(maxThemeStops) + `"}`, false},
+		{"too many stops", `{"gradient":"` + stops(maxThemeStops+1) + `"}`, true},
+		{"bad stop", `{"gradient":"#ff0000:red-ish"}`, true},
+		{"vertical", `{"gradient":"#ff0000:#0000ff","gradient_dir":"vertical"}`, false},
+		{"bad direction", `{"gradient":"#ff0000:#0000ff","gradient_dir":"sideways"}`, true},
+	}
+	for _, test := range tests {
+		theme, err := parseTheme(test.theme)
+		if err == nil {
+			_, _, err = theme.apply(asciiart.Style{Name: "Plain"})
+		}
+		if fails := err != nil; fails != test.fails {
+			t.Errorf("%s: error %v, want failure %v", test.name, err, test.fails)
+		}
+	}
+}
+
+func TestRequestThemeApply(t *testing.T) {
+	theme, err := parseTheme(`{"top":"=","left":"|","fill":".","gradient":"#ff0000:#0000ff"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	style, gradient, err := theme.apply(asciiart.Style{Name: "Plain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := style.Decorator
+	if d.Top != "=" || d.Bottom != "=" || d.Left != "|" || d.Right != "|" || d.Fill != "." || d.Corners != [4]string{"=", "=", "=", "="} {
+		t.Errorf("decorator = %+v, want the config file's defaults for top and left", d)
+	}
+	if gradient == nil {
+		t.Errorf("no gradient for two stops")
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"time"
+)
+
+// resizeSignals are the signals that report a terminal resize, where the
+// platform has them; elsewhere the size is polled
+var resizeSignals []os.Signal
+
+const resizePollInterval = 250 * time.Millisecond
+
+// watchResize returns a channel that receives whenever the terminal on
+// stdout changes size, and a function that stops watching
+func watchResize() (<-chan struct{}, func()) {
+	resized := make(chan struct{}, 1)
+	done := make(chan struct{})
+	notify := func() {
+		select {
+		case resized <- struct{}{}:
+		default: // One pending resize is enough
+		}
+	}
+
+	if len(resizeSignals) > 0 {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, resizeSignals...)
+		go func() {
+			for {
+				select {
+				case <-signals:
+					notify()
+				case <-done:
+					return
+				}
+			}
+		}()
+		return resized, func() {
+			signal.Stop(signals)
+			close(done)
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(resizePollInterval)
+		defer ticker.Stop()
+		width, height, _ := terminalSize()
+		for {
+			select {
+			case <-ticker.C:
+				if w, h, _ := terminalSize(); w != width || h != height {
+					width, height = w, h
+					notify()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return resized, func() { close(done) }
+}
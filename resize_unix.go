@@ -0,0 +1,12 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+func init() {
+	resizeSignals = []os.Signal{syscall.SIGWINCH}
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultAWSRegion = "us-east-1"
+
+// awsCredentials are the keys requests to AWS are signed with
+type awsCredentials struct {
+	accessKey, secretKey, token string
+}
+
+// putS3 uploads obj to S3, or to the S3-compatible service at
+// AWS_ENDPOINT_URL_S3 (or AWS_ENDPOINT_URL), such as MinIO
+func putS3(obj upload) error {
+	profile := cmp.Or(os.Getenv("AWS_PROFILE"), "default")
+	region := awsRegion(profile)
+	creds, err := awsCredentialChain(profile, region)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, s3URL(region, obj.bucket, obj.key), bytes.NewReader(obj.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", obj.contentType)
+	sum := sha256.Sum256(obj.body)
+	creds.sign(req, hex.EncodeToString(sum[:]), region, "s3", time.Now().UTC())
+	resp, err := uploadClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse("S3", resp)
+}
+
+// s3URL is the address of an object: virtual-hosted on AWS, except for
+// bucket names with dots, which TLS certificates do not cover, and
+// path-style on other endpoints
+func s3URL(region, bucket, key string) string {
+	if endpoint := cmp.Or(os.Getenv("AWS_ENDPOINT_URL_S3"), os.Getenv("AWS_ENDPOINT_URL")); endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/") + "/" + awsEscape(bucket, false) + "/" + awsEscape(key, true)
+	}
+	if strings.Contains(bucket, ".") {
+		return fmt.Sprintf("https://s3.%s.amazonaws.com/%s/%s", region, bucket, awsEscape(key, true))
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, region, awsEscape(key, true))
+}
+
+// awsEscape percent-encodes s the way Signature Version 4 expects: every
+// byte but letters, digits and -._~, and slashes too unless keepSlash
+func awsEscape(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '/' && keepSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// sign adds Signature Version 4 headers to req for service in region.
+// payloadHash is the hex SHA-256 of the body.
+func (c awsCredentials) sign(req *http.Request, payloadHash, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.token != "" {
+		req.Header.Set("X-Amz-Security-Token", c.token)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.Join(values, ",")
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.TrimSpace(headers[name]))
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			params = append(params, awsEscape(key, false)+"="+awsEscape(value, false))
+		}
+	}
+
+	canonical := strings.Join([]string{
+		req.Method,
+		cmp.Or(req.URL.EscapedPath(), "/"),
+		strings.Join(params, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", amzDate[:8], region, service)
+	sum := sha256.Sum256([]byte(canonical))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	key := []byte("AWS4" + c.secretKey)
+	for _, part := range []string{amzDate[:8], region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsCredentialChain finds credentials the way the AWS CLI and SDKs do:
+// from the environment, a web identity token (EKS, GitHub Actions OIDC),
+// the shared credentials file, the ECS container endpoint, then the EC2
+// instance metadata service
+func awsCredentialChain(profile, region string) (awsCredentials, error) {
+	sources := []func() (awsCredentials, error){
+		awsEnvCredentials,
+		func() (awsCredentials, error) { return awsWebIdentityCredentials(region) },
+		func() (awsCredentials, error) { return awsSharedCredentials(profile) },
+		awsContainerCredentials,
+		awsInstanceCredentials,
+	}
+	for _, source := range sources {
+		creds, err := source()
+		if !errors.Is(err, errNoCredentials) {
+			return creds, err
+		}
+	}
+	return awsCredentials{}, errors.New("no AWS credentials found (set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or AWS_PROFILE)")
+}
+
+func awsEnvCredentials() (awsCredentials, error) {
+	creds := awsCredentials{os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), os.Getenv("AWS_SESSION_TOKEN")}
+	if creds.accessKey == "" || creds.secretKey == "" {
+		return creds, errNoCredentials
+	}
+	return creds, nil
+}
+
+// awsWebIdentityCredentials exchanges the token in
+// AWS_WEB_IDENTITY_TOKEN_FILE for AWS_ROLE_ARN's credentials
+func awsWebIdentityCredentials(region string) (awsCredentials, error) {
+	tokenFile, role := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"), os.Getenv("AWS_ROLE_ARN")
+	if tokenFile == "" || role == "" {
+		return awsCredentials{}, errNoCredentials
+	}
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	query := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {role},
+		"RoleSessionName":  {cmp.Or(os.Getenv("AWS_ROLE_SESSION_NAME"), "ascii-art")},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	resp, err := uploadClient.Get(fmt.Sprintf("https://sts.%s.amazonaws.com/?%s", region, query.Encode()))
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse("AWS STS", resp); err != nil {
+		return awsCredentials{}, err
+	}
+	var result struct {
+		Credentials struct {
+			AccessKeyId, SecretAccessKey, SessionToken string
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return awsCredentials{}, fmt.Errorf("AWS STS: %v", err)
+	}
+	c := result.Credentials
+	return awsCredentials{c.AccessKeyId, c.SecretAccessKey, c.SessionToken}, nil
+}
+
+// awsFile returns the path of a file in ~/.aws, or the one env names
+func awsFile(env, name string) string {
+	if path := os.Getenv(env); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".aws", name)
+}
+
+// readINI returns the keys of section in the INI file at path, or nil
+// when there is no such file or section
+func readINI(path, section string) map[string]string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var values map[string]string
+	current := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[' && strings.HasSuffix(line, "]"):
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			if current == section && values == nil {
+				values = make(map[string]string)
+			}
+		case current == section:
+			if key, value, ok := strings.Cut(line, "="); ok {
+				values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return values
+}
+
+// awsRegion is AWS_REGION, AWS_DEFAULT_REGION, or the profile's region
+// in ~/.aws/config, with us-east-1 as the last resort
+func awsRegion(profile string) string {
+	if region := cmp.Or(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION")); region != "" {
+		return region
+	}
+	section := "profile " + profile
+	if profile == "default" {
+		section = "default"
+	}
+	return cmp.Or(readINI(awsFile("AWS_CONFIG_FILE", "config"), section)["region"], defaultAWSRegion)
+}
+
+func awsSharedCredentials(profile string) (awsCredentials, error) {
+	values := readINI(awsFile("AWS_SHARED_CREDENTIALS_FILE", "credentials"), profile)
+	creds := awsCredentials{values["aws_access_key_id"], values["aws_secret_access_key"], values["aws_session_token"]}
+	if creds.accessKey == "" || creds.secretKey == "" {
+		return creds, errNoCredentials
+	}
+	return creds, nil
+}
+
+// awsMetadataCredentials decodes the credentials a container or instance
+// metadata endpoint returns
+func awsMetadataCredentials(req *http.Request) (awsCredentials, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse("AWS credentials endpoint", resp); err != nil {
+		return awsCredentials{}, err
+	}
+	var c struct {
+		AccessKeyId, SecretAccessKey, Token string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&c); err != nil {
+		return awsCredentials{}, fmt.Errorf("AWS credentials endpoint: %v", err)
+	}
+	return awsCredentials{c.AccessKeyId, c.SecretAccessKey, c.Token}, nil
+}
+
+// awsContainerCredentials asks the ECS (or EKS Pod Identity) agent
+func awsContainerCredentials() (awsCredentials, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relative := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+		endpoint = "http://169.254.170.2" + relative
+	}
+	if endpoint == "" {
+		return awsCredentials{}, errNoCredentials
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), credentialTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if file := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return awsCredentials{}, err
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	return awsMetadataCredentials(req)
+}
+
+// awsInstanceCredentials asks the EC2 instance metadata service (IMDSv2)
+// for the instance role's credentials. Anywhere but EC2 it finds none.
+func awsInstanceCredentials() (awsCredentials, error) {
+	if strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		return awsCredentials{}, errNoCredentials
+	}
+	endpoint := strings.TrimSuffix(cmp.Or(os.Getenv("AWS_EC2_METADATA_SERVICE_ENDPOINT"), "http://169.254.169.254"), "/")
+	ctx, cancel := context.WithTimeout(context.Background(), credentialTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	req.Header.Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", "60")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return awsCredentials{}, errNoCredentials
+	}
+	var token bytes.Buffer
+	token.ReadFrom(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return awsCredentials{}, errNoCredentials
+	}
+
+	get := func(path string) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+path, nil)
+		if err == nil {
+			req.Header.Set("X-Aws-Ec2-Metadata-Token", token.String())
+		}
+		return req, err
+	}
+	req, err = get("/latest/meta-data/iam/security-credentials/")
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	var role bytes.Buffer
+	role.ReadFrom(resp.Body)
+	resp.Body.Close()
+	name, _, _ := strings.Cut(strings.TrimSpace(role.String()), "\n")
+	if resp.StatusCode != http.StatusOK || name == "" {
+		return awsCredentials{}, errNoCredentials
+	}
+	if req, err = get("/latest/meta-data/iam/security-credentials/" + name); err != nil {
+		return awsCredentials{}, err
+	}
+	return awsMetadataCredentials(req)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+// newSAUCE returns the SAUCE record for -sauce and its fields, or nil if
+// none of them were given
+func newSAUCE(enabled bool, title, author, group string) *asciiart.SAUCE {
+	if !enabled && title == "" && author == "" && group == "" {
+		return nil
+	}
+	return &asciiart.SAUCE{Title: title, Author: author, Group: group}
+}
+
+// withSAUCE appends record to art written in format, ans or text, with
+// the type, dimensions and date filled in from the art
+func withSAUCE(art, format string, record asciiart.SAUCE) string {
+	plain := art
+	record.DataType, record.FileType = asciiart.SAUCECharacter, asciiart.SAUCEASCII
+	if format == "ans" {
+		canvas, _ := asciiart.ParseANSIArt([]byte(art))
+		plain = canvas.Text()
+		record.FileType = asciiart.SAUCEANSi
+		record.Flags |= asciiart.SAUCEiCEColors
+		if record.Font == "" {
+			record.Font = "IBM VGA"
+		}
+	}
+	lines := strings.Split(strings.TrimRight(plain, "\n"), "\n")
+	record.Width, record.Height = blockWidth(lines), len(lines)
+	if record.Date.IsZero() {
+		record.Date = time.Now()
+	}
+	return string(record.Append([]byte(art)))
+}
+
+// mergeSAUCE fills in the fields of record that were not given from the
+// record an -ans file came with, so re-exported art keeps its credits
+func mergeSAUCE(record *asciiart.SAUCE, original asciiart.SAUCE) *asciiart.SAUCE {
+	if record == nil {
+		return &original
+	}
+	merged := *record
+	merged.Title = cmp.Or(merged.Title, original.Title)
+	merged.Author = cmp.Or(merged.Author, original.Author)
+	merged.Group = cmp.Or(merged.Group, original.Group)
+	merged.Date, merged.Font, merged.Comments = original.Date, original.Font, original.Comments
+	return &merged
+}
+
+// printSAUCE shows the credits of a SAUCE record under the art
+func printSAUCE(record *asciiart.SAUCE) {
+	var parts []string
+	if record.Title != "" {
+		parts = append(parts, fmt.Sprintf("%q", record.Title))
+	}
+	if record.Author != "" {
+		parts = append(parts, "by "+record.Author)
+	}
+	if record.Group != "" {
+		parts = append(parts, "of "+record.Group)
+	}
+	if record.Width > 0 && record.Height > 0 {
+		parts = append(parts, fmt.Sprintf("%dx%d", record.Width, record.Height))
+	}
+	if !record.Date.IsZero() {
+		parts = append(parts, record.Date.Format(time.DateOnly))
+	}
+	if record.Font != "" {
+		parts = append(parts, record.Font)
+	}
+	fmt.Println(color.HiBlackString(strings.Join(parts, " · ")))
+	for _, comment := range record.Comments {
+		fmt.Println(color.HiBlackString("  " + comment))
+	}
+}
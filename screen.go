@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ascii-art/asciiart"
+)
+
+const sgrReset = "\033[0m"
+
+// screenBridge is the longest run of unchanged cells rewritten rather
+// than skipped, since a cursor move costs at least as many bytes
+const screenBridge = 4
+
+// screenCell is one terminal cell of a frame: the character drawn there,
+// with any combining marks, and the color sequences in effect. The second
+// cell of a wide character has no text.
+type screenCell struct {
+	text  string
+	style string
+}
+
+// screen redraws live output in place, writing only the cells that
+// changed since the previous frame. A ticking clock then costs a few
+// bytes a second over SSH instead of a full-screen redraw, and the
+// unchanged parts of the screen never flicker.
+type screen struct {
+	w     io.Writer
+	front [][]screenCell // What the terminal shows; nil until the first frame
+	sync  bool           // Wrap updates in synchronized output sequences
+	alt   bool           // Draw on the alternate screen between open and close
+}
+
+func newScreen(w io.Writer) *screen {
+	s := &screen{w: w}
+	if isTerminal(w) {
+		s.sync = synchronizedOutput(w.(*os.File))
+		s.alt = true
+	}
+	return s
+}
+
+// open hides the cursor and, on a terminal, switches to the alternate
+// screen, so frames never pile up in the user's scrollback
+func (s *screen) open() {
+	if s.alt {
+		io.WriteString(s.w, enterAltScreen)
+	}
+	io.WriteString(s.w, hideCursor)
+}
+
+// close shows the cursor again and returns to the normal screen, with
+// the scrollback as it was before open
+func (s *screen) close() {
+	io.WriteString(s.w, sgrReset+showCursor)
+	if s.alt {
+		io.WriteString(s.w, leaveAltScreen)
+	}
+}
+
+// draw shows frame, which may span lines and carry SGR color sequences.
+// The first frame clears the screen; later ones are sent as differences.
+// Each frame is composed off screen and sent in a single write, as a
+// synchronized update, so the terminal never shows one half drawn.
+func (s *screen) draw(frame string) {
+	s.drawRows(parseFrame(frame))
+}
+
+// drawClipped is draw for frames that may not fit: rows past height and
+// cells past width are left out, so nothing wraps or scrolls
+func (s *screen) drawClipped(frame string, width, height int) {
+	s.drawRows(clipFrame(parseFrame(frame), width, height))
+}
+
+func (s *screen) drawRows(back [][]screenCell) {
+	update := diffFrames(s.front, back)
+	s.front = back
+	if update == "" {
+		return
+	}
+	if s.sync {
+		update = beginSync + update + endSync
+	}
+	io.WriteString(s.w, update)
+}
+
+// invalidate makes the next frame redraw the whole screen, for when
+// something else has written to it
+func (s *screen) invalidate() {
+	s.front = nil
+}
+
+// diffFrames returns the output that turns the screen showing prev into
+// next: a cursor move and the new text for each run of changed cells,
+// then erasures for what next no longer covers. A nil prev clears the
+// screen first. The cursor is left at the start of the line below next.
+func diffFrames(prev, next [][]screenCell) string {
+	var b strings.Builder
+	if prev == nil {
+		b.WriteString(clearScreen)
+	}
+	style := ""
+	setStyle := func(want string) {
+		if want != style {
+			b.WriteString(sgrReset + want)
+			style = want
+		}
+	}
+
+	for row, line := range next {
+		var old []screenCell
+		if row < len(prev) {
+			old = prev[row]
+		}
+		for col := 0; col < len(line); {
+			if col < len(old) && line[col] == old[col] {
+				col++
+				continue
+			}
+			// Rewrite a wide character whole, never just its right half
+			for col > 0 && line[col].text == "" {
+				col--
+			}
+			fmt.Fprintf(&b, "\033[%d;%dH", row+1, col+1)
+			for col < len(line) && changedWithin(line, old, col, screenBridge+1) {
+				if line[col].text != "" {
+					setStyle(line[col].style)
+					b.WriteString(line[col].text)
+				}
+				col++
+			}
+		}
+		if len(old) > len(line) {
+			setStyle("")
+			fmt.Fprintf(&b, "\033[%d;%dH\033[K", row+1, len(line)+1)
+		}
+	}
+	if len(prev) > len(next) {
+		setStyle("")
+		fmt.Fprintf(&b, "\033[%d;1H\033[J", len(next)+1)
+	}
+
+	if b.Len() == 0 {
+		return ""
+	}
+	setStyle("")
+	fmt.Fprintf(&b, "\033[%d;1H", len(next)+1)
+	return b.String()
+}
+
+// clipFrame cuts rows down to width cells and height rows. A wide
+// character cut in half becomes a space.
+func clipFrame(rows [][]screenCell, width, height int) [][]screenCell {
+	rows = rows[:min(len(rows), height)]
+	for i, row := range rows {
+		if len(row) <= width {
+			continue
+		}
+		clipped := row[:width:width]
+		if width > 0 && row[width].text == "" {
+			for j := width - 1; j >= 0; j-- {
+				wide := clipped[j].text != ""
+				clipped[j].text = " "
+				if wide {
+					break
+				}
+			}
+		}
+		rows[i] = clipped
+	}
+	return rows
+}
+
+// changedWithin reports whether any of the n cells of line from col on
+// differ from old
+func changedWithin(line, old []screenCell, col, n int) bool {
+	for i := col; i < min(col+n, len(line)); i++ {
+		if i >= len(old) || line[i] != old[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFrame splits frame into rows of cells, keeping the color each cell
+// is drawn in. A trailing newline does not start another row.
+func parseFrame(frame string) [][]screenCell {
+	var rows [][]screenCell
+	style := ""
+	for _, line := range strings.Split(strings.TrimSuffix(frame, "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		var cells []screenCell
+		for len(line) > 0 {
+			if n := escapeLength(line); n > 0 {
+				if line[n-1] == 'm' {
+					style = addStyle(style, line[:n])
+				}
+				line = line[n:]
+				continue
+			}
+			// A cluster ends at the next escape sequence, if not before
+			text := line
+			if i := strings.IndexByte(line, '\x1b'); i > 0 {
+				text = line[:i]
+			}
+			cluster, width := asciiart.NextGrapheme(text)
+			line = line[len(cluster):]
+			switch width {
+			case 0:
+				// Zero width text draws over the character before it
+				i := len(cells) - 1
+				for i > 0 && cells[i].text == "" {
+					i--
+				}
+				if i >= 0 {
+					cells[i].text += cluster
+				}
+			default:
+				// Cells after the first of a wide character stay empty
+				for range width {
+					cells = append(cells, screenCell{cluster, style})
+					cluster = ""
+				}
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+// addStyle returns the color state after the SGR sequence seq: a reset
+// starts over, anything else adds to style
+func addStyle(style, seq string) string {
+	params := seq[2 : len(seq)-1]
+	switch {
+	case params == "" || params == "0":
+		return ""
+	case strings.HasPrefix(params, "0;"):
+		return seq
+	}
+	return style + seq
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "Rewrite the snapshots in testdata")
+
+// checkSnapshot compares got with testdata/name, or rewrites it with -update
+func checkSnapshot(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("screen differs from %s:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// plain returns frame without its escape sequences and trailing blanks,
+// as the vterm shows it
+func plain(frame string) string {
+	var b strings.Builder
+	for frame != "" {
+		if n := escapeLength(frame); n > 0 {
+			frame = frame[n:]
+			continue
+		}
+		b.WriteByte(frame[0])
+		frame = frame[1:]
+	}
+	lines := strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+func TestScreenDrawsFrames(t *testing.T) {
+	frames := []string{
+		"Hello\nworld",
+		"Hellp\nworld\nagain",
+		"\x1b[31mred\x1b[0m and plain",
+		"日本語 wide\n👨‍👩‍👧 family",
+		"日x語\nshort",
+		"",
+		"one\n\ntwo",
+	}
+	// The stopwatch draws with the terminal in raw mode
+	vt := newVTerm(40, 10)
+	vt.raw = true
+	scr := &screen{w: vt}
+	for _, frame := range frames {
+		scr.draw(frame)
+		if got, want := vt.text(), plain(frame); got != want {
+			t.Errorf("after drawing %q the screen shows\n%s\nwant\n%s", frame, got, want)
+		}
+	}
+	if len(vt.unknown) > 0 {
+		t.Errorf("unknown sequences written: %v", vt.unknown)
+	}
+}
+
+func TestScreenColors(t *testing.T) {
+	vt := newVTerm(20, 5)
+	scr := &screen{w: vt}
+	scr.draw("\x1b[31mab\x1b[0mc\n\x1b[1;38;2;0;128;255mde")
+	scr.draw("\x1b[31mab\x1b[0mX\n\x1b[1;38;2;0;128;255mde")
+	checks := []struct {
+		row, col int
+		pen      vtPen
+	}{
+		{0, 0, vtPen{fg: "31"}},
+		{0, 1, vtPen{fg: "31"}},
+		{0, 2, vtPen{}},
+		{1, 0, vtPen{fg: "38;2;0;128;255", bold: true}},
+	}
+	for _, c := range checks {
+		if got := vt.cell(c.row, c.col).pen; got != c.pen {
+			t.Errorf("cell %d,%d drawn with %+v, want %+v", c.row, c.col, got, c.pen)
+		}
+	}
+	if vt.pen != (vtPen{}) {
+		t.Errorf("colors left set after a frame: %+v", vt.pen)
+	}
+}
+
+func TestScreenSendsChanges(t *testing.T) {
+	vt := newVTerm(80, 10)
+	var out strings.Builder
+	scr := &screen{w: teeWriter{vt, &out}}
+	first := "12:00:00\n" + strings.Repeat("=", 60)
+	scr.draw(first)
+	out.Reset()
+
+	scr.draw("12:00:01\n" + strings.Repeat("=", 60))
+	if out.Len() >= len(first)/4 {
+		t.Errorf("one changed digit took %d bytes: %q", out.Len(), out.String())
+	}
+	writes := vt.writes
+	scr.draw("12:00:01\n" + strings.Repeat("=", 60))
+	if vt.writes != writes {
+		t.Errorf("an unchanged frame was written")
+	}
+	if got := vt.lines()[0]; got != "12:00:01" {
+		t.Errorf("screen shows %q", got)
+	}
+}
+
+func TestScreenInvalidate(t *testing.T) {
+	vt := newVTerm(20, 5)
+	scr := &screen{w: vt}
+	scr.draw("abc\ndef")
+	vt.Write([]byte("\x1b[1;1Hzzz\r\nsomething else"))
+	scr.invalidate()
+	scr.draw("abc\ndef")
+	if got := vt.text(); got != "abc\ndef" {
+		t.Errorf("screen shows %q after invalidate", got)
+	}
+}
+
+func TestScreenClipped(t *testing.T) {
+	vt := newVTerm(10, 3)
+	scr := &screen{w: vt}
+	scr.drawClipped("0123456789abc\n12345678日本\nshort\nbelow", 10, 3)
+	if got, want := vt.text(), "0123456789\n12345678日\nshort"; got != want {
+		t.Errorf("screen shows\n%s\nwant\n%s", got, want)
+	}
+	scr.drawClipped("123456789日", 10, 3)
+	if got, want := vt.lines()[0], "123456789"; got != want {
+		t.Errorf("a wide character cut in half shows as %q, want %q", got, want)
+	}
+	if len(vt.unknown) > 0 {
+		t.Errorf("unknown sequences written: %v", vt.unknown)
+	}
+}
+
+func TestScreenSynchronized(t *testing.T) {
+	vt := newVTerm(20, 5)
+	scr := &screen{w: vt, sync: true}
+	for _, frame := range []string{"a", "b", "b"} {
+		scr.draw(frame)
+		if vt.syncDepth != 0 {
+			t.Fatalf("synchronized update left open after %q", frame)
+		}
+	}
+	if vt.writes != 2 {
+		t.Errorf("%d writes for two frames, want one each", vt.writes)
+	}
+}
+
+func TestScreenAlternate(t *testing.T) {
+	vt := newVTerm(20, 5)
+	vt.Write([]byte("$ ascii-art clocks\r\n"))
+	scr := &screen{w: vt, alt: true}
+	scr.open()
+	if !vt.cursorHidden {
+		t.Error("cursor shown while drawing")
+	}
+	scr.draw("12:00")
+	if got := vt.text(); got != "12:00" {
+		t.Errorf("alternate screen shows %q", got)
+	}
+	scr.close()
+	if vt.cursorHidden {
+		t.Error("cursor still hidden after close")
+	}
+	if got := vt.text(); got != "$ ascii-art clocks" {
+		t.Errorf("normal screen shows %q after close", got)
+	}
+}
+
+func TestClocksSnapshot(t *testing.T) {
+	clocks := []clock{{"UTC", time.UTC}, {"Plus Five", time.FixedZone("UTC+5", 5*60*60)}}
+	now := time.Date(2024, 3, 9, 7, 58, 0, 0, time.UTC)
+	vt := newVTerm(100, 12)
+	scr := &screen{w: vt}
+	var screens []string
+	for range 3 {
+		scr.draw(strings.Join(renderClocks(clocks, now, "15:04", "small"), "\n"))
+		screens = append(screens, vt.text())
+		now = now.Add(time.Minute)
+	}
+	if screens[0] == screens[1] {
+		t.Error("the clocks did not change from one minute to the next")
+	}
+	checkSnapshot(t, "clocks.txt", strings.Join(screens, "\n----\n")+"\n")
+}
+
+// teeWriter writes to a vterm and records the bytes
+type teeWriter struct {
+	vt  *vterm
+	out *strings.Builder
+}
+
+func (w teeWriter) Write(p []byte) (int, error) {
+	w.out.Write(p)
+	return w.vt.Write(p)
+}
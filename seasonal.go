@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+const holidaysFileName = "holidays.yaml"
+
+// Holiday decorates banners automatically between two calendar days
+type Holiday struct {
+	Name        string `yaml:"name"`
+	From        string `yaml:"from"` // MM-DD
+	To          string `yaml:"to"`   // MM-DD, may wrap past new year
+	Top         string `yaml:"top"`
+	Bottom      string `yaml:"bottom"`
+	Left        string `yaml:"left"`
+	Right       string `yaml:"right"`
+	Corner      string `yaml:"corner"`
+	ColorScheme string `yaml:"colorscheme"`
+}
+
+var builtinHolidays = []Holiday{
+	{Name: "Valentine's Day", From: "02-14", To: "02-14", Top: "♥", Left: "♥", Corner: "♥", ColorScheme: "Sunset"},
+	{Name: "Halloween", From: "10-20", To: "10-31", Top: "~", Left: "🎃", Corner: "🎃", ColorScheme: "Sunset"},
+	{Name: "Winter", From: "12-01", To: "12-31", Top: "❄", Left: "❄", Corner: "❄", ColorScheme: "Ocean"},
+}
+
+// loadHolidays returns the holidays from every asset directory's
+// holidays.yaml followed by the built-in ones. Earlier entries win, so
+// users can override or extend the defaults.
+func loadHolidays() []Holiday {
+	var holidays []Holiday
+	for _, dir := range assetDirs() {
+		data, err := os.ReadFile(filepath.Join(dir, holidaysFileName))
+		if err != nil {
+			continue
+		}
+		var file struct {
+			Holidays []Holiday `yaml:"holidays"`
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			fmt.Fprintln(os.Stderr, color.YellowString("Warning: %s: %v", holidaysFileName, err))
+			continue
+		}
+		holidays = append(holidays, file.Holidays...)
+	}
+	return append(holidays, builtinHolidays...)
+}
+
+// activeHoliday returns the first holiday covering day
+func activeHoliday(holidays []Holiday, day time.Time) *Holiday {
+	today := day.Format("01-02")
+	for i, h := range holidays {
+		if h.From == "" || h.To == "" {
+			continue
+		}
+		inRange := today >= h.From && today <= h.To
+		if h.From > h.To {
+			inRange = today >= h.From || today <= h.To
+		}
+		if inRange {
+			return &holidays[i]
+		}
+	}
+	return nil
+}
+
+func (h *Holiday) decorator() asciiart.Decorator {
+	d := asciiart.Decorator{Top: h.Top, Bottom: h.Bottom, Left: h.Left, Right: h.Right}
+	if d.Bottom == "" {
+		d.Bottom = d.Top
+	}
+	if d.Right == "" {
+		d.Right = d.Left
+	}
+	corner := h.Corner
+	if corner == "" {
+		corner = d.Top
+	}
+	d.Corners = [4]string{corner, corner, corner, corner}
+	return d
+}
+
+// seasonalStyle swaps in today's holiday border and color scheme, if any
+func (config *AppConfig) seasonalStyle(style asciiart.Style, colorScheme *asciiart.ColorScheme) (asciiart.Style, *asciiart.ColorScheme) {
+	h := activeHoliday(loadHolidays(), time.Now())
+	if h == nil {
+		return style, colorScheme
+	}
+
+	decorator := h.decorator()
+	decorator.Pre, decorator.Post = style.Decorator.Pre, style.Decorator.Post
+	style.Decorator = decorator
+
+	if colorScheme != nil && h.ColorScheme != "" {
+		for i := range config.colors {
+			if strings.EqualFold(config.colors[i].Name, h.ColorScheme) {
+				colorScheme = &config.colors[i]
+			}
+		}
+	}
+	return style, colorScheme
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// selection is a flag taking a number or a name, such as -style 3 or
+// -style "Double Box". Names are resolved once the config is loaded, by
+// resolveSelection.
+type selection struct {
+	number *int
+	name   *string
+}
+
+func (s selection) String() string {
+	if s.name != nil && *s.name != "" {
+		return *s.name
+	}
+	if s.number != nil && *s.number != 0 {
+		return strconv.Itoa(*s.number)
+	}
+	return ""
+}
+
+func (s selection) Set(value string) error {
+	if n, err := strconv.Atoi(value); err == nil {
+		*s.number, *s.name = n, ""
+		return nil
+	}
+	*s.name = value
+	return nil
+}
+
+// selectionFlag defines a selection flag in flags and returns where its
+// number and name go
+func selectionFlag(flags *flag.FlagSet, name, usage string) (*int, *string) {
+	s := selection{new(int), new(string)}
+	flags.Var(s, name, usage)
+	return s.number, s.name
+}
+
+// resolveSelection turns -category, -style and -colorscheme names into
+// numbers. A style name is looked for in the -category first, then in
+// every category, and selects the category it is found in.
+func (config *AppConfig) resolveSelection(category, style, colorScheme *int, categoryName, styleName, colorSchemeName string) error {
+	if categoryName != "" {
+		var names []string
+		for _, c := range config.categories {
+			names = append(names, c.Name)
+		}
+		i, err := matchName("category", categoryName, names)
+		if err != nil {
+			return err
+		}
+		*category = i + 1
+	}
+	if styleName != "" {
+		ci, si, err := config.styleIndex(*category, styleName)
+		if err != nil {
+			return err
+		}
+		*category, *style = ci+1, si+1
+	}
+	if colorSchemeName != "" {
+		var names []string
+		for _, scheme := range config.colors {
+			names = append(names, scheme.Name)
+		}
+		i, err := matchName("color scheme", colorSchemeName, names)
+		if err != nil {
+			return err
+		}
+		*colorScheme = i + 1
+	}
+	return nil
+}
+
+// styleIndex finds a style by name, in category (counting from 1) if it
+// is set and has one, and in any category otherwise
+func (config *AppConfig) styleIndex(category int, name string) (int, int, error) {
+	if category >= 1 && category <= len(config.categories) {
+		for si, style := range config.categories[category-1].Styles {
+			if nameKey(style.Name) == nameKey(name) {
+				return category - 1, si, nil
+			}
+		}
+	}
+	var names []string
+	for _, c := range config.categories {
+		for _, style := range c.Styles {
+			names = append(names, style.Name)
+		}
+	}
+	i, err := matchName("style", name, names)
+	if err != nil {
+		return 0, 0, err
+	}
+	for ci, c := range config.categories {
+		if i < len(c.Styles) {
+			return ci, i, nil
+		}
+		i -= len(c.Styles)
+	}
+	return 0, 0, fmt.Errorf("unknown style %q", name)
+}
+
+// matchName returns the index of name in names, ignoring case, spaces,
+// dashes and underscores, so "double-box" finds "Double Box". Otherwise
+// the error suggests the closest name, if one is close.
+func matchName(kind, name string, names []string) (int, error) {
+	for i, candidate := range names {
+		if nameKey(candidate) == nameKey(name) {
+			return i, nil
+		}
+	}
+	if suggestion := closestName(name, names); suggestion != "" {
+		return 0, fmt.Errorf("unknown %s %q (did you mean %q?)", kind, name, suggestion)
+	}
+	return 0, fmt.Errorf("unknown %s %q (-list shows the styles and color schemes)", kind, name)
+}
+
+// nameKey is name as matchName compares it
+func nameKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// closestName returns the name in names that takes the fewest edits to
+// reach from name, or "" if none is close enough to be a typo
+func closestName(name string, names []string) string {
+	key := nameKey(name)
+	best, bestDistance := "", max(2, len([]rune(key))/3)+1
+	for _, candidate := range names {
+		if d := editDistance(key, nameKey(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b: the number
+// of characters inserted, deleted or replaced to turn one into the other
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := range ra {
+		current := make([]int, len(rb)+1)
+		current[0] = i + 1
+		for j := range rb {
+			cost := 1
+			if ra[i] == rb[j] {
+				cost = 0
+			}
+			current[j+1] = min(previous[j]+cost, previous[j+1]+1, current[j]+1)
+		}
+		previous = current
+	}
+	return previous[len(rb)]
+}
+
+// fuzzyScore rates how well query matches name for searches, ignoring
+// case, spaces, dashes and underscores: best when name contains it,
+// then when its characters come in order ("dblbox" finds "Double Box"),
+// more so in runs and at word starts, and last when a word of name is a
+// typo away. ok is false when it does not match at all.
+func fuzzyScore(query, name string) (score int, ok bool) {
+	q := []rune(nameKey(query))
+	if len(q) == 0 {
+		return 0, true
+	}
+	if i := strings.Index(nameKey(name), string(q)); i >= 0 {
+		return 1000 - i, true
+	}
+
+	matched, run, wordStart := 0, false, true
+	for _, r := range strings.ToLower(name) {
+		if r == ' ' || r == '-' || r == '_' {
+			run, wordStart = false, true
+			continue
+		}
+		if matched < len(q) && r == q[matched] {
+			matched++
+			score++
+			if run {
+				score += 2
+			}
+			if wordStart {
+				score += 3
+			}
+			run = true
+		} else {
+			run = false
+		}
+		wordStart = false
+	}
+	if matched == len(q) {
+		return 100 + score, true
+	}
+
+	if len(q) >= 4 {
+		for _, word := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if editDistance(string(q), word) <= max(1, len(q)/3) {
+				return 0, true
+			}
+		}
+	}
+	return 0, false
+}
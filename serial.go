@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	outputTargets["serial"] = writeSerial
+}
+
+const defaultBaud = 115200
+
+// Frame delimiters for -output serial:...?frame=
+const (
+	frameNone    = "none"    // Payloads back to back
+	frameNewline = "newline" // A line feed after each payload
+	frameNull    = "null"    // A NUL byte after each payload
+	frameSTX     = "stx"     // STX before and ETX after each payload
+	frameLength  = "length"  // A big-endian uint32 byte count before each payload
+)
+
+// framePayload wraps payload in the delimiter a microcontroller reading
+// the serial stream uses to find where each frame starts and ends
+func framePayload(frame string, payload []byte) ([]byte, error) {
+	switch frame {
+	case "", frameNone:
+		return payload, nil
+	case frameNewline:
+		return append(payload, '\n'), nil
+	case frameNull:
+		return append(payload, 0), nil
+	case frameSTX:
+		return append(append([]byte{0x02}, payload...), 0x03), nil
+	case frameLength:
+		return append(binary.BigEndian.AppendUint32(nil, uint32(len(payload))), payload...), nil
+	}
+	return nil, fmt.Errorf("unknown frame delimiter %q (use none, newline, null, stx or length)", frame)
+}
+
+// writeSerial sends the art as one frame to the serial device before the
+// query, e.g. serial:/dev/ttyUSB0?baud=115200&format=rgb565&size=64x32&frame=length.
+// The port is set to raw 8N1 at the baud rate; formats are those of the
+// mqtt:// target.
+func writeSerial(dest string, art RenderedArt) error {
+	device, rawQuery, _ := strings.Cut(dest, "?")
+	if device == "" {
+		return errors.New("missing device in serial output, as in serial:/dev/ttyUSB0?baud=115200")
+	}
+	q, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return err
+	}
+
+	baud := 0
+	if v := q.Get("baud"); v != "" {
+		if baud, err = strconv.Atoi(v); err != nil || baud <= 0 {
+			return fmt.Errorf("invalid baud rate %q", v)
+		}
+	}
+	opts := PublishOptions{format: q.Get("format")}
+	if opts.format == "" {
+		opts.format = "plain"
+	}
+	if isMatrixPixels(opts.format) {
+		if opts.matrix, err = parseMatrixFrame(q.Get("size"), opts.format); err != nil {
+			return err
+		}
+	}
+	payload, err := opts.payload(art)
+	if err != nil {
+		return err
+	}
+	data, err := framePayload(q.Get("frame"), payload)
+	if err != nil {
+		return err
+	}
+
+	port, err := os.OpenFile(device, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer port.Close()
+	if err := configureSerial(port, baud); err != nil {
+		return fmt.Errorf("configuring %s: %w", device, err)
+	}
+	if _, err := port.Write(data); err != nil {
+		return err
+	}
+	return port.Close()
+}
@@ -0,0 +1,40 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// configureSerial puts the port in raw 8N1 mode at baud, or the default
+// rate when baud is 0. The BSDs take any rate the driver supports. A port
+// that is not a terminal is written as it is.
+func configureSerial(port *os.File, baud int) error {
+	if baud == 0 {
+		baud = defaultBaud
+	}
+	fd := int(port.Fd())
+	t, err := unix.IoctlGetTermios(fd, unix.TIOCGETA)
+	if err == unix.ENOTTY {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB | unix.CSTOPB
+	t.Cflag |= unix.CS8 | unix.CLOCAL | unix.CREAD
+	setSpeed(&t.Ispeed, baud)
+	setSpeed(&t.Ospeed, baud)
+	t.Cc[unix.VMIN], t.Cc[unix.VTIME] = 1, 0
+	return unix.IoctlSetTermios(fd, unix.TIOCSETA, t)
+}
+
+// setSpeed stores baud in a termios speed field, whose type varies
+func setSpeed[T ~int32 | ~uint32 | ~uint64](field *T, baud int) {
+	*field = T(baud)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// serialSpeeds are the standard baud rates termios has a constant for
+var serialSpeeds = map[int]uint32{
+	1200: unix.B1200, 2400: unix.B2400, 4800: unix.B4800, 9600: unix.B9600,
+	19200: unix.B19200, 38400: unix.B38400, 57600: unix.B57600,
+	115200: unix.B115200, 230400: unix.B230400, 460800: unix.B460800,
+	500000: unix.B500000, 576000: unix.B576000, 921600: unix.B921600,
+	1000000: unix.B1000000, 2000000: unix.B2000000,
+}
+
+// configureSerial puts the port in raw 8N1 mode at baud, or the default
+// rate when baud is 0. A port that is not a terminal, such as a plain file
+// or a pipe to an emulator, is written as it is.
+func configureSerial(port *os.File, baud int) error {
+	if baud == 0 {
+		baud = defaultBaud
+	}
+	speed, ok := serialSpeeds[baud]
+	if !ok {
+		return fmt.Errorf("unsupported baud rate %d", baud)
+	}
+	fd := int(port.Fd())
+	t, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err == unix.ENOTTY {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	t.Oflag &^= unix.OPOST
+	t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	t.Cflag &^= unix.CSIZE | unix.PARENB | unix.CSTOPB | unix.CBAUD
+	t.Cflag |= unix.CS8 | unix.CLOCAL | unix.CREAD | speed
+	t.Ispeed, t.Ospeed = speed, speed
+	t.Cc[unix.VMIN], t.Cc[unix.VTIME] = 1, 0
+	return unix.IoctlSetTermios(fd, unix.TCSETS, t)
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// configureSerial leaves the port as the system set it up, as with mode
+// COM3 BAUD=115200 on Windows; only the default rate can be asked for
+func configureSerial(port *os.File, baud int) error {
+	if baud != 0 && baud != defaultBaud {
+		return fmt.Errorf("setting the baud rate is not supported on %s; configure the port first", runtime.GOOS)
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	serverHeaderTimeout   = 10 * time.Second
+	serverShutdownTimeout = 30 * time.Second
+)
+
+// formatTypes are the Content-Type of each render format
+var formatTypes = map[string]string{
+	"":     "text/plain; charset=utf-8",
+	"text": "text/plain; charset=utf-8",
+	"ansi": "text/plain; charset=utf-8",
+	"html": "text/html; charset=utf-8",
+	"svg":  "image/svg+xml",
+	"json": "application/json",
+}
+
+// runServe serves the render API over HTTP, with the playground page at /:
+//
+//	POST /render  {"text": "Hi", "style": "Big", "colorscheme": "Ocean", "format": "ansi"}
+//	GET  /render?text=Hi&style=Big&format=html
+//	GET  /styles
+//	GET  /preview?text=Hi&colorscheme=Ocean&format=html
+//	GET  /openapi.json
+//	GET  /usage  (with -api-keys or -basic-auth)
+//
+// Requests may define a whole style with a theme, in the body, the theme
+// query parameter or the X-Ascii-Art-Theme header.
+//
+// SIGHUP reloads themes and config files without dropping requests;
+// SIGTERM or Ctrl+C stops accepting new ones and waits for the rest.
+// Under systemd the server takes a socket-activated listener if given
+// one and reports readiness with sd_notify.
+func runServe(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	host := flags.String("host", "localhost", "Interface to listen on (empty for all)")
+	port := flags.Int("port", 8080, "Port to listen on")
+	filterMode := flags.String("filter", filterMask, "Word filter: mask, reject or off")
+	auditPath := flags.String("audit-log", "", "Record each render to this rotating log file")
+	auditSize := flags.Int("audit-max-size", auditMaxSizeMB, "Rotate the audit log once it reaches this many MB")
+	auditRedact := flags.Bool("audit-redact", false, "Keep only a hash of the text in the audit log")
+	shutdownTimeout := flags.Duration("shutdown-timeout", serverShutdownTimeout, "How long to let in-flight requests finish on SIGTERM")
+	apiKeys := flags.String("api-keys", "", "Require one of these comma-separated API keys, each key or name=key (default $"+apiKeysEnv+")")
+	basicAuth := flags.String("basic-auth", "", "Require this user:password with basic auth (default $"+basicAuthEnv+")")
+	rateLimit := flags.Int("rate-limit", 0, "Allow each client this many requests a minute (0 for no limit)")
+	trustProxy := flags.Bool("trust-proxy", false, "Take client addresses from X-Forwarded-For, for use behind a reverse proxy")
+	basePath := flags.String("base-path", "", "Serve everything under this path prefix, such as /ascii-art")
+	tlsCert := flags.String("tls-cert", "", "Serve HTTPS with this certificate file (needs -tls-key)")
+	tlsKey := flags.String("tls-key", "", "Private key file for -tls-cert")
+	locale := flags.String("locale", "", "Locale for template placeholders, such as de_DE (default: $LC_ALL, $LC_TIME or $LANG)")
+	cacheSize := flags.Int("cache-size", renderCacheSize, "Keep this many renders for repeated requests (0 for none)")
+	printUnit := flags.Bool("print-systemd-unit", false, "Print a systemd service unit for these flags and exit")
+	flags.Parse(args)
+	if *printUnit {
+		unit, err := systemdUnit(flags, *shutdownTimeout)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(unit)
+		return
+	}
+	config.setFilter(*filterMode)
+	config.setAudit(*auditPath, *auditSize, *auditRedact)
+	if *locale != "" {
+		config.setLocale(*locale)
+	}
+	config.renders = newRenderCache(*cacheSize)
+	auth, err := newServerAuth(*apiKeys, *basicAuth)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		fmt.Println("Error: -tls-cert and -tls-key must be given together")
+		os.Exit(1)
+	}
+
+	var current atomic.Pointer[AppConfig]
+	current.Store(config)
+	// Client addresses are settled before the rate limit sees them, and
+	// every request is rate limited before its credentials are checked
+	handler := serverMux(&current, auth)
+	handler = newRateLimiter(*rateLimit).wrap(handler)
+	handler = withForwardedFor(*trustProxy, handler)
+	handler = withBasePath(*basePath, handler)
+
+	server := &http.Server{
+		Addr:              net.JoinHostPort(*host, strconv.Itoa(*port)),
+		Handler:           handler,
+		ReadHeaderTimeout: serverHeaderTimeout,
+	}
+	listener, activated, err := systemdListener()
+	if err == nil && !activated {
+		listener, err = net.Listen("tcp", server.Addr)
+	}
+	if err != nil {
+		fmt.Printf("Error serving: %v\n", err)
+		os.Exit(1)
+	}
+	served := make(chan error, 1)
+	scheme := "http"
+	if *tlsCert != "" {
+		scheme = "https"
+		go func() { served <- server.ServeTLS(listener, *tlsCert, *tlsKey) }()
+	} else {
+		go func() { served <- server.Serve(listener) }()
+	}
+	fmt.Printf("Serving ASCII art on %s://%s%s\n", scheme, listener.Addr(), strings.TrimSuffix("/"+strings.Trim(*basePath, "/"), "/")+"/")
+	notify("READY=1")
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGTERM, os.Interrupt)
+	for {
+		select {
+		case err := <-served:
+			if !errors.Is(err, http.ErrServerClosed) {
+				fmt.Printf("Error serving: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				notify("RELOADING=1")
+				reloaded, err := reloadConfig(current.Load(), *filterMode, *cacheSize)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, color.YellowString("Warning: reload failed, keeping the old config: %v", err))
+				} else {
+					current.Store(reloaded)
+					fmt.Println("Reloaded themes, fonts and config")
+				}
+				notify("READY=1")
+				continue
+			}
+
+			notify("STOPPING=1")
+			fmt.Printf("Shutting down, waiting up to %s for requests to finish\n", *shutdownTimeout)
+			ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+			err := server.Shutdown(ctx)
+			cancel()
+			if err != nil {
+				fmt.Printf("Error shutting down: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+}
+
+// serverMux routes the API and the playground, behind auth if it is
+// set. Requests use whichever config was current when they arrived, so
+// a reload never changes one half way through.
+func serverMux(current *atomic.Pointer[AppConfig], auth *ServerAuth) http.Handler {
+	handle := func(h func(*AppConfig, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) { h(current.Load(), w, r) }
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/render", handle((*AppConfig).handleRender))
+	mux.HandleFunc("/styles", handle((*AppConfig).handleStyles))
+	mux.HandleFunc("/preview", handle((*AppConfig).handlePreview))
+	mux.HandleFunc("/openapi.json", handleOpenAPI)
+	mux.Handle("/", playgroundHandler())
+	if auth != nil {
+		mux.HandleFunc("/usage", auth.handleUsage)
+	}
+	return auth.wrap(mux)
+}
+
+// notify sends state to systemd, warning if that fails
+func notify(state string) {
+	if err := sdNotify(state); err != nil {
+		fmt.Fprintln(os.Stderr, color.YellowString("Warning: %v", err))
+	}
+}
+
+// reloadConfig reads the themes and config files again for SIGHUP. The
+// audit log and locale carry over; the render cache starts empty, since
+// styles may have changed. Fonts are read from disk on every render
+// that is not cached, so new font files need no reload.
+func reloadConfig(old *AppConfig, filterMode string, cacheSize int) (*AppConfig, error) {
+	config, _, err := loadAppConfig()
+	if err != nil && !isNotExist(err) {
+		return nil, err
+	}
+	if config.filter, err = newWordFilter(filterMode, config.filterConfig); err != nil {
+		return nil, err
+	}
+	config.audit, config.locale = old.audit, old.locale
+	config.renders = newRenderCache(cacheSize)
+	return config, nil
+}
+
+// handleRender renders one request and returns the art in the requested
+// format; format "json" returns every variant as a JSON object
+func (config *AppConfig) handleRender(w http.ResponseWriter, r *http.Request) {
+	var request RenderRequest
+	themeData := r.Header.Get(themeHeader)
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		braille, _ := strconv.ParseBool(query.Get("braille"))
+		template, _ := strconv.ParseBool(query.Get("template"))
+		request = RenderRequest{
+			Text:        query.Get("text"),
+			Style:       query.Get("style"),
+			Font:        query.Get("font"),
+			ColorScheme: query.Get("colorscheme"),
+			Format:      query.Get("format"),
+			Braille:     braille,
+			Template:    template,
+		}
+		if query.Has("theme") {
+			themeData = query.Get("theme")
+		}
+	case http.MethodPost:
+		body := http.MaxBytesReader(w, r.Body, maxRequestSize)
+		if err := json.NewDecoder(body).Decode(&request); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if themeData != "" && request.Theme == nil {
+		theme, err := parseTheme(themeData)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		request.Theme = theme
+	}
+	if request.Text == "" {
+		http.Error(w, "text is required", http.StatusBadRequest)
+		return
+	}
+	rendered := config.renders.render(config, request)
+	config.audit.record(r.RemoteAddr, request, rendered.Response)
+	if rendered.Response.Error != "" {
+		http.Error(w, rendered.Response.Error, http.StatusBadRequest)
+		return
+	}
+
+	// Clients may keep the art but must check it is still current, which
+	// costs them only a 304 while it is
+	w.Header().Set("ETag", rendered.ETag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Method == http.MethodGet && etagMatches(r.Header.Get("If-None-Match"), rendered.ETag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", formatTypes[request.Format])
+	w.Write(rendered.Body)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+// -signature-position values
+const (
+	signatureBelow  = "below"
+	signatureInside = "inside"
+)
+
+const defaultSignatureColor = "gray"
+
+// Signature is a small credit line set flush right under the art, from
+// -signature, drawn in its own color
+type Signature struct {
+	Text     string
+	Position string // below the border, or inside it under the text
+	Color    *color.Color
+}
+
+func newSignature(text, position, colorName string) (*Signature, error) {
+	switch position {
+	case signatureBelow, signatureInside:
+	default:
+		return nil, fmt.Errorf("unknown signature position %q (use below or inside)", position)
+	}
+	if strings.ContainsAny(text, "\n\r") {
+		return nil, fmt.Errorf("the signature must be a single line")
+	}
+	c, err := parseColorName(colorName)
+	if err != nil {
+		return nil, err
+	}
+	return &Signature{Text: text, Position: position, Color: c}, nil
+}
+
+// withSignature returns style with the signature added last: before the
+// border for inside, after the effects and any clipart for below
+func withSignature(style asciiart.Style, sig *Signature) asciiart.Style {
+	if sig.Position == signatureInside {
+		previous := style.Decorator.Pre
+		style.Decorator.Pre = func(art string) string {
+			if previous != nil {
+				art = previous(art)
+			}
+			return sig.append(art)
+		}
+		return style
+	}
+	previous := style.Decorator.Post
+	style.Decorator.Post = func(art string) string {
+		if previous != nil {
+			art = previous(art)
+		}
+		return sig.append(art)
+	}
+	return style
+}
+
+// append adds the signature as a last line, aligned with the right edge
+// of art
+func (sig *Signature) append(art string) string {
+	width := blockWidth(strings.Split(art, "\n"))
+	padding := strings.Repeat(" ", max(0, width-asciiart.DisplayWidth(sig.Text)))
+	return art + "\n" + padding + sig.Text
+}
+
+// colorizer colors the signature in art with its own color and the rest
+// with c. forced keeps the color even when stdout is not a terminal.
+func (sig *Signature) colorizer(art string, c asciiart.Colorizer, forced bool) asciiart.Colorizer {
+	lines := strings.Split(art, "\n")
+	for row := len(lines) - 1; row >= 0; row-- {
+		i := strings.LastIndex(lines[row], sig.Text)
+		if i < 0 {
+			continue
+		}
+		signature := sig.Color
+		if forced {
+			clone := *sig.Color
+			clone.EnableColor()
+			signature = &clone
+		}
+		from := asciiart.DisplayWidth(lines[row][:i])
+		to := from + asciiart.DisplayWidth(sig.Text)
+		return asciiart.ColorizerFunc(func(cell asciiart.Cell) *color.Color {
+			if cell.Row == row && cell.Col >= from && cell.Col < to {
+				return signature
+			}
+			if c == nil {
+				return nil
+			}
+			return c.ColorAt(cell)
+		})
+	}
+	// An effect changed the text beyond recognition
+	return c
+}
@@ -0,0 +1,42 @@
+//go:build !noaudio
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// audioEnabled reports whether this build can play sound files
+const audioEnabled = true
+
+// playSound plays a sound file through the first available system player
+// and waits for it to finish.
+func playSound(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"afplay", path}}
+	case "windows":
+		script := fmt.Sprintf("(New-Object Media.SoundPlayer '%s').PlaySync()", strings.ReplaceAll(path, "'", "''"))
+		candidates = [][]string{{"powershell", "-NoProfile", "-NonInteractive", "-Command", script}}
+	default:
+		candidates = [][]string{{"paplay", path}, {"aplay", "-q", path}, {"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet", path}}
+	}
+
+	for _, args := range candidates {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		return exec.Command(args[0], args[1:]...).Run()
+	}
+	return errors.New("no audio player found")
+}
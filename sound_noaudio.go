@@ -0,0 +1,11 @@
+//go:build noaudio
+
+package main
+
+import "errors"
+
+const audioEnabled = false
+
+func playSound(path string) error {
+	return errors.New("built without audio support")
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+const (
+	statsFileName = "stats.json"
+	statsBarWidth = 30
+	statsTopN     = 5
+)
+
+// UsageStats are local render counters. They never leave the machine.
+type UsageStats struct {
+	Renders      int            `json:"renders"`
+	Styles       map[string]int `json:"styles"`
+	Fonts        map[string]int `json:"fonts"`
+	Schemes      map[string]int `json:"schemes"`
+	Combinations map[string]int `json:"combinations"`
+	Since        time.Time      `json:"since"`
+}
+
+// configDir returns the per-user directory for ascii-art files
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "asciiart"), nil
+}
+
+func statsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, statsFileName), nil
+}
+
+func loadStats() (*UsageStats, error) {
+	stats := &UsageStats{Since: time.Now()}
+	path, err := statsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return stats, nil
+}
+
+func (stats *UsageStats) save() error {
+	path, err := statsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// recordRender bumps the counters for one render. Set ASCIIART_NO_STATS
+// to turn tracking off.
+func recordRender(category asciiart.StyleCategory, style asciiart.Style, colorScheme *asciiart.ColorScheme) {
+	if os.Getenv("ASCIIART_NO_STATS") != "" {
+		return
+	}
+
+	stats, err := loadStats()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: usage stats not updated: %v\n", err)
+		return
+	}
+
+	font := style.Font
+	if font == "" {
+		font = "(plain)"
+	}
+	scheme := "(none)"
+	if colorScheme != nil {
+		scheme = colorScheme.Name
+	}
+	styleName := category.Name + " / " + style.Name
+
+	stats.Renders++
+	bump(&stats.Styles, styleName)
+	bump(&stats.Fonts, font)
+	bump(&stats.Schemes, scheme)
+	bump(&stats.Combinations, styleName+" + "+scheme)
+
+	if err := stats.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: usage stats not updated: %v\n", err)
+	}
+}
+
+func bump(counts *map[string]int, key string) {
+	if *counts == nil {
+		*counts = make(map[string]int)
+	}
+	(*counts)[key]++
+}
+
+// runStats renders bar charts of the local usage counters
+func runStats(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	reset := flags.Bool("reset", false, "Delete the collected stats")
+	flags.Parse(args)
+
+	if *reset {
+		path, err := statsPath()
+		if err == nil {
+			err = os.Remove(path)
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			fmt.Printf("Error resetting stats: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Usage stats cleared.")
+		return
+	}
+
+	stats, err := loadStats()
+	if err != nil {
+		fmt.Printf("Error reading stats: %v\n", err)
+		os.Exit(1)
+	}
+	if stats.Renders == 0 {
+		fmt.Println("No renders recorded yet.")
+		return
+	}
+
+	fmt.Println(color.CyanString("\n%d renders since %s", stats.Renders, stats.Since.Format("2006-01-02")))
+	printBarChart("Styles", stats.Styles)
+	printBarChart("Fonts", stats.Fonts)
+	printBarChart("Color schemes", stats.Schemes)
+	printBarChart("Most used combinations", stats.Combinations)
+}
+
+func printBarChart(title string, counts map[string]int) {
+	type entry struct {
+		name  string
+		count int
+	}
+	var entries []entry
+	labelWidth := 0
+	for name, count := range counts {
+		entries = append(entries, entry{name, count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].count != entries[j].count {
+			return entries[i].count > entries[j].count
+		}
+		return entries[i].name < entries[j].name
+	})
+	if len(entries) > statsTopN {
+		entries = entries[:statsTopN]
+	}
+	for _, e := range entries {
+		labelWidth = max(labelWidth, len([]rune(e.name)))
+	}
+
+	fmt.Printf("\n%s\n", color.BlueString(title))
+	for _, e := range entries {
+		bar := max(1, e.count*statsBarWidth/entries[0].count)
+		padding := strings.Repeat(" ", labelWidth-len([]rune(e.name)))
+		fmt.Printf("  %s%s %s %d\n", e.name, padding, color.GreenString(strings.Repeat("█", bar)), e.count)
+	}
+}
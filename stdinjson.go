@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"ascii-art/asciiart"
+)
+
+// maxRequestSize bounds one line of -stdin-json input
+const maxRequestSize = 1 << 20
+
+// RenderRequest is one line of -stdin-json input
+type RenderRequest struct {
+	ID          json.RawMessage `json:"id,omitempty"`
+	Text        string          `json:"text"`
+	Style       string          `json:"style,omitempty"`
+	Font        string          `json:"font,omitempty"`
+	ColorScheme string          `json:"colorscheme,omitempty"`
+	Format      string          `json:"format,omitempty"`
+	Theme       *RequestTheme   `json:"theme,omitempty"`
+	Braille     bool            `json:"braille,omitempty"`
+	Template    bool            `json:"template,omitempty"` // Fill in the text's safe placeholders
+}
+
+// RenderResponse answers one RenderRequest. Output holds the text, ansi,
+// html or svg rendering; format "json" fills Art with every variant instead.
+type RenderResponse struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Output string          `json:"output,omitempty"`
+	Art    *RenderedArt    `json:"art,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// runStdinJSON answers render requests read one JSON object per line
+// from stdin with one JSON response per line on stdout, until EOF.
+func runStdinJSON(config *AppConfig) {
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRequestSize)
+	encoder := json.NewEncoder(os.Stdout)
+
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var request RenderRequest
+		var response RenderResponse
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			response.Error = fmt.Sprintf("invalid request: %v", err)
+		} else {
+			response = config.answer(request)
+			config.audit.record("stdin-json", request, response)
+		}
+		if err := encoder.Encode(response); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing response: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading requests: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func (config *AppConfig) answer(request RenderRequest) RenderResponse {
+	response := RenderResponse{ID: request.ID}
+	fail := func(err error) RenderResponse {
+		response.Error = err.Error()
+		return response
+	}
+
+	text := request.Text
+	if request.Template {
+		expanded, err := config.expandRequestTemplate(text)
+		if err != nil {
+			return fail(err)
+		}
+		text = expanded
+	}
+	if err := checkRenderText(text); err != nil {
+		return fail(err)
+	}
+	text, err := config.filter.apply(text)
+	if err != nil {
+		return fail(err)
+	}
+
+	var style asciiart.Style
+	if request.Style != "" {
+		_, found, err := config.findStyle(request.Style)
+		if err != nil {
+			return fail(err)
+		}
+		style = found
+	}
+	if request.Font != "" {
+		if !renderer.FontExists(request.Font) {
+			return fail(fmt.Errorf("unknown font %q", request.Font))
+		}
+		style.Font = request.Font
+	}
+	if request.Braille {
+		style = withBraille(style)
+	}
+	var gradient *asciiart.Gradient
+	if request.Theme != nil {
+		if style, gradient, err = request.Theme.apply(style); err != nil {
+			return fail(err)
+		}
+	}
+	colorScheme, err := config.findColorScheme(request.ColorScheme)
+	if err != nil {
+		return fail(err)
+	}
+
+	if err := checkRenderSize(config.generateArt(text, style, nil)); err != nil {
+		return fail(err)
+	}
+	// The size check reported any missing glyphs already
+	quiet := config.withWarnings(nil)
+	var art RenderedArt
+	if gradient != nil {
+		art = quiet.gradientVariants(text, style, gradient)
+	} else {
+		art = quiet.renderVariants(text, style, colorScheme)
+	}
+	switch request.Format {
+	case "", "text":
+		response.Output = art.Plain
+	case "ansi":
+		response.Output = art.ANSI
+	case "html":
+		response.Output = art.HTML
+	case "svg":
+		response.Output = art.SVG
+	case "json":
+		response.Art = &art
+	default:
+		return fail(fmt.Errorf("unknown format %q", request.Format))
+	}
+	return response
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// Terminal control sequences used by live modes
+const (
+	clearScreen    = "\033[H\033[2J"
+	hideCursor     = "\033[?25l"
+	showCursor     = "\033[?25h"
+	enterAltScreen = "\033[?1049h"
+	leaveAltScreen = "\033[?1049l"
+	beginSync      = "\033[?2026h" // DEC 2026 synchronized update start
+	endSync        = "\033[?2026l" // and end, when the terminal shows it
+)
+
+const stopwatchTick = 100 * time.Millisecond
+
+// stopwatchLapRows is how many of the latest laps the stopwatch shows;
+// all of them are printed when it stops
+const stopwatchLapRows = 20
+
+// runStopwatch displays a running elapsed timer in a large font. Space
+// records a lap and q quits; lap times are printed as plain text on exit.
+func runStopwatch(config *AppConfig, args []string) {
+	fs := flag.NewFlagSet("stopwatch", flag.ExitOnError)
+	font := fs.String("font", "big", "Figure font for the timer")
+	colorFlag := fs.Int("colorscheme", 1, "Color scheme number (0 for none)")
+	cue := addCueFlags(fs, "when stopped")
+	fps, cpuLimit := addFrameFlags(fs)
+	fs.Parse(args)
+	limiter := mustFrameLimiter(*fps, *cpuLimit)
+
+	var scheme *asciiart.ColorScheme
+	if *colorFlag > 0 && *colorFlag <= len(config.colors) {
+		scheme = &config.colors[*colorFlag-1]
+	}
+
+	keys := make(chan byte)
+	var state *term.State
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		var err error
+		state, err = term.MakeRaw(int(os.Stdin.Fd()))
+		if err != nil {
+			fmt.Printf("Error preparing terminal: %v\n", err)
+			os.Exit(1)
+		}
+		go readKeys(keys)
+	}
+	restore := func() {
+		if state != nil {
+			term.Restore(int(os.Stdin.Fd()), state)
+			state = nil
+		}
+	}
+	defer restore()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	start := time.Now()
+	var laps []time.Duration
+	scr := newScreen(os.Stdout)
+	scr.open()
+	resized, stopResize := watchResize()
+	defer stopResize()
+
+	draw := func() {
+		elapsed := time.Since(start)
+		art := strings.TrimRight(renderer.Figure(formatElapsed(elapsed), *font), "\n")
+		if scheme != nil {
+			art = asciiart.ApplyColorScheme(art, scheme)
+		}
+
+		var b strings.Builder
+		b.WriteString(art + "\n\n")
+		// Each frame shows the latest laps only, so it stays the same
+		// size however many are taken
+		first := max(0, len(laps)-stopwatchLapRows)
+		for i, lap := range laps[first:] {
+			fmt.Fprintf(&b, "Lap %d: %s\n", first+i+1, formatElapsed(lap))
+		}
+		b.WriteString(color.HiBlackString("\nspace: lap   q: quit"))
+		// Cursor moves place each line, so raw mode needs no \r
+		scr.draw(b.String())
+	}
+
+loop:
+	for {
+		limiter.begin()
+		draw()
+		select {
+		case <-time.After(limiter.next(stopwatchTick)):
+		case <-resized:
+			// The terminal may have reflowed the old frame
+			scr.invalidate()
+		case <-interrupt:
+			break loop
+		case key, ok := <-keys:
+			if !ok {
+				keys = nil
+				continue
+			}
+			switch key {
+			case ' ':
+				laps = append(laps, time.Since(start))
+			case 'q', 'Q', 3: // 3 is Ctrl+C in raw mode
+				break loop
+			}
+		}
+	}
+
+	total := time.Since(start)
+	restore()
+	scr.close()
+	printLaps(laps, total)
+	cue.play()
+}
+
+// readKeys forwards single key presses from stdin
+func readKeys(keys chan<- byte) {
+	buf := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		if n > 0 {
+			keys <- buf[0]
+		}
+	}
+}
+
+func printLaps(laps []time.Duration, total time.Duration) {
+	var previous time.Duration
+	for i, lap := range laps {
+		fmt.Printf("Lap %d: %s (+%s)\n", i+1, formatElapsed(lap), formatElapsed(lap-previous))
+		previous = lap
+	}
+	fmt.Printf("Total: %s\n", formatElapsed(total))
+}
+
+// formatElapsed formats d as mm:ss.t, adding hours once they are needed
+func formatElapsed(d time.Duration) string {
+	tenths := int(d / (100 * time.Millisecond))
+	h, m, s := tenths/36000, tenths/600%60, tenths/10%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d.%d", h, m, s, tenths%10)
+	}
+	return fmt.Sprintf("%02d:%02d.%d", m, s, tenths%10)
+}
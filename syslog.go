@@ -0,0 +1,52 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+)
+
+func init() {
+	outputTargets["syslog"] = writeSyslog
+}
+
+var syslogFacilities = map[string]syslog.Priority{
+	"kern": syslog.LOG_KERN, "user": syslog.LOG_USER, "mail": syslog.LOG_MAIL,
+	"daemon": syslog.LOG_DAEMON, "auth": syslog.LOG_AUTH, "syslog": syslog.LOG_SYSLOG,
+	"lpr": syslog.LOG_LPR, "news": syslog.LOG_NEWS, "uucp": syslog.LOG_UUCP,
+	"cron": syslog.LOG_CRON, "authpriv": syslog.LOG_AUTHPRIV, "ftp": syslog.LOG_FTP,
+	"local0": syslog.LOG_LOCAL0, "local1": syslog.LOG_LOCAL1, "local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3, "local4": syslog.LOG_LOCAL4, "local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6, "local7": syslog.LOG_LOCAL7,
+}
+
+// writeSyslog sends each line of the plain art to the local syslog
+// daemon. dest is "facility/tag", both optional: -output syslog:local0/deploy
+func writeSyslog(dest string, art RenderedArt) error {
+	facility, tag, _ := strings.Cut(dest, "/")
+	if facility == "" {
+		facility = "user"
+	}
+	if tag == "" {
+		tag = "ascii-art"
+	}
+	priority, ok := syslogFacilities[strings.ToLower(facility)]
+	if !ok {
+		return fmt.Errorf("unknown syslog facility %q", facility)
+	}
+
+	writer, err := syslog.New(priority|syslog.LOG_INFO, tag)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	for _, line := range strings.Split(art.Plain, "\n") {
+		if err := writer.Info(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
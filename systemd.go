@@ -0,0 +1,145 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// systemdListenFD is the first file descriptor systemd passes to a
+// socket-activated service
+const systemdListenFD = 3
+
+// systemdListener returns the socket systemd opened for this process,
+// if it was started by a .socket unit (LISTEN_PID and LISTEN_FDS)
+func systemdListener() (net.Listener, bool, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, false, nil
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return nil, false, nil
+	}
+	// Child processes must not think the sockets are theirs
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	file := os.NewFile(systemdListenFD, "systemd-socket")
+	defer file.Close()
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, false, fmt.Errorf("socket activation: %v", err)
+	}
+	return listener, true, nil
+}
+
+// sdNotify tells the service manager about a state change, such as
+// READY=1 once requests are accepted. Outside systemd it does nothing.
+func sdNotify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	if strings.HasPrefix(socket, "@") {
+		// Abstract namespace socket
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("sd_notify: %v", err)
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// systemdEnvironmentFile holds the credentials of the service unit
+const systemdEnvironmentFile = "/etc/ascii-art/serve.env"
+
+// secretFlags are the serve flags holding credentials, with the
+// environment variables that can hold them instead
+var secretFlags = map[string]string{"api-keys": apiKeysEnv, "basic-auth": basicAuthEnv}
+
+// systemdUnit returns a service unit running serve with the flags set on
+// this command line. Type=notify lets systemd wait until the server is
+// ready, and a stop waits out the shutdown timeout.
+func systemdUnit(flags *flag.FlagSet, shutdownTimeout time.Duration) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	command := []string{systemdQuote(exe), "serve"}
+	var secrets []string
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "print-systemd-unit" {
+			return
+		}
+		// Unit files are world-readable, so credentials go in an
+		// environment file instead
+		if env, ok := secretFlags[f.Name]; ok {
+			secrets = append(secrets, env)
+			return
+		}
+		command = append(command, systemdQuote("-"+f.Name+"="+f.Value.String()))
+	})
+
+	environment := ""
+	if len(secrets) > 0 {
+		environment = fmt.Sprintf("# Set %s in %s, readable only by root\nEnvironmentFile=%s\n",
+			strings.Join(secrets, " and "), systemdEnvironmentFile, systemdEnvironmentFile)
+	}
+
+	return fmt.Sprintf(`[Unit]
+Description=ASCII art render server
+After=network.target
+
+[Service]
+Type=notify
+%sExecStart=%s
+ExecReload=/bin/kill -HUP $MAINPID
+TimeoutStopSec=%d
+Restart=on-failure
+NoNewPrivileges=yes
+
+[Install]
+WantedBy=multi-user.target
+`, environment, strings.Join(command, " "), int((shutdownTimeout + 5*time.Second).Seconds())), nil
+}
+
+// systemdQuote writes arg as one word of a unit file command line.
+// systemd expands % specifiers and $ variables even inside quotes, so
+// those are doubled, and words with spaces, quotes, backslashes or
+// control characters are double-quoted with C escapes.
+func systemdQuote(arg string) string {
+	arg = strings.NewReplacer("%", "%%", "$", "$$").Replace(arg)
+	if arg != "" && arg != ";" && !strings.ContainsFunc(arg, func(r rune) bool {
+		return r <= ' ' || r == '"' || r == '\'' || r == '\\' || r == 0x7f
+	}) {
+		return arg
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range arg {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < ' ' || r == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestSystemdQuote(t *testing.T) {
+	tests := []struct {
+		arg, want string
+	}{
+		{"/usr/local/bin/ascii-art", "/usr/local/bin/ascii-art"},
+		{"/opt/ASCII Art/ascii-art", `"/opt/ASCII Art/ascii-art"`},
+		{`-base-path=/a"b\c`, `"-base-path=/a\"b\\c"`},
+		{"-tls-key=/etc/ssl/%i.key", "-tls-key=/etc/ssl/%%i.key"},
+		{"-locale=$LANG", "-locale=$$LANG"},
+		{"it's", `"it's"`},
+		{"tab\there", `"tab\there"`},
+		{"", `""`},
+		{";", `";"`},
+	}
+	for _, test := range tests {
+		if got := systemdQuote(test.arg); got != test.want {
+			t.Errorf("systemdQuote(%q) = %s, want %s", test.arg, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+// TargetProfile describes the limits of a known output destination
+type TargetProfile struct {
+	name        string
+	description string
+	maxWidth    int  // Columns, 0 for unlimited
+	asciiOnly   bool // Destination cannot show Unicode box drawing
+	colorDepth  int  // One of the colorDepth constants
+}
+
+var targetProfiles = map[string]TargetProfile{
+	"chat":     {"chat", "Slack/Discord/Teams code blocks", 60, false, colorDepthNone},
+	"ci":       {"ci", "CI job logs", 120, false, colorDepth16},
+	"motd":     {"motd", "Login message of the day", 80, false, colorDepth16},
+	"printer":  {"printer", "Line printers and plain text files", 80, true, colorDepthNone},
+	"teletext": {"teletext", "Teletext and Minitel pages (with -teletext)", asciiart.TeletextWidth, false, colorDepth16},
+}
+
+func findTargetProfile(name string) (TargetProfile, error) {
+	profile, ok := targetProfiles[strings.ToLower(name)]
+	if !ok {
+		return profile, fmt.Errorf("unknown target %q (choose from %s)", name, strings.Join(targetNames(), ", "))
+	}
+	return profile, nil
+}
+
+func targetNames() []string {
+	var names []string
+	for name := range targetProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// constrainColors drops the color scheme when the target cannot show it
+func (p TargetProfile) constrainColors(colorScheme *asciiart.ColorScheme) *asciiart.ColorScheme {
+	if p.colorDepth == colorDepthNone {
+		return nil
+	}
+	return colorScheme
+}
+
+// limitDepth caps depth, the colors the terminal can show, at the colors
+// the target can show, so custom colors and gradients are reduced to
+// its 256 or 16 colors
+func (p TargetProfile) limitDepth(depth int) int {
+	return min(depth, p.colorDepth)
+}
+
+// violations checks plain (uncolored) art against the profile
+func (p TargetProfile) violations(plain string) []string {
+	var problems []string
+
+	width := 0
+	for _, line := range strings.Split(plain, "\n") {
+		width = max(width, asciiart.DisplayWidth(line))
+	}
+	if p.maxWidth > 0 && width > p.maxWidth {
+		problems = append(problems, fmt.Sprintf("art is %d columns wide, %s allows %d", width, p.name, p.maxWidth))
+	}
+
+	if p.asciiOnly {
+		seen := make(map[rune]bool)
+		var offending []string
+		for _, r := range plain {
+			if r > utf8.RuneSelf-1 && !seen[r] {
+				seen[r] = true
+				offending = append(offending, string(r))
+			}
+		}
+		if len(offending) > 0 {
+			problems = append(problems, fmt.Sprintf("%s is ASCII only but the art uses %s", p.name, strings.Join(offending, " ")))
+		}
+	}
+	return problems
+}
+
+func (p TargetProfile) warn(plain string) {
+	for _, problem := range p.violations(plain) {
+		fmt.Fprintln(os.Stderr, color.YellowString("Warning: %s", problem))
+	}
+}
+
+// printTargetMatrix lists every profile and its limits
+func printTargetMatrix() {
+	fmt.Println(color.CyanString("\nOutput targets:"))
+	fmt.Printf("  %-8s %-6s %-8s %-11s %s\n", "Target", "Width", "Charset", "Colors", "Use")
+	for _, name := range targetNames() {
+		p := targetProfiles[name]
+		width := "any"
+		if p.maxWidth > 0 {
+			width = fmt.Sprint(p.maxWidth)
+		}
+		charset := "unicode"
+		if p.asciiOnly {
+			charset = "ascii"
+		}
+		fmt.Printf("  %-8s %-6s %-8s %-11s %s\n", p.name, width, charset, colorDepthName(p.colorDepth), p.description)
+	}
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ascii-art/asciiart"
+)
+
+// maxTemplateWidth caps what {repeat} and {pad} make, so a short
+// placeholder cannot turn into an enormous banner
+const maxTemplateWidth = 1000
+
+const templateExecTimeout = 5 * time.Second
+
+// maxNumberDecimals caps the decimals {number} may ask for
+const maxNumberDecimals = 20
+
+// templateFunc is a placeholder function. Its arguments follow the name
+// after colons, the last one taking any colons left, as in {time:15:04};
+// a value piped in with | comes as the last argument, so {user|upper}
+// is {upper:alice}.
+type templateFunc struct {
+	min, max int
+	usage    string // How to write it, for errors
+	safe     bool   // Available to server and co-process requests
+	call     func(env templateEnv, args []string) (string, error)
+}
+
+// templateEnv is what placeholders may use besides their arguments
+type templateEnv struct {
+	exec   []string // Commands {exec:...} may run, from the config file
+	safe   bool     // Only the functions safe for requests
+	locale Locale   // For names of days and months and for numbers; English if unset
+}
+
+// templateFuncs fill in the {name} and {name:argument} placeholders of
+// input text, so banners in scripts can show dynamic values
+var templateFuncs = map[string]templateFunc{
+	"date":   {0, 1, "{date}, {date:Jan 2}, {date:%A %e %B} or {date:+7d:Jan 2}", true, timeFunc("2006-01-02")},
+	"time":   {0, 1, "{time}, {time:3:04PM}, {time:%X} or {time:-90m}", true, timeFunc("15:04")},
+	"number": {1, 2, "{number:1234.5} or {number:DECIMALS:1234.5}", true, numberFunc},
+	"hostname": {0, 0, "{hostname}", false, func(templateEnv, []string) (string, error) {
+		return os.Hostname()
+	}},
+	"user": {0, 0, "{user}", false, currentUser},
+	"env": {1, 2, "{env:HOME} or {env:BRANCH:main}", false, func(_ templateEnv, args []string) (string, error) {
+		if value := os.Getenv(args[0]); value != "" || len(args) == 1 {
+			return value, nil
+		}
+		return args[1], nil
+	}},
+	"upper": {1, 1, "{upper:TEXT}", true, func(_ templateEnv, args []string) (string, error) {
+		return strings.ToUpper(args[0]), nil
+	}},
+	"lower": {1, 1, "{lower:TEXT}", true, func(_ templateEnv, args []string) (string, error) {
+		return strings.ToLower(args[0]), nil
+	}},
+	"repeat": {2, 2, "{repeat:COUNT:TEXT}", true, repeatFunc},
+	"pad":    {2, 2, "{pad:WIDTH:TEXT}", true, padFunc},
+	"exec":   {1, 1, "{exec:COMMAND}", false, execFunc},
+}
+
+// templatePlaceholder matches {name}, {name:argument} and either piped
+// through more functions, as in {env:USER|upper}; doubled braces are
+// literal ones
+var templatePlaceholder = regexp.MustCompile(`\{\{|\}\}|\{([a-z]+)(?::([^{}|]*))?((?:\|[a-z]+(?::[^{}|]*)?)*)\}`)
+
+// timeOffset matches the offset {date} and {time} may start with, such
+// as +7d, -1w or +1h30m, and the layout after it
+var timeOffset = regexp.MustCompile(`^([+-])((?:\d+(?:mo|[ywdhms]))+)(?::(.*))?$`)
+
+var timeOffsetTerm = regexp.MustCompile(`(\d+)(mo|[ywdhms])`)
+
+var timeOffsetUnits = map[string]time.Duration{"h": time.Hour, "m": time.Minute, "s": time.Second}
+
+// timeFunc formats the current time, moved by an offset if one is given,
+// in the layout given in Go's reference time notation or with strftime
+// directives such as %A, or else in layout
+func timeFunc(layout string) func(templateEnv, []string) (string, error) {
+	return func(env templateEnv, args []string) (string, error) {
+		now := time.Now()
+		if len(args) == 0 {
+			return now.Format(layout), nil
+		}
+		spec := args[0]
+		if m := timeOffset.FindStringSubmatch(spec); m != nil {
+			sign := 1
+			if m[1] == "-" {
+				sign = -1
+			}
+			for _, term := range timeOffsetTerm.FindAllStringSubmatch(m[2], -1) {
+				n, err := strconv.Atoi(term[1])
+				if err != nil {
+					return "", fmt.Errorf("invalid offset %q", m[1]+m[2])
+				}
+				n *= sign
+				switch term[2] {
+				case "y":
+					now = now.AddDate(n, 0, 0)
+				case "mo":
+					now = now.AddDate(0, n, 0)
+				case "w":
+					now = now.AddDate(0, 0, 7*n)
+				case "d":
+					now = now.AddDate(0, 0, n)
+				default:
+					now = now.Add(time.Duration(n) * timeOffsetUnits[term[2]])
+				}
+			}
+			spec = m[3]
+		}
+		if strings.Contains(spec, "%") {
+			return strftime(now, spec, env.localeOrEnglish())
+		}
+		return now.Format(cmp.Or(spec, layout)), nil
+	}
+}
+
+func (env templateEnv) localeOrEnglish() Locale {
+	if env.locale.name == "" {
+		return englishLocale
+	}
+	return env.locale
+}
+
+// strftimeShorthands are the strftime directives that stand for others
+var strftimeShorthands = map[byte]string{'F': "%Y-%m-%d", 'T': "%H:%M:%S", 'R': "%H:%M", 'D': "%m/%d/%y"}
+
+// strftime formats t as C's strftime does with format, with the names,
+// %x, %X and %c of locale
+func strftime(t time.Time, format string, locale Locale) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		if i == len(format) {
+			return "", errors.New("format ends with %")
+		}
+		hour12 := t.Hour() % 12
+		if hour12 == 0 {
+			hour12 = 12
+		}
+		switch c := format[i]; c {
+		case 'a':
+			b.WriteString(locale.shortDays[t.Weekday()])
+		case 'A':
+			b.WriteString(locale.days[t.Weekday()])
+		case 'b', 'h':
+			b.WriteString(locale.shortMonths[t.Month()-1])
+		case 'B':
+			b.WriteString(locale.months[t.Month()-1])
+		case 'd':
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case 'e':
+			fmt.Fprintf(&b, "%2d", t.Day())
+		case 'H':
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case 'I':
+			fmt.Fprintf(&b, "%02d", hour12)
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 'm':
+			fmt.Fprintf(&b, "%02d", t.Month())
+		case 'M':
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case 'p':
+			if t.Hour() < 12 {
+				b.WriteString(locale.am)
+			} else {
+				b.WriteString(locale.pm)
+			}
+		case 'S':
+			fmt.Fprintf(&b, "%02d", t.Second())
+		case 'u':
+			fmt.Fprintf(&b, "%d", (int(t.Weekday())+6)%7+1)
+		case 'w':
+			fmt.Fprintf(&b, "%d", t.Weekday())
+		case 'V':
+			_, week := t.ISOWeek()
+			fmt.Fprintf(&b, "%02d", week)
+		case 'y':
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+		case 'Y':
+			fmt.Fprintf(&b, "%d", t.Year())
+		case 'z':
+			b.WriteString(t.Format("-0700"))
+		case 'Z':
+			b.WriteString(t.Format("MST"))
+		case '%':
+			b.WriteByte('%')
+		default:
+			expansion, ok := strftimeShorthands[c]
+			switch c {
+			case 'x':
+				expansion, ok = locale.date, true
+			case 'X':
+				expansion, ok = locale.time, true
+			case 'c':
+				expansion, ok = locale.dateTime, true
+			}
+			if !ok {
+				return "", fmt.Errorf("unknown directive %%%c", c)
+			}
+			s, err := strftime(t, expansion, locale)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(s)
+		}
+	}
+	return b.String(), nil
+}
+
+// numberFunc writes a number with the locale's decimal point and
+// thousands separator, rounded to a number of decimals if one is given
+func numberFunc(env templateEnv, args []string) (string, error) {
+	nf := NumberFormat{separator: ",", decimals: -1}
+	if len(args) == 2 {
+		decimals, err := strconv.Atoi(args[0])
+		if err != nil || decimals < 0 || decimals > maxNumberDecimals {
+			return "", fmt.Errorf("invalid decimals %q", args[0])
+		}
+		nf.decimals = decimals
+	}
+	formatted, err := formatNumber(args[len(args)-1], nf)
+	if err != nil {
+		return "", err
+	}
+	// Swap the separators for the locale's in one pass, so "." and ","
+	// trading places do not undo each other
+	locale := env.localeOrEnglish()
+	return strings.NewReplacer(",", locale.group, ".", locale.decimal).Replace(formatted), nil
+}
+
+func currentUser(templateEnv, []string) (string, error) {
+	if u, err := user.Current(); err == nil {
+		return u.Username, nil
+	}
+	for _, name := range []string{"USER", "USERNAME", "LOGNAME"} {
+		if value := os.Getenv(name); value != "" {
+			return value, nil
+		}
+	}
+	return "", errors.New("cannot tell the current user")
+}
+
+func repeatFunc(_ templateEnv, args []string) (string, error) {
+	count, err := strconv.Atoi(args[0])
+	if err != nil || count < 0 {
+		return "", fmt.Errorf("invalid count %q", args[0])
+	}
+	// Divided, not multiplied, so a huge count cannot overflow past
+	// the check
+	if count > maxTemplateWidth/max(1, asciiart.DisplayWidth(args[1])) {
+		return "", fmt.Errorf("would be wider than %d characters", maxTemplateWidth)
+	}
+	return strings.Repeat(args[1], count), nil
+}
+
+// padFunc pads the text with spaces to the width, on the left as with
+// %5s, or on the right for a negative width as with %-5s
+func padFunc(_ templateEnv, args []string) (string, error) {
+	width, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "", fmt.Errorf("invalid width %q", args[0])
+	}
+	if width > maxTemplateWidth || -width > maxTemplateWidth {
+		return "", fmt.Errorf("width %d is over %d", width, maxTemplateWidth)
+	}
+	padding := strings.Repeat(" ", max(0, max(width, -width)-asciiart.DisplayWidth(args[1])))
+	if width < 0 {
+		return args[1] + padding, nil
+	}
+	return padding + args[1], nil
+}
+
+// execFunc runs a command allowed by template_exec, without a shell, and
+// returns its output without the final newline
+func execFunc(env templateEnv, args []string) (string, error) {
+	words := strings.Fields(args[0])
+	if len(words) == 0 {
+		return "", errors.New("needs a command, as in {exec:git rev-parse --short HEAD}")
+	}
+	if !env.allows(words) {
+		return "", fmt.Errorf("%q is not allowed (add it to template_exec in the config file)", args[0])
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), templateExecTimeout)
+	defer cancel()
+	var stderr strings.Builder
+	cmd := exec.CommandContext(ctx, words[0], words[1:]...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("timed out after %s", templateExecTimeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// allows reports whether a command's words start with those of one of
+// the allowed commands, so "git" allows any git command and
+// "git rev-parse" only that one
+func (env templateEnv) allows(words []string) bool {
+	for _, allowed := range env.exec {
+		prefix := strings.Fields(allowed)
+		if len(prefix) > 0 && len(prefix) <= len(words) && slices.Equal(prefix, words[:len(prefix)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// call runs the function name with the arguments in arg and, if piped
+// is set, the value piped into it
+func (env templateEnv) call(name, arg string, piped *string) (string, error) {
+	f, ok := templateFuncs[name]
+	if !ok {
+		return "", fmt.Errorf("unknown function %q", name)
+	}
+	if env.safe && !f.safe {
+		return "", fmt.Errorf("{%s} is not available in requests", name)
+	}
+	var args []string
+	if arg != "" {
+		if f.max == 0 {
+			return "", fmt.Errorf("use %s", f.usage)
+		}
+		args = strings.SplitN(arg, ":", f.max)
+	}
+	if piped != nil {
+		args = append(args, *piped)
+	}
+	if len(args) < f.min || len(args) > f.max {
+		return "", fmt.Errorf("use %s", f.usage)
+	}
+	return f.call(env, args)
+}
+
+// expand replaces the placeholders in text with their values. Braces
+// that do not start with a known function are left as they are.
+func (env templateEnv) expand(text string) (string, error) {
+	var firstErr error
+	expanded := templatePlaceholder.ReplaceAllStringFunc(text, func(match string) string {
+		switch match {
+		case "{{":
+			return "{"
+		case "}}":
+			return "}"
+		}
+		parts := templatePlaceholder.FindStringSubmatch(match)
+		if _, ok := templateFuncs[parts[1]]; !ok {
+			return match
+		}
+		value, err := env.call(parts[1], parts[2], nil)
+		for _, pipe := range strings.Split(parts[3], "|")[1:] {
+			if err != nil {
+				break
+			}
+			name, arg, _ := strings.Cut(pipe, ":")
+			value, err = env.call(name, arg, &value)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %v", match, err)
+		}
+		return value
+	})
+	return expanded, firstErr
+}
+
+// expandTemplate fills in the placeholders of text typed, given or read
+// from a batch file, with every function
+func (config *AppConfig) expandTemplate(text string) (string, error) {
+	return templateEnv{exec: config.templateExec, locale: config.locale}.expand(text)
+}
+
+// expandRequestTemplate fills in the placeholders of a server or
+// co-process request's text with the safe functions only: nothing that
+// reads the server's environment or runs commands
+func (config *AppConfig) expandRequestTemplate(text string) (string, error) {
+	return templateEnv{safe: true, locale: config.locale}.expand(text)
+}
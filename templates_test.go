@@ -0,0 +1,139 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplateFunctions(t *testing.T) {
+	tests := []struct {
+		text, want string
+		fails      bool
+	}{
+		{text: "{upper:shout}", want: "SHOUT"},
+		{text: "{lower:QUIET}", want: "quiet"},
+		{text: "{repeat:3:ab}", want: "ababab"},
+		{text: "{repeat:0:ab}", want: ""},
+		{text: "{repeat:1000:x}", want: strings.Repeat("x", 1000)},
+		{text: "{repeat:1001:x}", fails: true},
+		{text: "{repeat:501:ab}", fails: true},
+		{text: "{repeat:4611686018427387904:ab}", fails: true},
+		{text: "{repeat:-1:ab}", fails: true},
+		{text: "{repeat:many:ab}", fails: true},
+		{text: "{pad:5:ab}", want: "   ab"},
+		{text: "{pad:-5:ab}", want: "ab   "},
+		{text: "{pad:1:abc}", want: "abc"},
+		{text: "{pad:1001:ab}", fails: true},
+		{text: "{pad:-1001:ab}", fails: true},
+		{text: "{lower:ABC|upper|repeat:2}", want: "ABCABC"},
+		{text: "{{upper:x}}", want: "{upper:x}"},
+		{text: "{nothing:here}", want: "{nothing:here}"},
+		{text: "{upper}", fails: true},
+		{text: "{exec:true}", fails: true},
+	}
+	for _, test := range tests {
+		got, err := templateEnv{}.expand(test.text)
+		if test.fails {
+			if err == nil {
+				t.Errorf("%s expanded to %q, want an error", test.text, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("%s expanded to %q (%v), want %q", test.text, got, err, test.want)
+		}
+	}
+}
+
+func TestRequestTemplatesAreSafe(t *testing.T) {
+	for _, text := range []string{"{user}", "{hostname}", "{env:HOME}", "{exec:id}", "{upper:x|exec}"} {
+		if got, err := newAppConfig().expandRequestTemplate(text); err == nil {
+			t.Errorf("%s expanded to %q in a request, want an error", text, got)
+		}
+	}
+	if got, err := newAppConfig().expandRequestTemplate("{repeat:4611686018427387904:ab}"); err == nil {
+		t.Errorf("huge {repeat} expanded to %d bytes in a request, want an error", len(got))
+	}
+}
+
+func TestTemplateExecAllowed(t *testing.T) {
+	env := templateEnv{exec: []string{"echo hello"}}
+	if got, err := env.expand("{exec:echo hello there}"); err != nil || got != "hello there" {
+		t.Errorf("allowed command gave %q (%v), want %q", got, err, "hello there")
+	}
+	if _, err := env.expand("{exec:echo goodbye}"); err == nil {
+		t.Error("command outside template_exec ran")
+	}
+}
+
+func TestStrftime(t *testing.T) {
+	at := time.Date(2026, time.March, 5, 14, 7, 9, 0, time.UTC)
+	german, _ := findLocale("de_DE.UTF-8")
+	tests := []struct {
+		format string
+		locale Locale
+		want   string
+	}{
+		{"%Y-%m-%d %H:%M:%S", englishLocale, "2026-03-05 14:07:09"},
+		{"%A %e %B", englishLocale, "Thursday  5 March"},
+		{"%a, %d %b %y", englishLocale, "Thu, 05 Mar 26"},
+		{"%I:%M %p", englishLocale, "02:07 PM"},
+		{"%j %u %w %V", englishLocale, "064 4 4 10"},
+		{"%F %T %R", englishLocale, "2026-03-05 14:07:09 14:07"},
+		{"%x %X", englishLocale, "03/05/2026 02:07:09 PM"},
+		{"100%%", englishLocale, "100%"},
+		{"%A %e. %B", german, "Donnerstag  5. März"},
+		{"%x %X", german, "05.03.2026 14:07:09"},
+		{"%c", german, "Do 05 Mär 2026 14:07:09"},
+	}
+	for _, test := range tests {
+		got, err := strftime(at, test.format, test.locale)
+		if err != nil || got != test.want {
+			t.Errorf("strftime(%q) in %s = %q (%v), want %q", test.format, test.locale.name, got, err, test.want)
+		}
+	}
+	for _, format := range []string{"%Q", "ends with %"} {
+		if got, err := strftime(at, format, englishLocale); err == nil {
+			t.Errorf("strftime(%q) = %q, want an error", format, got)
+		}
+	}
+}
+
+func TestLocalePlaceholders(t *testing.T) {
+	tests := []struct {
+		locale, text, want string
+	}{
+		{"", "{number:1234567.5}", "1,234,567.5"},
+		{"C", "{number:2:1234.5}", "1,234.50"},
+		{"de_DE.UTF-8", "{number:1234567.5}", "1.234.567,5"},
+		{"de_AT", "{number:-1234}", "-1.234"},
+		{"fr_FR@euro", "{number:1234567.5}", "1 234 567,5"},
+		{"sv_SE", "{number:0:1234.4}", "1 234"},
+		{"de", "{number:1:1234.56}", "1.234,6"},
+	}
+	for _, test := range tests {
+		locale, ok := findLocale(test.locale)
+		if !ok {
+			t.Fatalf("no locale %q", test.locale)
+		}
+		got, err := templateEnv{locale: locale}.expand(test.text)
+		if err != nil || got != test.want {
+			t.Errorf("%s in %s expanded to %q (%v), want %q", test.text, test.locale, got, err, test.want)
+		}
+	}
+	for _, text := range []string{"{number:many}", "{number:x:1}", "{number:99:1}"} {
+		if got, err := (templateEnv{}).expand(text); err == nil {
+			t.Errorf("%s expanded to %q, want an error", text, got)
+		}
+	}
+
+	if _, err := resolveLocale("tlh_KX"); err == nil {
+		t.Error("resolveLocale accepted an unknown -locale")
+	}
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_TIME", "tlh_KX.UTF-8")
+	if locale, err := resolveLocale(""); err != nil || locale.name != "en" {
+		t.Errorf("unknown $LC_TIME gave %q (%v), want English", locale.name, err)
+	}
+}
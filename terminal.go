@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// Color depths reported by detectColorDepth
+const (
+	colorDepthNone = 0
+	colorDepth16   = 16
+	colorDepth256  = 256
+	colorDepthTrue = 1 << 24
+)
+
+// terminalSize returns the size of the terminal attached to stdout
+func terminalSize() (width, height int, ok bool) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 {
+		return 0, 0, false
+	}
+	return width, height, true
+}
+
+// isTerminal reports whether w writes to a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// synchronizedOutput reports whether updates to f should be sent as DEC
+// 2026 synchronized updates. Terminals without the mode ignore it, so it
+// is used on any terminal but those too simple to parse it.
+func synchronizedOutput(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd())) && os.Getenv("TERM") != "dumb"
+}
+
+// stdinPiped reports whether stdin is a pipe or a redirected file
+// rather than a terminal
+func stdinPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeNamedPipe != 0 || info.Mode().IsRegular()
+}
+
+// detectColorDepth guesses how many colors the terminal can show from
+// the environment, following the usual NO_COLOR/COLORTERM/TERM rules.
+func detectColorDepth() int {
+	if color.NoColor {
+		return colorDepthNone
+	}
+	if ct := strings.ToLower(os.Getenv("COLORTERM")); ct == "truecolor" || ct == "24bit" {
+		return colorDepthTrue
+	}
+	termName := os.Getenv("TERM")
+	switch {
+	case strings.Contains(termName, "truecolor") || strings.Contains(termName, "direct"):
+		return colorDepthTrue
+	case strings.Contains(termName, "256"):
+		return colorDepth256
+	case os.Getenv("WT_SESSION") != "":
+		// Windows Terminal supports truecolor without advertising it
+		return colorDepthTrue
+	}
+	return colorDepth16
+}
+
+// currentLocale returns the effective locale from the environment
+func currentLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// unicodeSupported reports whether the locale suggests UTF-8 output
+func unicodeSupported() bool {
+	if runtime.GOOS == "windows" {
+		return os.Getenv("WT_SESSION") != "" || os.Getenv("TERM_PROGRAM") != ""
+	}
+	locale := strings.ToUpper(currentLocale())
+	return strings.Contains(locale, "UTF-8") || strings.Contains(locale, "UTF8")
+}
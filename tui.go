@@ -0,0 +1,709 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// pickerListRows is how many entries of the style and color lists show
+// at once; the lists scroll to keep the choice in view
+const pickerListRows = 8
+
+// pickerWarningRows is how many font and glyph warnings about the
+// preview the picker shows, above it
+const pickerWarningRows = 2
+
+// pickerColumn is the width of the style list, left of the color list
+const pickerColumn = 40
+
+// Keys the picker reads, other than the text typed
+const (
+	keyRune = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyTab
+	keyEnter
+	keyBackspace
+	keyClear
+	keySearch
+	keyEscape
+	keyQuit
+	keyNewTab
+	keyCloseTab
+	keyNextTab
+	keyPrevTab
+	keyExport
+	keyPaste
+)
+
+// exportAllCommand is what the picker returns for Ctrl-E, and what can be
+// typed at the text prompt, to save every tab's banner to a file
+const exportAllCommand = ":export"
+
+// tabLabelWidth is how much of a tab's text its label shows
+const tabLabelWidth = 12
+
+type keyPress struct {
+	key  int
+	r    rune   // For keyRune
+	text string // For keyPaste, with line breaks as \n
+}
+
+// picker is interactive mode's full-screen prompt: the text being typed,
+// the style and color scheme lists, and a preview of the banner that
+// follows every key. The choices carry over from one banner to the next.
+// A search narrows both lists to the entries matching what is typed.
+// Several banners can be worked on at once in tabs, each with its own
+// text, style and color scheme.
+type picker struct {
+	config *AppConfig
+	styles []styleCombo
+	style  int  // Index into styles
+	scheme int  // 0 for no colors, otherwise config.colors[scheme-1]
+	colors bool // The color list has the focus, not the style list
+	text   []rune
+
+	tabs []workspaceTab // Every tab, the current one as it was last kept
+	tab  int            // Index of the current tab
+
+	searching               bool
+	query                   []rune
+	savedStyle, savedScheme int // The choices before the search, for Esc
+}
+
+// workspaceTab is one banner of the picker's workspace
+type workspaceTab struct {
+	text          []rune
+	style, scheme int
+}
+
+// newPicker starts with the choices options already has, from flags or
+// the config file, in a single tab
+func newPicker(config *AppConfig, options RenderOptions) *picker {
+	p := &picker{config: config, styles: config.styleCombos()}
+	for i, combo := range p.styles {
+		if combo.category+1 == options.category && (combo.style+1 == options.style || options.style == 0) {
+			p.style = i
+			break
+		}
+	}
+	if options.showColors {
+		p.scheme = max(options.colorScheme, 1)
+		if p.scheme > len(config.colors) {
+			p.scheme = 1
+		}
+	}
+	p.tabs = []workspaceTab{{style: p.style, scheme: p.scheme}}
+	return p
+}
+
+// run shows the picker until Enter, then sets the choices in options and
+// returns the text to render. It returns false when the user quits.
+func (p *picker) run(options *RenderOptions) (string, bool) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		fmt.Printf("Error preparing terminal: %v\n", err)
+		os.Exit(1)
+	}
+	defer term.Restore(fd, state)
+
+	scr := newScreen(os.Stdout)
+	scr.open()
+	defer scr.close()
+	fmt.Print(bracketedPasteOn)
+	defer fmt.Print(bracketedPasteOff)
+	var mu sync.Mutex
+	draw := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		width, height, _ := terminalSize()
+		scr.drawClipped(p.frame(*options, width, height), width, height)
+	}
+	resized, stopResize := watchResize()
+	defer stopResize()
+	// The screen is closed only once the resize goroutine has stopped
+	done, stopped := make(chan struct{}), make(chan struct{})
+	defer func() {
+		close(done)
+		<-stopped
+	}()
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-resized:
+				mu.Lock()
+				scr.invalidate()
+				mu.Unlock()
+				draw()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	buf := make([]byte, 256)
+	var pending []byte // The start of a paste that is still arriving
+	for {
+		draw()
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return "", false
+		}
+		var presses []keyPress
+		presses, pending = decodeInput(append(pending, buf[:n]...))
+		// The resize goroutine draws the picker too, so it must not see
+		// a key half handled
+		mu.Lock()
+		text, ok, finished := p.handle(presses, options)
+		mu.Unlock()
+		if finished {
+			return text, ok
+		}
+	}
+}
+
+// handle applies the keys pressed. It finishes with the text to render,
+// or false if the user quit, on the key that leaves the picker.
+func (p *picker) handle(presses []keyPress, options *RenderOptions) (string, bool, bool) {
+	for _, press := range presses {
+		switch press.key {
+		case keyRune:
+			switch {
+			case p.searching:
+				p.query = append(p.query, press.r)
+				p.filter()
+			case press.r == '/' && len(p.text) == 0:
+				p.startSearch()
+			default:
+				p.text = append(p.text, press.r)
+			}
+		case keySearch:
+			p.startSearch()
+		case keyBackspace:
+			switch {
+			case p.searching && len(p.query) == 0:
+				p.endSearch(true)
+			case p.searching:
+				p.query = p.query[:len(p.query)-1]
+				p.filter()
+			case len(p.text) > 0:
+				p.text = p.text[:len(p.text)-1]
+			}
+		case keyClear:
+			if p.searching {
+				p.query = nil
+				p.filter()
+				continue
+			}
+			p.text = nil
+		case keyTab, keyLeft, keyRight:
+			p.colors = !p.colors
+		case keyUp:
+			p.move(-1)
+		case keyDown:
+			p.move(1)
+		case keyEnter:
+			if p.searching {
+				p.endSearch(true)
+				continue
+			}
+			if strings.TrimSpace(string(p.text)) == "" {
+				continue
+			}
+			p.choose(options)
+			return string(p.text), true, true
+		case keyEscape:
+			if p.searching {
+				p.endSearch(false)
+				continue
+			}
+			return "", false, true
+		case keyQuit:
+			return "", false, true
+		case keyNewTab, keyCloseTab, keyNextTab, keyPrevTab:
+			p.endSearch(true)
+			p.switchTab(press.key)
+		case keyExport:
+			p.endSearch(true)
+			p.keep()
+			return exportAllCommand, true, true
+		case keyPaste:
+			if p.searching {
+				p.query = append(p.query, []rune(strings.ReplaceAll(press.text, "\n", " "))...)
+				p.filter()
+				continue
+			}
+			p.text = append(p.text, []rune(press.text)...)
+		}
+	}
+	return "", false, false
+}
+
+// move steps the focused list by delta through the entries shown,
+// wrapping around
+func (p *picker) move(delta int) {
+	matches, selected := p.styleMatches(), &p.style
+	if p.colors {
+		matches, selected = p.schemeMatches(), &p.scheme
+	}
+	if len(matches) == 0 {
+		return
+	}
+	i := max(0, slices.Index(matches, *selected))
+	*selected = matches[(i+delta+len(matches))%len(matches)]
+}
+
+// keep stores the text and choices shown in the current tab
+func (p *picker) keep() {
+	p.tabs[p.tab] = workspaceTab{text: p.text, style: p.style, scheme: p.scheme}
+}
+
+// load shows tab i
+func (p *picker) load(i int) {
+	p.tab = i
+	tab := p.tabs[i]
+	p.text, p.style, p.scheme = tab.text, tab.style, tab.scheme
+}
+
+// switchTab opens a new tab after the current one, with its style and
+// color scheme, closes the current tab, or moves to the next or
+// previous one, for the key pressed
+func (p *picker) switchTab(key int) {
+	p.keep()
+	switch key {
+	case keyNewTab:
+		p.tabs = slices.Insert(p.tabs, p.tab+1, workspaceTab{style: p.style, scheme: p.scheme})
+		p.load(p.tab + 1)
+	case keyCloseTab:
+		if len(p.tabs) == 1 {
+			p.text = nil
+			return
+		}
+		p.tabs = slices.Delete(p.tabs, p.tab, p.tab+1)
+		p.load(min(p.tab, len(p.tabs)-1))
+	case keyNextTab:
+		p.load((p.tab + 1) % len(p.tabs))
+	case keyPrevTab:
+		p.load((p.tab + len(p.tabs) - 1) % len(p.tabs))
+	}
+}
+
+// startSearch starts filtering the lists by what is typed next
+func (p *picker) startSearch() {
+	if !p.searching {
+		p.searching, p.query = true, nil
+		p.savedStyle, p.savedScheme = p.style, p.scheme
+	}
+}
+
+// endSearch shows the whole lists again, with the choices found when
+// keep is set and those from before the search otherwise
+func (p *picker) endSearch(keep bool) {
+	if !keep {
+		p.style, p.scheme = p.savedStyle, p.savedScheme
+	}
+	p.searching, p.query = false, nil
+}
+
+// filter selects the best match in each list as the search changes, or
+// the choice from before the search in a list with none
+func (p *picker) filter() {
+	p.style, p.scheme = p.savedStyle, p.savedScheme
+	if matches := p.styleMatches(); len(matches) > 0 && len(p.query) > 0 {
+		p.style = matches[0]
+	}
+	if matches := p.schemeMatches(); len(matches) > 0 && len(p.query) > 0 {
+		p.scheme = matches[0]
+	}
+}
+
+// styleMatches returns the indices into styles the search matches, best
+// first, or all of them in order. A style matches by its name, its
+// category's or its font's, or a description containing the search.
+func (p *picker) styleMatches() []int {
+	return searchList(len(p.styles), string(p.query), func(i int) ([]string, string) {
+		combo := p.styles[i]
+		category := p.config.categories[combo.category]
+		style := category.Styles[combo.style]
+		return []string{style.Name, category.Name, style.Font}, style.Description
+	})
+}
+
+// schemeMatches returns the color list entries the search matches: 0
+// for None, otherwise one more than the index into config.colors
+func (p *picker) schemeMatches() []int {
+	return searchList(len(p.config.colors)+1, string(p.query), func(i int) ([]string, string) {
+		if i == 0 {
+			return []string{"None"}, ""
+		}
+		return []string{p.config.colors[i-1].Name}, ""
+	})
+}
+
+// searchList returns the indices of the n entries that match query,
+// best first, with entries scoring the same left in order. fields gives
+// an entry's names, matched fuzzily, and its description, matched where
+// it contains query.
+func searchList(n int, query string, fields func(int) ([]string, string)) []int {
+	type match struct{ index, score int }
+	var matches []match
+	for i := range n {
+		names, description := fields(i)
+		best, found := 0, query == ""
+		for _, name := range names {
+			if score, ok := fuzzyScore(query, name); ok && (!found || score > best) {
+				best, found = score, true
+			}
+		}
+		if !found && description != "" && strings.Contains(strings.ToLower(description), strings.ToLower(query)) {
+			found = true
+		}
+		if found {
+			matches = append(matches, match{i, best})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b match) int { return b.score - a.score })
+	indices := make([]int, len(matches))
+	for i, m := range matches {
+		indices[i] = m.index
+	}
+	return indices
+}
+
+// choose sets the picked style and color scheme in options, for a render
+// that prints the art alone, without prompts
+func (p *picker) choose(options *RenderOptions) {
+	p.keep()
+	p.apply(p.tabs[p.tab], options)
+	options.bare = true
+}
+
+// apply sets a tab's style and color scheme in options
+func (p *picker) apply(tab workspaceTab, options *RenderOptions) {
+	combo := p.styles[tab.style]
+	options.category, options.style = combo.category+1, combo.style+1
+	options.colorScheme, options.showColors = tab.scheme, tab.scheme > 0
+}
+
+// exportAll saves the banner of every tab with text, each in the tab's
+// own style and color scheme, to its own file in the current directory,
+// named after its text the way -batch names the files in -out-dir
+func (p *picker) exportAll(options RenderOptions) {
+	var tabs []workspaceTab
+	var texts []string
+	for _, tab := range p.tabs {
+		if text := string(tab.text); strings.TrimSpace(text) != "" && text != exportAllCommand {
+			tabs, texts = append(tabs, tab), append(texts, text)
+		}
+	}
+	if len(texts) == 0 {
+		fmt.Println(color.YellowString("Nothing to export yet"))
+		return
+	}
+	paths := p.config.batchFileNames(".", texts, options.format)
+	options.bare, options.fitTerminal = true, false
+	for i, tab := range tabs {
+		p.apply(tab, &options)
+		options.outputFile = paths[i]
+		processText(texts[i], p.config, options)
+	}
+}
+
+// frame draws the picker for a terminal of width by height cells
+func (p *picker) frame(options RenderOptions, width, height int) string {
+	var b strings.Builder
+	styleMatches, schemeMatches := p.styleMatches(), p.schemeMatches()
+	if p.searching {
+		b.WriteString(color.CyanString("ASCII Art Generator") + "  " +
+			color.HiBlackString("type to search · Tab switches list · ↑↓ choose · Enter keeps · Esc cancels") + "\n\n")
+		b.WriteString("Search: " + string(p.query) + "\x1b[7m " + sgrReset +
+			color.HiBlackString("  %d styles, %d color schemes", len(styleMatches), len(schemeMatches)) + "\n\n")
+	} else {
+		b.WriteString(color.CyanString("ASCII Art Generator") + "  " +
+			color.HiBlackString("type the text · / searches · Tab switches list · ↑↓ choose · Enter prints · Esc quits") + "\n\n")
+		b.WriteString("Text: " + strings.ReplaceAll(string(p.text), "\n", color.HiBlackString("↵")) + "\x1b[7m " + sgrReset + "\n")
+		b.WriteString(p.tabBar() + "\n\n")
+	}
+
+	styleHeading, colorHeading := color.HiWhiteString("Styles"), color.HiBlackString("Color schemes")
+	if p.colors {
+		styleHeading, colorHeading = color.HiBlackString("Styles"), color.HiWhiteString("Color schemes")
+	}
+	column := min(pickerColumn, width/2)
+	b.WriteString(styleHeading + strings.Repeat(" ", max(1, column-len("Styles"))) + colorHeading + "\n")
+
+	styleLines := listWindow(styleMatches, p.style, func(i int) string {
+		combo := p.styles[i]
+		category := p.config.categories[combo.category]
+		return fmt.Sprintf("%d.%d %s (%s)", combo.category+1, combo.style+1, category.Styles[combo.style].Name, category.Name)
+	})
+	colorLines := listWindow(schemeMatches, p.scheme, func(i int) string {
+		if i == 0 {
+			return "None"
+		}
+		return p.config.colors[i-1].Name
+	})
+	for row := range pickerListRows {
+		b.WriteString(p.entry(styleLines[row], p.style, !p.colors, column, nil))
+		var scheme *asciiart.ColorScheme
+		if index := colorLines[row].index; index > 0 {
+			scheme = &p.config.colors[index-1]
+		}
+		b.WriteString(p.entry(colorLines[row], p.scheme, p.colors, 0, scheme) + "\n")
+	}
+	combo := p.styles[p.style]
+	style := p.config.categories[combo.category].Styles[combo.style]
+	font := "plain text"
+	if style.Font != "" {
+		font = "font " + style.Font
+	}
+	b.WriteString(color.HiBlackString("%s: %s · %s", style.Name, style.Description, font) + "\n")
+	art, warnings := p.preview(options, width)
+	for i, warning := range warnings {
+		if i == pickerWarningRows {
+			b.WriteString(color.YellowString("  … %d more warnings", len(warnings)-i) + "\n")
+			break
+		}
+		if warning != "" {
+			b.WriteString(warning + "\n")
+		}
+	}
+	b.WriteString(color.HiBlackString(strings.Repeat("─", max(1, width))) + "\n")
+	b.WriteString(art)
+	return b.String()
+}
+
+// tabBar lists the tabs by their text, the current one highlighted,
+// with the keys for them
+func (p *picker) tabBar() string {
+	var labels []string
+	for i, tab := range p.tabs {
+		text := []rune(strings.TrimSpace(string(tab.text)))
+		if i == p.tab {
+			text = []rune(strings.TrimSpace(string(p.text)))
+		}
+		if len(text) > tabLabelWidth {
+			text = append(text[:tabLabelWidth-1], '…')
+		}
+		label := fmt.Sprintf(" %d %s ", i+1, string(text))
+		if i == p.tab {
+			label = "\x1b[7m" + label + sgrReset
+		}
+		labels = append(labels, label)
+	}
+	return strings.Join(labels, " ") + "  " +
+		color.HiBlackString("Ctrl-T new tab · Ctrl-N/Ctrl-P switch · Ctrl-W closes · Ctrl-E exports all")
+}
+
+// listLine is one row of a scrolling list: the entry's index, or -1
+// below the last entry, and its label
+type listLine struct {
+	index int
+	label string
+}
+
+// listWindow returns the pickerListRows of the entries shown, indices,
+// around selected, labeled by label
+func listWindow(indices []int, selected int, label func(int) string) []listLine {
+	start := max(0, min(slices.Index(indices, selected)-pickerListRows/2, len(indices)-pickerListRows))
+	lines := make([]listLine, pickerListRows)
+	for row := range lines {
+		lines[row].index = -1
+		if i := start + row; i < len(indices) {
+			lines[row] = listLine{indices[i], label(indices[i])}
+		}
+	}
+	return lines
+}
+
+// entry draws a list row padded to width cells (0 for no padding),
+// marked when it is the selected one and highlighted in the focused
+// list, with a color scheme's name in its own color
+func (p *picker) entry(line listLine, selected int, focused bool, width int, scheme *asciiart.ColorScheme) string {
+	label := "  " + line.label
+	if line.index == selected {
+		label = "> " + line.label
+	}
+	if line.index < 0 {
+		label = ""
+	}
+	padding := strings.Repeat(" ", max(1, width-asciiart.DisplayWidth(label)))
+	if width == 0 {
+		padding = ""
+	}
+	switch {
+	case scheme != nil:
+		label = scheme.Primary.Sprint(label)
+	case !focused:
+		label = color.HiBlackString(label)
+	}
+	if line.index == selected && focused {
+		label = "\x1b[7m" + label + sgrReset
+	}
+	return label + padding
+}
+
+// preview renders the text, or a sample until something is typed, in
+// the selected style and color scheme, the way processText will. The
+// picker owns the screen, so font and glyph warnings are returned to be
+// shown in the frame rather than written to stderr.
+func (p *picker) preview(options RenderOptions, width int) (string, []string) {
+	var warnings bytes.Buffer
+	config := p.config.withWarnings(&warnings)
+	art := p.render(config, options, width)
+	return art, strings.Split(strings.TrimSpace(warnings.String()), "\n")
+}
+
+// render draws the preview with config
+func (p *picker) render(config *AppConfig, options RenderOptions, width int) string {
+	text := string(p.text)
+	if strings.TrimSpace(text) == "" {
+		text = previewSample
+	}
+	combo := p.styles[p.style]
+	style := options.restyle(config.categories[combo.category].Styles[combo.style])
+	text = config.artRenderer().Wrap(text, style, width)
+
+	var scheme *asciiart.ColorScheme
+	if p.scheme > 0 {
+		scheme = &config.colors[p.scheme-1]
+	}
+	gradient := options.gradient
+	if options.fg != nil {
+		scheme = options.fg
+	}
+	if p.scheme == 0 {
+		scheme, gradient = nil, nil
+	}
+	switch {
+	case options.blocks:
+		return config.blockRaster(text, style, scheme, gradient).HalfBlocks(detectColorDepth())
+	case options.teletext:
+		return config.blockRaster(text, style, scheme, gradient).Teletext(detectColorDepth())
+	case gradient != nil:
+		return config.artRenderer().RenderColorized(text, style, gradient)
+	}
+	return config.generateArt(text, style, scheme)
+}
+
+// decodeInput splits what the terminal sent into key presses, with each
+// bracketed paste as one keyPaste, so the line breaks in it insert text
+// instead of pressing Enter. It returns the start of a paste that has
+// not ended yet, to be decoded again with the next read.
+func decodeInput(data []byte) ([]keyPress, []byte) {
+	var keys []keyPress
+	for {
+		start := bytes.Index(data, []byte(pasteStart))
+		if start < 0 {
+			// Hold back what may be the first part of a paste marker
+			for n := min(len(data), len(pasteStart)-1); n > 1; n-- {
+				if bytes.HasPrefix([]byte(pasteStart), data[len(data)-n:]) {
+					return append(keys, decodeKeys(data[:len(data)-n])...), data[len(data)-n:]
+				}
+			}
+			return append(keys, decodeKeys(data)...), nil
+		}
+		keys = append(keys, decodeKeys(data[:start])...)
+		end := bytes.Index(data[start:], []byte(pasteEnd))
+		if end < 0 {
+			return keys, data[start:]
+		}
+		text := string(data[start+len(pasteStart) : start+end])
+		text = strings.Map(func(r rune) rune {
+			switch {
+			case r == '\n':
+				return r
+			case r == '\t':
+				return ' '
+			case r == utf8.RuneError || !unicode.IsPrint(r):
+				return -1
+			}
+			return r
+		}, strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(text))
+		if text = strings.TrimRight(text, "\n"); text != "" {
+			keys = append(keys, keyPress{key: keyPaste, text: text})
+		}
+		data = data[start+end+len(pasteEnd):]
+	}
+}
+
+// decodeKeys splits what one read of a raw terminal returned into key
+// presses. An escape on its own is Esc; arrow keys come as escape
+// sequences, and other sequences are skipped.
+func decodeKeys(data []byte) []keyPress {
+	var keys []keyPress
+	for len(data) > 0 {
+		if data[0] == 0x1b {
+			if len(data) == 1 {
+				return append(keys, keyPress{key: keyEscape})
+			}
+			n := escapeLength(string(data))
+			if n == 0 && len(data) >= 3 && data[1] == 'O' {
+				n = 3 // SS3, as some terminals send arrows
+			}
+			if n == 0 {
+				n = 2
+			}
+			switch string(data[n-1 : n]) {
+			case "A":
+				keys = append(keys, keyPress{key: keyUp})
+			case "B":
+				keys = append(keys, keyPress{key: keyDown})
+			case "C":
+				keys = append(keys, keyPress{key: keyRight})
+			case "D":
+				keys = append(keys, keyPress{key: keyLeft})
+			case "Z":
+				keys = append(keys, keyPress{key: keyTab}) // Shift-Tab
+			}
+			data = data[n:]
+			continue
+		}
+
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+		switch r {
+		case '\t':
+			keys = append(keys, keyPress{key: keyTab})
+		case '\r', '\n':
+			keys = append(keys, keyPress{key: keyEnter})
+		case 0x7f, 0x08:
+			keys = append(keys, keyPress{key: keyBackspace})
+		case 0x15: // Ctrl-U
+			keys = append(keys, keyPress{key: keyClear})
+		case 0x06: // Ctrl-F
+			keys = append(keys, keyPress{key: keySearch})
+		case 0x03, 0x04: // Ctrl-C, Ctrl-D
+			keys = append(keys, keyPress{key: keyQuit})
+		case 0x14: // Ctrl-T
+			keys = append(keys, keyPress{key: keyNewTab})
+		case 0x17: // Ctrl-W
+			keys = append(keys, keyPress{key: keyCloseTab})
+		case 0x0e: // Ctrl-N
+			keys = append(keys, keyPress{key: keyNextTab})
+		case 0x10: // Ctrl-P
+			keys = append(keys, keyPress{key: keyPrevTab})
+		case 0x05: // Ctrl-E
+			keys = append(keys, keyPress{key: keyExport})
+		default:
+			if r != utf8.RuneError && unicode.IsPrint(r) {
+				keys = append(keys, keyPress{key: keyRune, r: r})
+			}
+		}
+	}
+	return keys
+}
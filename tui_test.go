@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeInputPaste(t *testing.T) {
+	tests := []struct {
+		name    string
+		reads   []string
+		want    []keyPress
+		pending string
+	}{
+		{
+			name:  "typed",
+			reads: []string{"hi\r"},
+			want:  []keyPress{{key: keyRune, r: 'h'}, {key: keyRune, r: 'i'}, {key: keyEnter}},
+		},
+		{
+			name:  "multi-line paste",
+			reads: []string{"a" + pasteStart + "one\r\ntwo\rthree\n" + pasteEnd + "\r"},
+			want:  []keyPress{{key: keyRune, r: 'a'}, {key: keyPaste, text: "one\ntwo\nthree"}, {key: keyEnter}},
+		},
+		{
+			name:  "paste over several reads",
+			reads: []string{pasteStart + "one\r", "two", "\r" + pasteEnd[:3], pasteEnd[3:] + "x"},
+			want:  []keyPress{{key: keyPaste, text: "one\ntwo"}, {key: keyRune, r: 'x'}},
+		},
+		{
+			name:  "marker split between reads",
+			reads: []string{"x\x1b[20", "0~y\ty" + pasteEnd},
+			want:  []keyPress{{key: keyRune, r: 'x'}, {key: keyPaste, text: "y y"}},
+		},
+		{
+			name:    "paste not ended",
+			reads:   []string{pasteStart + "one\r"},
+			pending: pasteStart + "one\r",
+		},
+		{
+			name:  "escape and arrows",
+			reads: []string{"\x1b[A", "\x1b"},
+			want:  []keyPress{{key: keyUp}, {key: keyEscape}},
+		},
+	}
+	for _, test := range tests {
+		var got []keyPress
+		var pending []byte
+		for _, read := range test.reads {
+			var keys []keyPress
+			keys, pending = decodeInput(append(pending, read...))
+			got = append(got, keys...)
+		}
+		if !reflect.DeepEqual(got, test.want) || string(pending) != test.pending {
+			t.Errorf("%s: got %+v, pending %q; want %+v, pending %q", test.name, got, pending, test.want, test.pending)
+		}
+	}
+}
+
+func TestPickerShowsWarningsInFrame(t *testing.T) {
+	p := newPicker(newAppConfig(), RenderOptions{category: 1, style: 2})
+	p.text = []rune("Hi ☃")
+	stderr := os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stderr = w
+	renderer.Warnings = w
+	frame := p.frame(RenderOptions{}, 100, 40)
+	os.Stderr, renderer.Warnings = stderr, stderr
+	w.Close()
+	leaked, _ := io.ReadAll(r)
+
+	if !strings.Contains(frame, "no glyph for '☃'") {
+		t.Errorf("frame does not show the missing glyph:\n%s", frame)
+	}
+	if len(leaked) > 0 {
+		t.Errorf("preview wrote to stderr: %q", leaked)
+	}
+}
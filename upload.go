@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+func init() {
+	outputTargets["s3"] = uploadTarget("s3", putS3)
+	outputTargets["gs"] = uploadTarget("gs", putGCS)
+}
+
+// uploadTimeout bounds an upload, including fetching credentials
+const uploadTimeout = 30 * time.Second
+
+// credentialTimeout bounds asking a metadata server for credentials, so
+// machines without one are not kept waiting
+const credentialTimeout = 2 * time.Second
+
+var uploadClient = &http.Client{Timeout: uploadTimeout}
+
+// upload is one object to store in a bucket
+type upload struct {
+	bucket, key string
+	contentType string
+	body        []byte
+}
+
+// uploadFormats map a format, or the key's extension, to the variant
+// uploaded and its content type
+var uploadFormats = map[string]struct {
+	variant     func(RenderedArt) ([]byte, error)
+	contentType string
+}{
+	"text": {func(art RenderedArt) ([]byte, error) { return []byte(art.Plain + "\n"), nil }, "text/plain; charset=utf-8"},
+	"ansi": {func(art RenderedArt) ([]byte, error) { return []byte(art.ANSI + "\n"), nil }, "text/plain; charset=utf-8"},
+	"ans":  {func(art RenderedArt) ([]byte, error) { return []byte(ansArt(art.ANSI)), nil }, "text/x-ansi"},
+	"html": {func(art RenderedArt) ([]byte, error) { return []byte(art.HTML), nil }, "text/html; charset=utf-8"},
+	"svg":  {func(art RenderedArt) ([]byte, error) { return []byte(art.SVG), nil }, "image/svg+xml"},
+	"json": {func(art RenderedArt) ([]byte, error) { return json.Marshal(art) }, "application/json"},
+}
+
+// uploadFormat picks the format for key: format if given, otherwise by
+// its extension (.html, .svg, .ans, .json), otherwise text
+func uploadFormat(key, format string) string {
+	if format != "" {
+		return format
+	}
+	switch ext := strings.ToLower(path.Ext(key)); ext {
+	case ".htm":
+		return "html"
+	case ".html", ".svg", ".ans", ".json":
+		return ext[1:]
+	}
+	return "text"
+}
+
+// uploadTarget returns an output target storing the art as an object,
+// for -output s3://bucket/key or gs://bucket/key?format=svg
+func uploadTarget(scheme string, put func(upload) error) func(string, RenderedArt) error {
+	return func(dest string, art RenderedArt) error {
+		u, err := url.Parse(scheme + ":" + dest)
+		if err != nil {
+			return err
+		}
+		obj := upload{bucket: u.Host, key: strings.TrimPrefix(u.Path, "/")}
+		if obj.bucket == "" || obj.key == "" || strings.HasSuffix(obj.key, "/") {
+			return fmt.Errorf("missing bucket or object name, as in %s://bucket/banners/hello.txt", scheme)
+		}
+		format := uploadFormat(obj.key, u.Query().Get("format"))
+		f, ok := uploadFormats[format]
+		if !ok {
+			return fmt.Errorf("unknown format %q (use text, ansi, ans, html, svg or json)", format)
+		}
+		if obj.body, err = f.variant(art); err != nil {
+			return err
+		}
+		obj.contentType = f.contentType
+		return put(obj)
+	}
+}
+
+// checkResponse turns an unsuccessful response into an error, with the
+// start of the body, where storage services explain what went wrong
+func checkResponse(what string, resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if msg := strings.TrimSpace(string(body)); msg != "" {
+		return fmt.Errorf("%s returned %s: %s", what, resp.Status, msg)
+	}
+	return fmt.Errorf("%s returned %s", what, resp.Status)
+}
+
+// errNoCredentials is returned by a credential source that does not
+// apply, so the next one in the chain is tried
+var errNoCredentials = errors.New("no credentials")
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/common-nighthawk/go-figure"
+	"github.com/fatih/color"
+)
+
+// Build information, set at link time:
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=abc123 -X main.buildDate=2024-01-01"
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// BuildInfo describes this binary
+type BuildInfo struct {
+	Version   string   `json:"version"`
+	Commit    string   `json:"commit"`
+	BuildDate string   `json:"build_date"`
+	GoVersion string   `json:"go_version"`
+	Platform  string   `json:"platform"`
+	Features  []string `json:"features"`
+}
+
+// buildInfo fills in anything not set at link time from the VCS
+// metadata the Go toolchain embeds.
+func buildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Features:  enabledFeatures(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		// Only trust tagged module versions; pseudo-versions are long and
+		// the commit is reported separately anyway
+		if info.Version == "dev" && strings.HasPrefix(bi.Main.Version, "v") && !strings.ContainsAny(bi.Main.Version, "-+") {
+			info.Version = bi.Main.Version
+		}
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+	if len(info.Commit) > 12 {
+		info.Commit = info.Commit[:12]
+	}
+	return info
+}
+
+// enabledFeatures lists optional capabilities compiled into this build
+func enabledFeatures() []string {
+	var features []string
+	if audioEnabled {
+		features = append(features, "audio")
+	}
+	for scheme := range outputTargets {
+		features = append(features, "output:"+scheme)
+	}
+	sort.Strings(features)
+	return features
+}
+
+// runVersion prints build information as a small banner or as JSON
+func runVersion(config *AppConfig, args []string) {
+	flags := flag.NewFlagSet("version", flag.ExitOnError)
+	format := flags.String("format", "text", "Output format: text or json")
+	flags.Parse(args)
+
+	info := buildInfo()
+	switch *format {
+	case "json":
+		data, _ := json.MarshalIndent(info, "", "  ")
+		fmt.Println(string(data))
+	case "text":
+		banner := figure.NewFigure(info.Version, "small", false).String()
+		fmt.Print(color.CyanString(banner))
+		printField := func(name, value string) {
+			if value == "" {
+				value = "unknown"
+			}
+			fmt.Printf("%s %s\n", color.HiBlackString("%-10s", name+":"), value)
+		}
+		printField("Commit", info.Commit)
+		printField("Built", info.BuildDate)
+		printField("Go", info.GoVersion)
+		printField("Platform", info.Platform)
+		printField("Features", strings.Join(info.Features, ", "))
+	default:
+		fmt.Printf("Error: unknown format %q\n", *format)
+		os.Exit(1)
+	}
+}
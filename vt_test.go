@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ascii-art/asciiart"
+)
+
+// vtPen is the drawing state a cell was written with
+type vtPen struct {
+	fg, bg string // SGR parameters of the color, such as "31" or "38;2;255;0;0"
+	bold   bool
+}
+
+// vtCell is one cell of a vterm. The cells after the first of a wide
+// character have wide set and no text.
+type vtCell struct {
+	text string
+	pen  vtPen
+	wide bool
+}
+
+// vterm is a small virtual terminal for tests. It interprets what live
+// modes and animations write (text, cursor moves, erasures, colors and
+// the private modes they use) into a grid of cells, so tests can assert
+// on what a user would see rather than on escape sequences.
+type vterm struct {
+	width, height int
+	rows          [][]vtCell
+	saved         [][]vtCell // The normal screen while the alternate one is shown
+	row, col      int
+	pen           vtPen
+	raw           bool // As in raw mode: \n moves down without returning to the left
+	cursorHidden  bool
+	syncDepth     int      // Open synchronized updates
+	writes        int      // Calls to Write
+	unknown       []string // Sequences the terminal did not understand
+	pending       string   // An escape sequence split across writes
+}
+
+func newVTerm(width, height int) *vterm {
+	t := &vterm{width: width, height: height}
+	t.rows = t.blank()
+	return t
+}
+
+func (t *vterm) blank() [][]vtCell {
+	rows := make([][]vtCell, t.height)
+	for i := range rows {
+		rows[i] = make([]vtCell, t.width)
+	}
+	return rows
+}
+
+func (t *vterm) Write(p []byte) (int, error) {
+	t.writes++
+	s := t.pending + string(p)
+	t.pending = ""
+	for s != "" {
+		switch s[0] {
+		case '\x1b':
+			n := t.escape(s)
+			if n == 0 {
+				t.pending = s
+				return len(p), nil
+			}
+			s = s[n:]
+			continue
+		case '\r':
+			t.col = 0
+		case '\n':
+			// The tty turns newlines into \r\n unless in raw mode
+			if !t.raw {
+				t.col = 0
+			}
+			t.lineFeed()
+		case '\b':
+			t.col = max(t.col-1, 0)
+		case '\t':
+			t.col = min((t.col/8+1)*8, t.width-1)
+		default:
+			text := s
+			if i := strings.IndexAny(s, "\x1b\r\n\b\t"); i >= 0 {
+				text = s[:i]
+			}
+			cluster, width := asciiart.NextGrapheme(text)
+			t.print(cluster, width)
+			s = s[len(cluster):]
+			continue
+		}
+		s = s[1:]
+	}
+	return len(p), nil
+}
+
+// escape carries out the escape sequence at the start of s and returns
+// its length, or 0 if s ends before the sequence does
+func (t *vterm) escape(s string) int {
+	if len(s) < 2 {
+		return 0
+	}
+	if s[1] != '[' {
+		t.unknown = append(t.unknown, strconv.Quote(s[:2]))
+		return 2
+	}
+	end := 2
+	for end < len(s) && (s[end] < 0x40 || s[end] > 0x7e) {
+		end++
+	}
+	if end == len(s) {
+		return 0
+	}
+	params, final := s[2:end], s[end]
+	if strings.HasPrefix(params, "?") {
+		t.privateMode(params[1:], final)
+		return end + 1
+	}
+
+	args := vtArgs(params)
+	arg := func(i, fallback int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return fallback
+	}
+	switch final {
+	case 'H', 'f':
+		t.row = min(arg(0, 1), t.height) - 1
+		t.col = min(arg(1, 1), t.width) - 1
+	case 'A':
+		t.row = max(t.row-arg(0, 1), 0)
+	case 'B':
+		t.row = min(t.row+arg(0, 1), t.height-1)
+	case 'C':
+		t.col = min(t.col+arg(0, 1), t.width-1)
+	case 'D':
+		t.col = max(t.col-arg(0, 1), 0)
+	case 'G':
+		t.col = min(arg(0, 1), t.width) - 1
+	case 'J':
+		t.eraseDisplay(arg(0, 0))
+	case 'K':
+		t.eraseLine(arg(0, 0))
+	case 'm':
+		t.sgr(params)
+	default:
+		t.unknown = append(t.unknown, strconv.Quote(s[:end+1]))
+	}
+	return end + 1
+}
+
+// vtArgs splits CSI parameters; missing ones are 0
+func vtArgs(params string) []int {
+	var args []int
+	for _, p := range strings.Split(params, ";") {
+		n, _ := strconv.Atoi(p)
+		args = append(args, n)
+	}
+	return args
+}
+
+func (t *vterm) privateMode(mode string, final byte) {
+	on := final == 'h'
+	if final != 'h' && final != 'l' {
+		t.unknown = append(t.unknown, "?"+mode+string(final))
+		return
+	}
+	switch mode {
+	case "25":
+		t.cursorHidden = !on
+	case "1049":
+		switch {
+		case on && t.saved == nil:
+			t.saved, t.rows = t.rows, t.blank()
+		case !on && t.saved != nil:
+			t.rows, t.saved = t.saved, nil
+		}
+	case "2026":
+		if on {
+			t.syncDepth++
+		} else {
+			t.syncDepth--
+		}
+	default:
+		t.unknown = append(t.unknown, "?"+mode+string(final))
+	}
+}
+
+func (t *vterm) sgr(params string) {
+	args := strings.Split(params, ";")
+	for i := 0; i < len(args); i++ {
+		n, _ := strconv.Atoi(args[i])
+		switch {
+		case n == 0:
+			t.pen = vtPen{}
+		case n == 1:
+			t.pen.bold = true
+		case n == 22:
+			t.pen.bold = false
+		case n == 39:
+			t.pen.fg = ""
+		case n == 49:
+			t.pen.bg = ""
+		case n == 38 || n == 48:
+			// Extended colors: 5;index or 2;r;g;b
+			length := 3
+			if i+1 < len(args) && args[i+1] == "2" {
+				length = 5
+			}
+			color := strings.Join(args[i:min(i+length, len(args))], ";")
+			if n == 38 {
+				t.pen.fg = color
+			} else {
+				t.pen.bg = color
+			}
+			i += length - 1
+		case n >= 30 && n <= 37 || n >= 90 && n <= 97:
+			t.pen.fg = args[i]
+		case n >= 40 && n <= 47 || n >= 100 && n <= 107:
+			t.pen.bg = args[i]
+		default:
+			t.unknown = append(t.unknown, "m"+args[i])
+		}
+	}
+}
+
+// print draws cluster at the cursor, wrapping at the right margin like
+// a terminal with autowrap on. Zero width clusters join the cell before.
+func (t *vterm) print(cluster string, width int) {
+	if width == 0 {
+		col := t.col - 1
+		for col > 0 && t.rows[t.row][col].wide {
+			col--
+		}
+		if col >= 0 {
+			t.rows[t.row][col].text += cluster
+		}
+		return
+	}
+	if t.col+width > t.width {
+		t.col = 0
+		t.lineFeed()
+	}
+	line := t.rows[t.row]
+	// Overwriting half of a wide character erases the rest of it
+	if line[t.col].wide {
+		for col := t.col - 1; col >= 0; col-- {
+			line[col] = vtCell{text: " ", pen: line[col].pen}
+			if !line[col].wide {
+				break
+			}
+		}
+	}
+	for col := t.col + width; col < t.width && line[col].wide; col++ {
+		line[col] = vtCell{}
+	}
+	line[t.col] = vtCell{text: cluster, pen: t.pen}
+	for i := 1; i < width; i++ {
+		line[t.col+i] = vtCell{pen: t.pen, wide: true}
+	}
+	// At the right margin the cursor waits there, and the next character
+	// wraps
+	t.col += width
+}
+
+func (t *vterm) lineFeed() {
+	if t.row < t.height-1 {
+		t.row++
+		return
+	}
+	t.rows = append(t.rows[1:], make([]vtCell, t.width))
+}
+
+func (t *vterm) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		t.eraseLine(0)
+		for row := t.row + 1; row < t.height; row++ {
+			t.rows[row] = make([]vtCell, t.width)
+		}
+	case 2, 3:
+		t.rows = t.blank()
+	default:
+		t.unknown = append(t.unknown, fmt.Sprintf("%dJ", mode))
+	}
+}
+
+func (t *vterm) eraseLine(mode int) {
+	switch mode {
+	case 0:
+		clear(t.rows[t.row][t.col:])
+	case 2:
+		clear(t.rows[t.row])
+	default:
+		t.unknown = append(t.unknown, fmt.Sprintf("%dK", mode))
+	}
+}
+
+// lines returns the text on screen, one string per row, with trailing
+// blanks and blank rows at the bottom removed
+func (t *vterm) lines() []string {
+	var lines []string
+	for _, row := range t.rows {
+		var b strings.Builder
+		for _, cell := range row {
+			switch {
+			case cell.wide:
+			case cell.text == "":
+				b.WriteByte(' ')
+			default:
+				b.WriteString(cell.text)
+			}
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// text returns the screen as lines joined by newlines
+func (t *vterm) text() string {
+	return strings.Join(t.lines(), "\n")
+}
+
+// cell returns the cell at row and col, counted from 0
+func (t *vterm) cell(row, col int) vtCell {
+	return t.rows[row][col]
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"ascii-art/asciiart"
+	"github.com/fatih/color"
+)
+
+const wizardSample = "Hi!"
+
+// runSetupWizard walks the user through picking a default style and
+// color scheme with previews, then writes the config file.
+func runSetupWizard(config *AppConfig) *UserConfig {
+	reader := bufio.NewReader(os.Stdin)
+	// Keep everything else already in the user's file, such as clocks and
+	// custom decorators
+	cfg := &UserConfig{}
+	if path, err := userConfigPath(); err == nil {
+		cfg.merge(path)
+	}
+
+	fmt.Println(color.CyanString("\nWelcome! Let's pick your defaults. Press Enter to accept the suggestion."))
+	if width, height, ok := terminalSize(); ok {
+		fmt.Printf("Terminal: %dx%d, %s", width, height, colorDepthName(detectColorDepth()))
+	} else {
+		fmt.Printf("Terminal: %s", colorDepthName(detectColorDepth()))
+	}
+	if unicodeSupported() {
+		fmt.Println(", Unicode")
+	} else {
+		fmt.Println(color.YellowString(", no UTF-8 locale (box borders may not display)"))
+	}
+
+	for i, category := range config.categories {
+		fmt.Printf("\n%d. %s - %s\n", i+1, color.BlueString(category.Name), color.YellowString(category.Description))
+	}
+	cfg.Category = promptNumber(reader, "Default category", 1, len(config.categories), 1)
+
+	category := config.categories[cfg.Category-1]
+	for i, style := range category.Styles {
+		fmt.Printf("\n%d. %s (%s)\n", i+1, color.HiWhiteString(style.Name), color.HiBlackString(style.Description))
+		fmt.Println(config.generateArt(wizardSample, style, nil))
+	}
+	cfg.Style = promptNumber(reader, "Default style", 1, len(category.Styles), 1)
+
+	fmt.Println()
+	for i := range config.colors {
+		scheme := &config.colors[i]
+		fmt.Printf("%d. %s\n", i+1, asciiart.ApplyColorScheme(scheme.Name+"\n"+strings.Repeat("█", 12)+"\n"+strings.Repeat("▓", 12), scheme))
+	}
+	colorDefault := 1
+	if detectColorDepth() == colorDepthNone {
+		colorDefault = 0
+	}
+	cfg.ColorScheme = promptNumber(reader, "Default color scheme (0 for none)", 0, len(config.colors), colorDefault)
+	cfg.NoColor = cfg.ColorScheme == 0
+
+	path, err := cfg.save()
+	if err != nil {
+		fmt.Println(color.RedString("Could not save config: %v", err))
+		return cfg
+	}
+	fmt.Println(color.GreenString("\nSaved your defaults to %s", path))
+	fmt.Println("Run 'ascii-art setup' to change them later.")
+	return cfg
+}
+
+// promptNumber asks for a number in [min, max], returning def on Enter
+// or when input ends.
+func promptNumber(reader *bufio.Reader, prompt string, min, max, def int) int {
+	for {
+		fmt.Printf("%s (%d-%d) [%d]: ", prompt, min, max, def)
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if err != nil {
+				fmt.Println()
+			}
+			return def
+		}
+		if n, convErr := strconv.Atoi(line); convErr == nil && n >= min && n <= max {
+			return n
+		}
+		fmt.Println(color.RedString("Invalid selection. Please try again."))
+		if err != nil {
+			return def
+		}
+	}
+}
+
+// runSetup re-runs the setup wizard
+func runSetup(config *AppConfig, args []string) {
+	runSetupWizard(config)
+}